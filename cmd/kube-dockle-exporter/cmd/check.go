@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"kube-dockle-exporter/pkg/report"
+	"kube-dockle-exporter/pkg/scan"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+func checkCmd() *cobra.Command {
+	checkArgs := scan.DefaultCheckArgs()
+
+	cmd := &cobra.Command{
+		Use:          "check [FILE...]",
+		Short:        "Scans images in manifests of files or stdin and prints the results",
+		SilenceUsage: true,
+		Run: func(cmd *cobra.Command, args []string) {
+			checkArgs.Files = args
+			err := scan.RunCheck(checkArgs)
+			if xerrors.Is(err, scan.ErrThresholdExceeded) {
+				log.Printf("Found findings at or above %s\n", checkArgs.FailOn)
+				os.Exit(int(checkArgs.ExitCode))
+			}
+			if err != nil {
+				log.Fatalf("Failed to run scan.RunCheck: %s\n", err.Error())
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&checkArgs.Config,
+		"config",
+		"",
+		checkArgs.Config,
+		"Path of YAML config of the server, whose ignore, exceptions, severities and registries are used",
+	)
+	cmd.PersistentFlags().Int64VarP(
+		&checkArgs.DockleConcurrency,
+		"dockle-concurrency",
+		"",
+		checkArgs.DockleConcurrency,
+		"Concurrency of dockle execution",
+	)
+	cmd.PersistentFlags().StringSliceVarP(
+		&checkArgs.IgnoreCodes,
+		"ignore-codes",
+		"",
+		checkArgs.IgnoreCodes,
+		"Glob patterns of codes of checks to ignore",
+	)
+	cmd.PersistentFlags().StringSliceVarP(
+		&checkArgs.IgnoreImages,
+		"ignore-images",
+		"",
+		checkArgs.IgnoreImages,
+		"Glob patterns of images to ignore",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&checkArgs.Format,
+		"format",
+		"f",
+		checkArgs.Format,
+		"Output format ("+strings.Join(report.Formats(), ", ")+")",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&checkArgs.Output,
+		"output",
+		"o",
+		checkArgs.Output,
+		"Path of output file (stdout if empty)",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&checkArgs.FailOn,
+		"fail-on",
+		"",
+		checkArgs.FailOn,
+		"Exit with --exit-code if findings at or above the level (FATAL, WARN, INFO or SKIP) are found",
+	)
+	cmd.PersistentFlags().Int64VarP(
+		&checkArgs.ExitCode,
+		"exit-code",
+		"",
+		checkArgs.ExitCode,
+		"Exit code when findings exceed --fail-on",
+	)
+
+	return cmd
+}
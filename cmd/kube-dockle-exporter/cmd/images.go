@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/scan"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+func imagesCmd() *cobra.Command {
+	imagesArgs := scan.DefaultImagesArgs()
+
+	cmd := &cobra.Command{
+		Use:          "images",
+		Short:        "Prints images in clusters which scans would find",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return fmt.Errorf("%q is an invalid argument", args[0])
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := scan.RunImages(imagesArgs)
+			if err != nil {
+				log.Fatalf("Failed to run scan.RunImages: %s\n", err.Error())
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&imagesArgs.Config,
+		"config",
+		"",
+		imagesArgs.Config,
+		"Path of YAML config of the server, whose ignore is used",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&imagesArgs.Kubeconfig,
+		"kubeconfig",
+		"",
+		imagesArgs.Kubeconfig,
+		"Path of kubeconfig (KUBECONFIG or in-cluster config if empty)",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&imagesArgs.Context,
+		"context",
+		"",
+		imagesArgs.Context,
+		"Context of kubeconfig (current context if empty)",
+	)
+	cmd.PersistentFlags().Float64VarP(
+		&imagesArgs.KubeAPIQPS,
+		"kube-api-qps",
+		"",
+		imagesArgs.KubeAPIQPS,
+		"Sustained QPS of requests to Kubernetes API",
+	)
+	cmd.PersistentFlags().Int64VarP(
+		&imagesArgs.KubeAPIBurst,
+		"kube-api-burst",
+		"",
+		imagesArgs.KubeAPIBurst,
+		"Burst of requests to Kubernetes API",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&imagesArgs.ClusterName,
+		"cluster-name",
+		"",
+		imagesArgs.ClusterName,
+		"Name of the cluster of the context in workloads",
+	)
+	cmd.PersistentFlags().StringSliceVarP(
+		&imagesArgs.ClusterContexts,
+		"cluster-contexts",
+		"",
+		imagesArgs.ClusterContexts,
+		"Contexts of kubeconfig of other clusters to discover",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&imagesArgs.ClusterSecretNamespace,
+		"cluster-secret-namespace",
+		"",
+		imagesArgs.ClusterSecretNamespace,
+		"Namespace of Secrets of kubeconfig of other clusters to discover",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&imagesArgs.ClusterSecretSelector,
+		"cluster-secret-selector",
+		"",
+		imagesArgs.ClusterSecretSelector,
+		"Label selector of Secrets of kubeconfig of other clusters to discover (disabled if empty)",
+	)
+	cmd.PersistentFlags().StringSliceVarP(
+		&imagesArgs.IgnoreImages,
+		"ignore-images",
+		"",
+		imagesArgs.IgnoreImages,
+		"Glob patterns of images to ignore",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&imagesArgs.Format,
+		"format",
+		"f",
+		imagesArgs.Format,
+		"Output format (table, json)",
+	)
+
+	return cmd
+}
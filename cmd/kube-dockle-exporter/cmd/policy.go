@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"kube-dockle-exporter/pkg/scan"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+func policyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "policy",
+		Short:        "Works with Rego policies evaluated over scan results",
+		SilenceUsage: true,
+	}
+
+	cmd.AddCommand(policyTestCmd())
+
+	return cmd
+}
+
+func policyTestCmd() *cobra.Command {
+	policyTestArgs := scan.DefaultPolicyTestArgs()
+
+	cmd := &cobra.Command{
+		Use:          "test PATH...",
+		Short:        "Runs unit tests of Rego policies",
+		SilenceUsage: true,
+		Args:         cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			policyTestArgs.Paths = args
+			err := scan.RunPolicyTest(policyTestArgs)
+			if xerrors.Is(err, scan.ErrPolicyTestFailed) {
+				os.Exit(1)
+			}
+			if err != nil {
+				log.Fatalf("Failed to run scan.RunPolicyTest: %s\n", err.Error())
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&policyTestArgs.Run,
+		"run",
+		"r",
+		policyTestArgs.Run,
+		"Regular expression of names of tests to run",
+	)
+	cmd.PersistentFlags().BoolVarP(
+		&policyTestArgs.Verbose,
+		"verbose",
+		"v",
+		policyTestArgs.Verbose,
+		"Print results of all tests",
+	)
+
+	return cmd
+}
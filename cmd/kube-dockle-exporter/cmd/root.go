@@ -11,6 +11,11 @@ func GetRootCmd(args []string) *cobra.Command {
 
 	rootCmd.SetArgs(args)
 	rootCmd.AddCommand(serverCmd())
+	rootCmd.AddCommand(scanCmd())
+	rootCmd.AddCommand(checkCmd())
+	rootCmd.AddCommand(imagesCmd())
+	rootCmd.AddCommand(validateConfigCmd())
+	rootCmd.AddCommand(policyCmd())
 
 	return rootCmd
 }
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/report"
+	"kube-dockle-exporter/pkg/scan"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+)
+
+func scanCmd() *cobra.Command {
+	scanArgs := scan.DefaultArgs()
+
+	cmd := &cobra.Command{
+		Use:          "scan",
+		Short:        "Scans images in the cluster once and prints the results",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return fmt.Errorf("%q is an invalid argument", args[0])
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := scan.Run(scanArgs)
+			if xerrors.Is(err, scan.ErrThresholdExceeded) {
+				log.Printf("Found findings at or above %s\n", scanArgs.FailOn)
+				os.Exit(int(scanArgs.ExitCode))
+			}
+			if err != nil {
+				log.Fatalf("Failed to run scan.Run: %s\n", err.Error())
+			}
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(
+		&scanArgs.Config,
+		"config",
+		"",
+		scanArgs.Config,
+		"Path of YAML config of the server, whose ignore, exceptions, severities and registries are used",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&scanArgs.Kubeconfig,
+		"kubeconfig",
+		"",
+		scanArgs.Kubeconfig,
+		"Path of kubeconfig (KUBECONFIG or in-cluster config if empty)",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&scanArgs.Context,
+		"context",
+		"",
+		scanArgs.Context,
+		"Context of kubeconfig (current context if empty)",
+	)
+	cmd.PersistentFlags().Float64VarP(
+		&scanArgs.KubeAPIQPS,
+		"kube-api-qps",
+		"",
+		scanArgs.KubeAPIQPS,
+		"Sustained QPS of requests to Kubernetes API",
+	)
+	cmd.PersistentFlags().Int64VarP(
+		&scanArgs.KubeAPIBurst,
+		"kube-api-burst",
+		"",
+		scanArgs.KubeAPIBurst,
+		"Burst of requests to Kubernetes API",
+	)
+	cmd.PersistentFlags().Int64VarP(
+		&scanArgs.DockleConcurrency,
+		"dockle-concurrency",
+		"",
+		scanArgs.DockleConcurrency,
+		"Concurrency of dockle execution",
+	)
+	cmd.PersistentFlags().StringSliceVarP(
+		&scanArgs.IgnoreCodes,
+		"ignore-codes",
+		"",
+		scanArgs.IgnoreCodes,
+		"Glob patterns of codes of checks to ignore",
+	)
+	cmd.PersistentFlags().StringSliceVarP(
+		&scanArgs.IgnoreImages,
+		"ignore-images",
+		"",
+		scanArgs.IgnoreImages,
+		"Glob patterns of images to ignore",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&scanArgs.Format,
+		"format",
+		"f",
+		scanArgs.Format,
+		"Output format ("+strings.Join(report.Formats(), ", ")+")",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&scanArgs.Output,
+		"output",
+		"o",
+		scanArgs.Output,
+		"Path of output file (stdout if empty)",
+	)
+	cmd.PersistentFlags().StringVarP(
+		&scanArgs.FailOn,
+		"fail-on",
+		"",
+		scanArgs.FailOn,
+		"Exit with --exit-code if findings at or above the level (FATAL, WARN, INFO or SKIP) are found",
+	)
+	cmd.PersistentFlags().Int64VarP(
+		&scanArgs.ExitCode,
+		"exit-code",
+		"",
+		scanArgs.ExitCode,
+		"Exit code when findings exceed --fail-on",
+	)
+
+	return cmd
+}
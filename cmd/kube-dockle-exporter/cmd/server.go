@@ -6,6 +6,7 @@ import (
 	"log"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -23,6 +24,15 @@ func serverCmd() *cobra.Command {
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
+			if serverArgs.Config != "" {
+				config, err := server.LoadConfig(serverArgs.Config)
+				if err != nil {
+					log.Fatalf("Failed to load config: %s\n", err.Error())
+				}
+				if err := server.ApplyServerConfig(cmd.PersistentFlags(), config.Server); err != nil {
+					log.Fatalf("Failed to load config: %s\n", err.Error())
+				}
+			}
 			err := server.Run(serverArgs)
 			if err != nil {
 				log.Fatalf("Failed to run server.Run: %s\n", err.Error())
@@ -30,108 +40,441 @@ func serverCmd() *cobra.Command {
 		},
 	}
 
-	cmd.PersistentFlags().StringVarP(
+	serverFlags(cmd.PersistentFlags(), serverArgs)
+	if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+		log.Fatalf("Failed to execute command: %s\n", err)
+	}
+
+	return cmd
+}
+
+// serverFlags defines flags of the server on flags, which set serverArgs.
+func serverFlags(flags *pflag.FlagSet, serverArgs *server.Args) {
+	flags.StringVarP(
+		&serverArgs.Config,
+		"config",
+		"",
+		serverArgs.Config,
+		"Path of YAML config, whose server section sets flags which are not given",
+	)
+	flags.Int64VarP(
+		&serverArgs.ConfigReloadInterval,
+		"config-reload-interval",
+		"",
+		serverArgs.ConfigReloadInterval,
+		"Interval seconds to check changes of config",
+	)
+	flags.StringVarP(
+		&serverArgs.Kubeconfig,
+		"kubeconfig",
+		"",
+		serverArgs.Kubeconfig,
+		"Path of kubeconfig (KUBECONFIG or in-cluster config if empty)",
+	)
+	flags.StringVarP(
+		&serverArgs.Context,
+		"context",
+		"",
+		serverArgs.Context,
+		"Context of kubeconfig (current context if empty)",
+	)
+	flags.Float64VarP(
+		&serverArgs.KubeAPIQPS,
+		"kube-api-qps",
+		"",
+		serverArgs.KubeAPIQPS,
+		"Sustained QPS of requests to Kubernetes API",
+	)
+	flags.Int64VarP(
+		&serverArgs.KubeAPIBurst,
+		"kube-api-burst",
+		"",
+		serverArgs.KubeAPIBurst,
+		"Burst of requests to Kubernetes API",
+	)
+	flags.StringVarP(
+		&serverArgs.ClusterName,
+		"cluster-name",
+		"",
+		serverArgs.ClusterName,
+		"Name of the cluster of the exporter in the cluster label of metrics",
+	)
+	flags.StringSliceVarP(
+		&serverArgs.ClusterContexts,
+		"cluster-contexts",
+		"",
+		serverArgs.ClusterContexts,
+		"Contexts of kubeconfig of other clusters to scan",
+	)
+	flags.StringVarP(
+		&serverArgs.ClusterSecretNamespace,
+		"cluster-secret-namespace",
+		"",
+		serverArgs.ClusterSecretNamespace,
+		"Namespace of Secrets of kubeconfig of other clusters to scan",
+	)
+	flags.StringVarP(
+		&serverArgs.ClusterSecretSelector,
+		"cluster-secret-selector",
+		"",
+		serverArgs.ClusterSecretSelector,
+		"Label selector of Secrets of kubeconfig of other clusters to scan (disabled if empty)",
+	)
+	flags.StringVarP(
 		&serverArgs.APIAddress,
 		"api-address",
 		"",
 		serverArgs.APIAddress,
 		"Address to use API",
 	)
-	cmd.PersistentFlags().Int64VarP(
+	flags.Int64VarP(
 		&serverArgs.APIMaxConnections,
 		"api-max-connections",
 		"",
 		serverArgs.APIMaxConnections,
 		"Max connections of API",
 	)
-	cmd.PersistentFlags().StringVarP(
+	flags.StringVarP(
 		&serverArgs.MonitorAddress,
 		"monitor-address",
 		"",
 		serverArgs.MonitorAddress,
 		"Address to use self-monitoring information",
 	)
-	cmd.PersistentFlags().Int64VarP(
+	flags.Int64VarP(
 		&serverArgs.MonitorMaxConnections,
 		"monitor-max-connections",
 		"",
 		serverArgs.MonitorMaxConnections,
 		"Max connections of self-monitoring information",
 	)
-	cmd.PersistentFlags().StringVarP(
+	flags.StringVarP(
 		&serverArgs.MonitoringJaegerEndpoint,
 		"monitoring-jaeger-endpoint",
 		"",
 		serverArgs.MonitoringJaegerEndpoint,
 		"Address to use for distributed tracing",
 	)
-	cmd.PersistentFlags().BoolVarP(
+	flags.BoolVarP(
 		&serverArgs.EnableProfiling,
 		"enable-profiling",
 		"",
 		serverArgs.EnableProfiling,
 		"Enable profiling",
 	)
-	cmd.PersistentFlags().BoolVarP(
+	flags.BoolVarP(
 		&serverArgs.EnableTracing,
 		"enable-tracing",
 		"",
 		serverArgs.EnableTracing,
 		"Enable distributed tracing",
 	)
-	cmd.PersistentFlags().Float64VarP(
+	flags.Float64VarP(
 		&serverArgs.TracingSampleRate,
 		"tracing-sample-rate",
 		"",
 		serverArgs.TracingSampleRate,
 		"Tracing sample rate",
 	)
-	cmd.PersistentFlags().BoolVarP(
+	flags.BoolVarP(
 		&serverArgs.KeepAlived,
 		"enable-keep-alived",
 		"",
 		serverArgs.KeepAlived,
 		"Enable HTTP KeepAlive",
 	)
-	cmd.PersistentFlags().BoolVarP(
+	flags.BoolVarP(
 		&serverArgs.ReUsePort,
 		"enable-reuseport",
 		"",
 		serverArgs.ReUsePort,
 		"Enable SO_REUSEPORT",
 	)
-	cmd.PersistentFlags().Int64VarP(
+	flags.Int64VarP(
 		&serverArgs.TCPKeepAliveInterval,
 		"tcp-keep-alive-interval",
 		"",
 		serverArgs.TCPKeepAliveInterval,
 		"Interval of TCP KeepAlive",
 	)
-	cmd.PersistentFlags().Int64VarP(
+	flags.Int64VarP(
 		&serverArgs.DockleConcurrency,
 		"dockle-concurrency",
 		"",
 		serverArgs.DockleConcurrency,
 		"Concurrency of dockle execution",
 	)
-	cmd.PersistentFlags().Int64VarP(
+	flags.StringSliceVarP(
+		&serverArgs.IgnoreCodes,
+		"ignore-codes",
+		"",
+		serverArgs.IgnoreCodes,
+		"Glob patterns of codes of checks to ignore",
+	)
+	flags.StringSliceVarP(
+		&serverArgs.IgnoreImages,
+		"ignore-images",
+		"",
+		serverArgs.IgnoreImages,
+		"Glob patterns of images to ignore",
+	)
+	flags.StringSliceVarP(
+		&serverArgs.PolicyPaths,
+		"policy-paths",
+		"",
+		serverArgs.PolicyPaths,
+		"Paths of Rego files or directories of policies evaluated after each scan",
+	)
+	flags.StringSliceVarP(
+		&serverArgs.DisableCorrelationRules,
+		"disable-correlation-rules",
+		"",
+		serverArgs.DisableCorrelationRules,
+		"Names of rules not to correlate findings with specs of workloads (non-root, liveness-probe or no-privilege-escalation)",
+	)
+	flags.StringSliceVarP(
+		&serverArgs.NamespaceOwnerLabels,
+		"namespace-owner-labels",
+		"",
+		serverArgs.NamespaceOwnerLabels,
+		"Keys of labels of namespaces which tell owners of workloads in them, e.g. team; namespaces are listed again on each scan",
+	)
+	flags.StringSliceVarP(
+		&serverArgs.NamespaceOwnerAnnotations,
+		"namespace-owner-annotations",
+		"",
+		serverArgs.NamespaceOwnerAnnotations,
+		"Keys of annotations of namespaces which tell owners of workloads in them; namespaces are listed again on each scan",
+	)
+	flags.Int64VarP(
 		&serverArgs.CollectorLoopInterval,
 		"collector-loop-interval",
 		"",
 		serverArgs.CollectorLoopInterval,
 		"Interval to execute collect result from dockle",
 	)
-	cmd.PersistentFlags().BoolVarP(
+	flags.BoolVarP(
+		&serverArgs.LeaderElect,
+		"leader-elect",
+		"",
+		serverArgs.LeaderElect,
+		"Enable leader election so that only the leader of replicas scans images",
+	)
+	flags.StringVarP(
+		&serverArgs.LeaderElectionNamespace,
+		"leader-election-namespace",
+		"",
+		serverArgs.LeaderElectionNamespace,
+		"Namespace of the Lease for leader election",
+	)
+	flags.StringVarP(
+		&serverArgs.LeaderElectionName,
+		"leader-election-name",
+		"",
+		serverArgs.LeaderElectionName,
+		"Name of the Lease for leader election",
+	)
+	flags.Int64VarP(
+		&serverArgs.LeaderElectionLeaseDuration,
+		"leader-election-lease-duration",
+		"",
+		serverArgs.LeaderElectionLeaseDuration,
+		"Duration in seconds that standby replicas wait before taking over the lease",
+	)
+	flags.Int64VarP(
+		&serverArgs.LeaderElectionRenewDeadline,
+		"leader-election-renew-deadline",
+		"",
+		serverArgs.LeaderElectionRenewDeadline,
+		"Duration in seconds that the leader retries renewing the lease before giving up",
+	)
+	flags.Int64VarP(
+		&serverArgs.LeaderElectionRetryPeriod,
+		"leader-election-retry-period",
+		"",
+		serverArgs.LeaderElectionRetryPeriod,
+		"Interval in seconds between attempts to acquire or renew the lease",
+	)
+	flags.BoolVarP(
+		&serverArgs.Shard,
+		"shard",
+		"",
+		serverArgs.Shard,
+		"Enable sharding so that each replica of the StatefulSet scans its own share of images",
+	)
+	flags.StringVarP(
+		&serverArgs.ShardNamespace,
+		"shard-namespace",
+		"",
+		serverArgs.ShardNamespace,
+		"Namespace of the StatefulSet for sharding",
+	)
+	flags.StringVarP(
+		&serverArgs.ShardStatefulSetName,
+		"shard-statefulset-name",
+		"",
+		serverArgs.ShardStatefulSetName,
+		"Name of the StatefulSet for sharding",
+	)
+	flags.BoolVarP(
+		&serverArgs.EnablePolicyReport,
+		"enable-policy-report",
+		"",
+		serverArgs.EnablePolicyReport,
+		"Enable publishing results as wgpolicyk8s.io PolicyReport",
+	)
+	flags.StringVarP(
+		&serverArgs.PolicyReportScope,
+		"policy-report-scope",
+		"",
+		serverArgs.PolicyReportScope,
+		"Scope of PolicyReport (namespace or cluster)",
+	)
+	flags.BoolVarP(
+		&serverArgs.EnableEvents,
+		"enable-events",
+		"",
+		serverArgs.EnableEvents,
+		"Enable recording Kubernetes Events on workloads when new findings appear",
+	)
+	flags.StringVarP(
+		&serverArgs.EventLevel,
+		"event-level",
+		"",
+		serverArgs.EventLevel,
+		"Minimum level of findings to record as Kubernetes Events",
+	)
+	flags.Float64VarP(
+		&serverArgs.EventQPS,
+		"event-qps",
+		"",
+		serverArgs.EventQPS,
+		"Sustained rate of Kubernetes Events per workload",
+	)
+	flags.Int64VarP(
+		&serverArgs.EventBurst,
+		"event-burst",
+		"",
+		serverArgs.EventBurst,
+		"Burst of Kubernetes Events per workload",
+	)
+	flags.StringVarP(
+		&serverArgs.HistoryPath,
+		"history-path",
+		"",
+		serverArgs.HistoryPath,
+		"Path of the on-disk store of scan history (disabled if empty)",
+	)
+	flags.Int64VarP(
+		&serverArgs.HistoryRetention,
+		"history-retention",
+		"",
+		serverArgs.HistoryRetention,
+		"Retention in seconds of scan history",
+	)
+	flags.StringVarP(
+		&serverArgs.NotificationLevel,
+		"notification-level",
+		"",
+		serverArgs.NotificationLevel,
+		"Minimum level of new findings to notify",
+	)
+	flags.StringVarP(
+		&serverArgs.NotificationConfig,
+		"notification-config",
+		"",
+		serverArgs.NotificationConfig,
+		"Path of the YAML file of sinks and routes of notifications",
+	)
+	flags.StringVarP(
+		&serverArgs.SlackWebhookURL,
+		"slack-webhook-url",
+		"",
+		serverArgs.SlackWebhookURL,
+		"Slack incoming webhook URL to notify new findings (disabled if empty)",
+	)
+	flags.Float64VarP(
+		&serverArgs.SlackRateLimit,
+		"slack-rate-limit",
+		"",
+		serverArgs.SlackRateLimit,
+		"Maximum number of Slack messages per second",
+	)
+	flags.Int64VarP(
+		&serverArgs.SlackRetries,
+		"slack-retries",
+		"",
+		serverArgs.SlackRetries,
+		"Number of retries of failed Slack messages",
+	)
+	flags.StringVarP(
+		&serverArgs.ExternalURL,
+		"external-url",
+		"",
+		serverArgs.ExternalURL,
+		"URL under which the API is externally reachable, used for links in notifications",
+	)
+	flags.StringVarP(
+		&serverArgs.WebhookURL,
+		"webhook-url",
+		"",
+		serverArgs.WebhookURL,
+		"URL of the webhook to send CloudEvents of scans and findings (disabled if empty)",
+	)
+	flags.StringVarP(
+		&serverArgs.WebhookMode,
+		"webhook-mode",
+		"",
+		serverArgs.WebhookMode,
+		"Content mode of CloudEvents (structured or binary)",
+	)
+	flags.StringVarP(
+		&serverArgs.WebhookSecretFile,
+		"webhook-secret-file",
+		"",
+		serverArgs.WebhookSecretFile,
+		"Path of the file containing the secret to sign webhook requests with HMAC-SHA256",
+	)
+	flags.StringVarP(
+		&serverArgs.WebhookSource,
+		"webhook-source",
+		"",
+		serverArgs.WebhookSource,
+		"Source attribute of CloudEvents",
+	)
+	flags.Int64VarP(
+		&serverArgs.WebhookRetries,
+		"webhook-retries",
+		"",
+		serverArgs.WebhookRetries,
+		"Number of retries of failed webhook requests",
+	)
+	flags.StringVarP(
+		&serverArgs.WebhookOutboxPath,
+		"webhook-outbox-path",
+		"",
+		serverArgs.WebhookOutboxPath,
+		"Path of the on-disk outbox which keeps undelivered webhook events across restarts (dropped if empty)",
+	)
+	flags.StringVarP(
+		&serverArgs.AlertmanagerURL,
+		"alertmanager-url",
+		"",
+		serverArgs.AlertmanagerURL,
+		"URL of Alertmanager to push findings as alerts (disabled if empty)",
+	)
+	flags.StringVarP(
+		&serverArgs.AlertmanagerLevel,
+		"alertmanager-level",
+		"",
+		serverArgs.AlertmanagerLevel,
+		"Minimum level of findings to push to Alertmanager",
+	)
+	flags.BoolVarP(
 		&serverArgs.Verbose,
 		"verbose",
 		"",
 		serverArgs.Verbose,
 		"Verbose logging",
 	)
-
-	if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
-		log.Fatalf("Failed to execute command: %s\n", err)
-	}
-
-	return cmd
 }
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/server"
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func validateConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "validate-config FILE",
+		Short:        "Validates config of the server",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			config, err := server.LoadConfig(args[0])
+			if err != nil {
+				log.Fatalf("Failed to validate config: %s\n", err.Error())
+			}
+			// Flags of a fresh server are neither bound to viper nor shared, so that validation does not affect this process.
+			serverArgs := server.DefaultArgs()
+			flags := pflag.NewFlagSet("server", pflag.ContinueOnError)
+			serverFlags(flags, serverArgs)
+			if err := server.ApplyServerConfig(flags, config.Server); err != nil {
+				log.Fatalf("Failed to validate config: %s\n", err.Error())
+			}
+			if err := serverArgs.Validate(); err != nil {
+				log.Fatalf("Failed to validate config: %s\n", err.Error())
+			}
+			if _, err := config.ResolveRegistries(); err != nil {
+				log.Fatalf("Failed to validate config: %s\n", err.Error())
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", args[0])
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	alertmanagerAlertsPath = "/api/v2/alerts"
+)
+
+type Alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+	StartsAt     time.Time         `json:"startsAt,omitempty"`
+	EndsAt       time.Time         `json:"endsAt,omitempty"`
+	GeneratorURL string            `json:"generatorURL,omitempty"`
+}
+
+type AlertmanagerClient struct {
+	url        string
+	httpClient *http.Client
+}
+
+func NewAlertmanagerClient(url string) *AlertmanagerClient {
+	return &AlertmanagerClient{
+		url: strings.TrimSuffix(url, "/") + alertmanagerAlertsPath,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (c *AlertmanagerClient) PostAlerts(ctx context.Context, alerts []Alert) error {
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal alerts: %w", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return xerrors.Errorf("failed to create request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return xerrors.Errorf("failed to send request: %w", err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode/100 != 2 {
+		return xerrors.Errorf("unexpected status code: %d", response.StatusCode)
+	}
+	return nil
+}
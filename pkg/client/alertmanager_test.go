@@ -0,0 +1,44 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"kube-dockle-exporter/pkg/client"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAlertmanagerClientPostAlerts(t *testing.T) {
+	alerts := []client.Alert{
+		{
+			Labels: map[string]string{
+				"alertname": "DockleFinding",
+			},
+			StartsAt: time.Unix(1, 0).UTC(),
+			EndsAt:   time.Unix(2, 0).UTC(),
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if diff := cmp.Diff("/api/v2/alerts", r.URL.Path); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+		var got []client.Alert
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode alerts: %s", err)
+		}
+		if diff := cmp.Diff(alerts, got); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	if err := client.NewAlertmanagerClient(server.URL+"/").PostAlerts(context.Background(), alerts); err != nil {
+		t.Fatal(err)
+	}
+}
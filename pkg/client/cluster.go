@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	ClusterSecretKey      = "kubeconfig"
+	ClusterNameAnnotation = "kube-dockle-exporter.kaidotdev.github.io/cluster-name"
+)
+
+type Cluster struct {
+	Name   string
+	Remote bool
+	Client *KubernetesClient
+}
+
+// ClusterErrors holds errors of clusters whose workloads could not be discovered, keyed by their names.
+type ClusterErrors map[string]error
+
+func (e ClusterErrors) Error() string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	messages := make([]string, 0, len(names))
+	for _, name := range names {
+		messages = append(messages, fmt.Sprintf("cluster %q: %s", name, e[name].Error()))
+	}
+	return strings.Join(messages, ", ")
+}
+
+// MultiClusterClient discovers workloads of clusters and marks them with clusters they belong to.
+// When a cluster fails, workloads last discovered in it stand in, so that one unreachable cluster fails neither scans
+// nor makes its findings look resolved.
+type MultiClusterClient struct {
+	Clusters       []Cluster
+	last           map[string][]Workload
+	lastNamespaces map[string][]Namespace
+	mutex          sync.Mutex
+}
+
+// Workloads returns ClusterErrors with workloads of the other clusters if only some of clusters failed.
+func (c *MultiClusterClient) Workloads() ([]Workload, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.last == nil {
+		c.last = make(map[string][]Workload)
+	}
+
+	var workloads []Workload
+	errs := make(ClusterErrors)
+	discovered := 0
+	for _, cluster := range c.Clusters {
+		clusterWorkloads, err := cluster.Client.Workloads()
+		if err != nil {
+			errs[cluster.Name] = err
+			workloads = append(workloads, c.last[cluster.Name]...)
+			if _, ok := c.last[cluster.Name]; ok {
+				discovered++
+			}
+			continue
+		}
+		for i := range clusterWorkloads {
+			clusterWorkloads[i].Cluster = cluster.Name
+			clusterWorkloads[i].Remote = cluster.Remote
+		}
+		c.last[cluster.Name] = clusterWorkloads
+		workloads = append(workloads, clusterWorkloads...)
+		discovered++
+	}
+	if len(errs) == 0 {
+		return workloads, nil
+	}
+	// Partial failures are distinguished by ClusterErrors, so that the error of all is not one of them.
+	if discovered == 0 {
+		return nil, xerrors.Errorf("could not discover any cluster: %s", errs.Error())
+	}
+	return workloads, errs
+}
+
+// Namespaces returns ClusterErrors with namespaces of the other clusters if only some of clusters failed, as Workloads
+// does.
+func (c *MultiClusterClient) Namespaces() ([]Namespace, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.lastNamespaces == nil {
+		c.lastNamespaces = make(map[string][]Namespace)
+	}
+
+	var namespaces []Namespace
+	errs := make(ClusterErrors)
+	discovered := 0
+	for _, cluster := range c.Clusters {
+		clusterNamespaces, err := cluster.Client.Namespaces()
+		if err != nil {
+			errs[cluster.Name] = err
+			namespaces = append(namespaces, c.lastNamespaces[cluster.Name]...)
+			if _, ok := c.lastNamespaces[cluster.Name]; ok {
+				discovered++
+			}
+			continue
+		}
+		for i := range clusterNamespaces {
+			clusterNamespaces[i].Cluster = cluster.Name
+		}
+		c.lastNamespaces[cluster.Name] = clusterNamespaces
+		namespaces = append(namespaces, clusterNamespaces...)
+		discovered++
+	}
+	if len(errs) == 0 {
+		return namespaces, nil
+	}
+	if discovered == 0 {
+		return nil, xerrors.Errorf("could not discover any cluster: %s", errs.Error())
+	}
+	return namespaces, errs
+}
+
+// NewClusterFromContext creates a remote cluster of the context of kubeconfig given by path or KUBECONFIG.
+func NewClusterFromContext(path string, context string, qps float32, burst int) (*Cluster, error) {
+	config, err := NewRESTConfig(path, context, qps, burst)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, xerrors.Errorf("could not create client of context %s: %w", context, err)
+	}
+	return &Cluster{
+		Name:   context,
+		Remote: true,
+		Client: &KubernetesClient{
+			Inner: clientset,
+		},
+	}, nil
+}
+
+// NewClustersFromSecrets creates remote clusters of kubeconfig in Secrets selected by the label selector.
+// Clusters are named by the annotation of Secrets, or by their names without it.
+func NewClustersFromSecrets(
+	inner kubernetes.Interface,
+	namespace string,
+	selector string,
+	qps float32,
+	burst int,
+) ([]Cluster, error) {
+	secrets, err := inner.CoreV1().Secrets(namespace).List(context.Background(), metaV1.ListOptions{
+		LabelSelector: selector,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("could not get secret: %w", err)
+	}
+	clusters := make([]Cluster, 0, len(secrets.Items))
+	for _, secret := range secrets.Items {
+		name := secret.Name
+		if annotation, ok := secret.Annotations[ClusterNameAnnotation]; ok {
+			name = annotation
+		}
+		kubeconfig, ok := secret.Data[ClusterSecretKey]
+		if !ok {
+			return nil, xerrors.Errorf("secret %s has no %s", secret.Name, ClusterSecretKey)
+		}
+		config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+		if err != nil {
+			return nil, xerrors.Errorf("could not load kubeconfig of secret %s: %w", secret.Name, err)
+		}
+		config.QPS = qps
+		config.Burst = burst
+		clientset, err := kubernetes.NewForConfig(config)
+		if err != nil {
+			return nil, xerrors.Errorf("could not create client of secret %s: %w", secret.Name, err)
+		}
+		clusters = append(clusters, Cluster{
+			Name:   name,
+			Remote: true,
+			Client: &KubernetesClient{
+				Inner: clientset,
+			},
+		})
+	}
+	return clusters, nil
+}
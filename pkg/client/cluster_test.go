@@ -0,0 +1,186 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	apiV1 "k8s.io/api/apps/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newClusterClientsetMock(deployments []string, failAfter int) *kubernetesClientsetMock {
+	calls := 0
+	return &kubernetesClientsetMock{
+		fakeDeploymentList: func(ctx context.Context, opts metaV1.ListOptions) (*apiV1.DeploymentList, error) {
+			calls++
+			if failAfter >= 0 && calls > failAfter {
+				return nil, errors.New("fake")
+			}
+			list := &apiV1.DeploymentList{}
+			for _, name := range deployments {
+				list.Items = append(list.Items, apiV1.Deployment{
+					ObjectMeta: metaV1.ObjectMeta{
+						Namespace: "fake",
+						Name:      name,
+					},
+				})
+			}
+			return list, nil
+		},
+		fakeStatefulSetList: func(ctx context.Context, opts metaV1.ListOptions) (*apiV1.StatefulSetList, error) {
+			return &apiV1.StatefulSetList{}, nil
+		},
+		fakeDaemonSetList: func(ctx context.Context, opts metaV1.ListOptions) (*apiV1.DaemonSetList, error) {
+			return &apiV1.DaemonSetList{}, nil
+		},
+	}
+}
+
+func TestMultiClusterClientWorkloads(t *testing.T) {
+	type want struct {
+		workloads   []string
+		errorString string
+	}
+
+	tests := []struct {
+		name     string
+		receiver func() *client.MultiClusterClient
+		in       int
+		want     want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func() *client.MultiClusterClient {
+				return &client.MultiClusterClient{
+					Clusters: []client.Cluster{
+						{
+							Name:   "home",
+							Client: &client.KubernetesClient{Inner: newClusterClientsetMock([]string{"a"}, -1)},
+						},
+						{
+							Name:   "remote",
+							Remote: true,
+							Client: &client.KubernetesClient{Inner: newClusterClientsetMock([]string{"b"}, -1)},
+						},
+					},
+				}
+			},
+			1,
+			want{
+				[]string{"home/fake/a/false", "remote/fake/b/true"},
+				"",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func() *client.MultiClusterClient {
+				return &client.MultiClusterClient{
+					Clusters: []client.Cluster{
+						{
+							Name:   "home",
+							Client: &client.KubernetesClient{Inner: newClusterClientsetMock([]string{"a"}, -1)},
+						},
+						{
+							Name:   "remote",
+							Remote: true,
+							Client: &client.KubernetesClient{Inner: newClusterClientsetMock([]string{"b"}, 1)},
+						},
+					},
+				}
+			},
+			2,
+			want{
+				[]string{"home/fake/a/false", "remote/fake/b/true"},
+				`cluster "remote": could not get deployment: fake`,
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func() *client.MultiClusterClient {
+				return &client.MultiClusterClient{
+					Clusters: []client.Cluster{
+						{
+							Name:   "home",
+							Client: &client.KubernetesClient{Inner: newClusterClientsetMock([]string{"a"}, -1)},
+						},
+						{
+							Name:   "remote",
+							Remote: true,
+							Client: &client.KubernetesClient{Inner: newClusterClientsetMock([]string{"b"}, 0)},
+						},
+					},
+				}
+			},
+			1,
+			want{
+				[]string{"home/fake/a/false"},
+				`cluster "remote": could not get deployment: fake`,
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func() *client.MultiClusterClient {
+				return &client.MultiClusterClient{
+					Clusters: []client.Cluster{
+						{
+							Name:   "home",
+							Client: &client.KubernetesClient{Inner: newClusterClientsetMock([]string{"a"}, 0)},
+						},
+					},
+				}
+			},
+			1,
+			want{
+				nil,
+				`could not discover any cluster: cluster "home": could not get deployment: fake`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		receiver := tt.receiver()
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var workloads []client.Workload
+			var err error
+			for i := 0; i < in; i++ {
+				workloads, err = receiver.Workloads()
+			}
+
+			var got []string
+			for _, workload := range workloads {
+				got = append(got, fmt.Sprintf("%s/%s/%s/%t", workload.Cluster, workload.Namespace, workload.Name, workload.Remote))
+			}
+			if diff := cmp.Diff(want.workloads, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			gotErrorString := ""
+			if err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(want.errorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"os"
+
+	"golang.org/x/xerrors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// NewRESTConfig loads the context of kubeconfig given by path or KUBECONFIG, or uses the in-cluster config when neither is given.
+// Outside of clusters, it falls back to the default kubeconfig in the home directory.
+func NewRESTConfig(path string, context string, qps float32, burst int) (*rest.Config, error) {
+	var config *rest.Config
+	var err error
+	if path == "" && context == "" && os.Getenv(clientcmd.RecommendedConfigPathEnvVar) == "" {
+		config, err = rest.InClusterConfig()
+		if err != nil && !xerrors.Is(err, rest.ErrNotInCluster) {
+			return nil, xerrors.Errorf("could not load in-cluster config: %w", err)
+		}
+	}
+	if config == nil {
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		loadingRules.ExplicitPath = path
+		config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			loadingRules,
+			&clientcmd.ConfigOverrides{
+				CurrentContext: context,
+			},
+		).ClientConfig()
+		if err != nil {
+			return nil, xerrors.Errorf("could not load kubeconfig: %w", err)
+		}
+	}
+	config.QPS = qps
+	config.Burst = burst
+	return config, nil
+}
@@ -0,0 +1,109 @@
+package client_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"kube-dockle-exporter/pkg/client"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const fakeKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+  - name: first
+    cluster:
+      server: https://first.example.com
+  - name: second
+    cluster:
+      server: https://second.example.com
+users:
+  - name: fake
+    user:
+      token: fake
+contexts:
+  - name: first
+    context:
+      cluster: first
+      user: fake
+  - name: second
+    context:
+      cluster: second
+      user: fake
+current-context: first
+`
+
+func TestNewRESTConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config")
+	if err := ioutil.WriteFile(path, []byte(fakeKubeconfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		context string
+		want    string
+		wantErr bool
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			"",
+			"https://first.example.com",
+			false,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			"second",
+			"https://second.example.com",
+			false,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			"unknown",
+			"",
+			true,
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		context := tt.context
+		want := tt.want
+		wantErr := tt.wantErr
+		t.Run(name, func(t *testing.T) {
+			config, err := client.NewRESTConfig(path, context, 20, 30)
+			if diff := cmp.Diff(wantErr, err != nil); diff != "" {
+				t.Fatalf("(-want +got):\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(want, config.Host); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(float32(20), config.QPS); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(30, config.Burst); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -6,13 +6,76 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
 
 	"golang.org/x/xerrors"
 )
 
 // Hope to implement using github.com/goodwithtech/dockle/pkg
 
-type DockleClient struct{}
+const (
+	LevelFatal = "FATAL"
+	LevelWarn  = "WARN"
+	LevelInfo  = "INFO"
+	LevelSkip  = "SKIP"
+	LevelPass  = "PASS"
+)
+
+func LevelSeverity(level string) int {
+	switch level {
+	case LevelFatal:
+		return 4
+	case LevelWarn:
+		return 3
+	case LevelInfo:
+		return 2
+	case LevelSkip:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Registry holds how dockle accesses a registry given by its host.
+type Registry struct {
+	Host     string
+	Insecure bool
+	Username string
+	Password string
+}
+
+type DockleClient struct {
+	registries map[string]Registry
+	mutex      sync.RWMutex
+}
+
+// SetRegistries replaces registries, which executions after it use.
+func (c *DockleClient) SetRegistries(registries []Registry) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.registries = make(map[string]Registry, len(registries))
+	for _, registry := range registries {
+		c.registries[registry.Host] = registry
+	}
+}
+
+// Env returns environment variables of dockle for the registry of the image.
+func (c *DockleClient) Env(image string) []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	registry, ok := c.registries[ParseImageReference(image).Registry]
+	if !ok {
+		return nil
+	}
+	var env []string
+	if registry.Insecure {
+		env = append(env, "DOCKLE_INSECURE=true")
+	}
+	if registry.Username != "" {
+		env = append(env, "DOCKLE_USERNAME="+registry.Username, "DOCKLE_PASSWORD="+registry.Password)
+	}
+	return env
+}
 
 func (c *DockleClient) Do(ctx context.Context, image string) ([]byte, error) {
 	tmpfile, err := ioutil.TempFile("", "*.json")
@@ -24,7 +87,11 @@ func (c *DockleClient) Do(ctx context.Context, image string) ([]byte, error) {
 	defer tmpfile.Close()
 	defer os.Remove(filename)
 
-	if _, err := exec.CommandContext(ctx, "dockle", "-o", filename, "-f", "json", image).CombinedOutput(); err != nil {
+	cmd := exec.CommandContext(ctx, "dockle", "-o", filename, "-f", "json", image)
+	if env := c.Env(image); len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if _, err := cmd.CombinedOutput(); err != nil {
 		return nil, xerrors.Errorf("failed to execute dockle: %w", err)
 	}
 	body, err := ioutil.ReadFile(filename)
@@ -57,4 +124,14 @@ type DockleDetail struct {
 	Title  string   `json:"title"`
 	Level  string   `json:"level"`
 	Alerts []string `json:"alerts"`
+	// OriginalLevel is the level given by dockle if Level is remapped.
+	OriginalLevel string `json:"originalLevel,omitempty"`
+}
+
+// DockleLevel returns the level given by dockle regardless of remapping.
+func (dd *DockleDetail) DockleLevel() string {
+	if dd.OriginalLevel != "" {
+		return dd.OriginalLevel
+	}
+	return dd.Level
 }
@@ -51,3 +51,20 @@ func TestDockleResponseExtractImage(t *testing.T) {
 		})
 	}
 }
+
+func TestDockleClientEnv(t *testing.T) {
+	receiver := &client.DockleClient{}
+	receiver.SetRegistries([]client.Registry{
+		{Host: "registry.example.com", Insecure: true, Username: "fake", Password: "secret"},
+		{Host: "docker.io", Username: "fake", Password: "secret"},
+	})
+	for image, want := range map[string][]string{
+		"registry.example.com/fake:1": {"DOCKLE_INSECURE=true", "DOCKLE_USERNAME=fake", "DOCKLE_PASSWORD=secret"},
+		"alpine":                      {"DOCKLE_USERNAME=fake", "DOCKLE_PASSWORD=secret"},
+		"quay.io/fake":                nil,
+	} {
+		if diff := cmp.Diff(want, receiver.Env(image)); diff != "" {
+			t.Errorf("%s (-want +got):\n%s", image, diff)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedCoreV1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const eventComponent = "kube-dockle-exporter"
+
+type EventRecorder struct {
+	broadcaster record.EventBroadcaster
+	recorder    record.EventRecorder
+}
+
+// NewEventRecorder creates a recorder whose events are rate-limited per involved object by qps and burst.
+func NewEventRecorder(inner kubernetes.Interface, qps float32, burst int) *EventRecorder {
+	broadcaster := record.NewBroadcasterWithCorrelatorOptions(record.CorrelatorOptions{
+		QPS:       qps,
+		BurstSize: burst,
+	})
+	broadcaster.StartRecordingToSink(&typedCoreV1.EventSinkImpl{
+		Interface: inner.CoreV1().Events(""),
+	})
+	return &EventRecorder{
+		broadcaster: broadcaster,
+		recorder: broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{
+			Component: eventComponent,
+		}),
+	}
+}
+
+func (r *EventRecorder) Event(object runtime.Object, eventType string, reason string, message string) {
+	r.recorder.Event(object, eventType, reason, message)
+}
+
+func (r *EventRecorder) Shutdown() {
+	r.broadcaster.Shutdown()
+}
@@ -0,0 +1,266 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/xerrors"
+)
+
+const (
+	historyBucket      = "history"
+	findingBucket      = "findings"
+	notificationBucket = "notifications"
+	storeTimeout       = 10 * time.Second
+)
+
+type HistoryEntry struct {
+	ScannedAt time.Time        `json:"scannedAt"`
+	Responses []DockleResponse `json:"responses"`
+	// Clusters holds clusters running each image, which is empty in entries stored before clusters were recorded.
+	Clusters map[string][]string `json:"clusters,omitempty"`
+	// Failed holds images which failed to be scanned, whose findings are unknown rather than resolved.
+	Failed []string `json:"failed,omitempty"`
+}
+
+type FindingState struct {
+	Cluster   string    `json:"cluster,omitempty"`
+	Image     string    `json:"image"`
+	Code      string    `json:"code"`
+	Level     string    `json:"level"`
+	FirstSeen time.Time `json:"firstSeen"`
+}
+
+type HistoryStore struct {
+	db        *bolt.DB
+	retention time.Duration
+}
+
+func NewHistoryStore(path string, retention time.Duration) (*HistoryStore, error) {
+	db, err := bolt.Open(path, os.FileMode(0600), &bolt.Options{Timeout: storeTimeout})
+	if err != nil {
+		return nil, xerrors.Errorf("could not open %s: %w", path, err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range []string{historyBucket, findingBucket, notificationBucket} {
+			if _, err := tx.CreateBucketIfNotExists([]byte(bucket)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		_ = db.Close()
+		return nil, xerrors.Errorf("could not create bucket: %w", err)
+	}
+	return &HistoryStore{
+		db:        db,
+		retention: retention,
+	}, nil
+}
+
+// historyKey encodes t so that keys sort chronologically. Times before the Unix epoch, such as the zero time, are
+// clamped to it, since negative nanoseconds would wrap around to the largest keys.
+func historyKey(t time.Time) []byte {
+	nanos := t.UnixNano()
+	if nanos < 0 {
+		nanos = 0
+	}
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(nanos))
+	return key
+}
+
+func (s *HistoryStore) Put(entry *HistoryEntry) error {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal history entry: %w", err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(historyBucket)).Put(historyKey(entry.ScannedAt), value)
+	})
+}
+
+// Prune deletes entries which are older than the retention.
+func (s *HistoryStore) Prune(now time.Time) error {
+	if s.retention <= 0 {
+		return nil
+	}
+	limit := historyKey(now.Add(-s.retention))
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(historyBucket))
+		var keys [][]byte
+		c := bucket.Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k, limit) < 0; k, _ = c.Next() {
+			keys = append(keys, k)
+		}
+		for _, k := range keys {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Entries returns entries scanned within [from, to] in chronological order.
+func (s *HistoryStore) Entries(from time.Time, to time.Time) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	if err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(historyBucket)).Cursor()
+		limit := historyKey(to)
+		for k, v := c.Seek(historyKey(from)); k != nil && bytes.Compare(k, limit) <= 0; k, v = c.Next() {
+			var entry HistoryEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+		}
+		return nil
+	}); err != nil {
+		return nil, xerrors.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+// ImageEntries returns entries scanned within [from, to] in chronological order, holding only responses of the image.
+// Entries without the image are left out.
+func (s *HistoryStore) ImageEntries(image string, from time.Time, to time.Time) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	if err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(historyBucket)).Cursor()
+		limit := historyKey(to)
+		for k, v := c.Seek(historyKey(from)); k != nil && bytes.Compare(k, limit) <= 0; k, v = c.Next() {
+			var entry HistoryEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			var responses []DockleResponse
+			for i := range entry.Responses {
+				if entry.Responses[i].ExtractImage() == image {
+					responses = append(responses, entry.Responses[i])
+				}
+			}
+			if len(responses) == 0 {
+				continue
+			}
+			entries = append(entries, HistoryEntry{
+				ScannedAt: entry.ScannedAt,
+				Responses: responses,
+			})
+		}
+		return nil
+	}); err != nil {
+		return nil, xerrors.Errorf("failed to read history: %w", err)
+	}
+	return entries, nil
+}
+
+// At returns the latest entry scanned at or before t.
+func (s *HistoryStore) At(t time.Time) (*HistoryEntry, error) {
+	var entry *HistoryEntry
+	if err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket([]byte(historyBucket)).Cursor()
+		limit := historyKey(t)
+		k, v := c.Seek(limit)
+		if k == nil || bytes.Compare(k, limit) > 0 {
+			k, v = c.Prev()
+		}
+		if k == nil {
+			return nil
+		}
+		entry = &HistoryEntry{}
+		return json.Unmarshal(v, entry)
+	}); err != nil {
+		return nil, xerrors.Errorf("failed to read history: %w", err)
+	}
+	return entry, nil
+}
+
+func (s *HistoryStore) FindingStates() (map[string]FindingState, error) {
+	states := make(map[string]FindingState)
+	if err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(findingBucket)).ForEach(func(k, v []byte) error {
+			var state FindingState
+			if err := json.Unmarshal(v, &state); err != nil {
+				return err
+			}
+			states[string(k)] = state
+			return nil
+		})
+	}); err != nil {
+		return nil, xerrors.Errorf("failed to read finding states: %w", err)
+	}
+	return states, nil
+}
+
+// SetFindingStates replaces all stored finding states.
+func (s *HistoryStore) SetFindingStates(states map[string]FindingState) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket([]byte(findingBucket)); err != nil {
+			return err
+		}
+		bucket, err := tx.CreateBucket([]byte(findingBucket))
+		if err != nil {
+			return err
+		}
+		for key, state := range states {
+			value, err := json.Marshal(state)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(key), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// NotificationTimes returns when notifications were sent last keyed by their groups.
+func (s *HistoryStore) NotificationTimes() (map[string]time.Time, error) {
+	times := make(map[string]time.Time)
+	if err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(notificationBucket)).ForEach(func(k, v []byte) error {
+			var t time.Time
+			if err := t.UnmarshalBinary(v); err != nil {
+				return err
+			}
+			times[string(k)] = t
+			return nil
+		})
+	}); err != nil {
+		return nil, xerrors.Errorf("failed to read notification times: %w", err)
+	}
+	return times, nil
+}
+
+// SetNotificationTimes replaces all stored notification times.
+func (s *HistoryStore) SetNotificationTimes(times map[string]time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket([]byte(notificationBucket)); err != nil {
+			return err
+		}
+		bucket, err := tx.CreateBucket([]byte(notificationBucket))
+		if err != nil {
+			return err
+		}
+		for key, t := range times {
+			value, err := t.MarshalBinary()
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(key), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *HistoryStore) Close() error {
+	return s.db.Close()
+}
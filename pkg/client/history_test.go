@@ -0,0 +1,231 @@
+package client_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"kube-dockle-exporter/pkg/client"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newHistoryStore(t *testing.T, retention time.Duration) *client.HistoryStore {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.RemoveAll(dir)
+	})
+	store, err := client.NewHistoryStore(filepath.Join(dir, "history.db"), retention)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+	for _, second := range []int64{10, 20, 30} {
+		if err := store.Put(&client.HistoryEntry{
+			ScannedAt: time.Unix(second, 0).UTC(),
+			Responses: []client.DockleResponse{
+				{
+					Target: fmt.Sprintf("fake:%d", second),
+				},
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+func TestHistoryStoreEntries(t *testing.T) {
+	type in struct {
+		first  time.Time
+		second time.Time
+	}
+
+	tests := []struct {
+		name      string
+		retention time.Duration
+		prune     time.Time
+		in        in
+		want      []string
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			0,
+			time.Unix(100, 0),
+			in{
+				time.Unix(15, 0),
+				time.Unix(30, 0),
+			},
+			[]string{"fake:20", "fake:30"},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			15 * time.Second,
+			time.Unix(40, 0),
+			in{
+				time.Unix(0, 0),
+				time.Unix(100, 0),
+			},
+			[]string{"fake:30"},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			0,
+			time.Unix(100, 0),
+			in{
+				time.Time{},
+				time.Unix(20, 0),
+			},
+			[]string{"fake:10", "fake:20"},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		retention := tt.retention
+		prune := tt.prune
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store := newHistoryStore(t, retention)
+			if err := store.Prune(prune); err != nil {
+				t.Fatal(err)
+			}
+			entries, err := store.Entries(in.first, in.second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Responses[0].Target)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHistoryStoreImageEntries(t *testing.T) {
+	store := newHistoryStore(t, 0)
+	if err := store.Put(&client.HistoryEntry{
+		ScannedAt: time.Unix(40, 0).UTC(),
+		Responses: []client.DockleResponse{
+			{Target: "other"},
+			{Target: "fake:20 (linux)"},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.ImageEntries("fake:20", time.Time{}, time.Unix(100, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []client.HistoryEntry{
+		{ScannedAt: time.Unix(20, 0).UTC(), Responses: []client.DockleResponse{{Target: "fake:20"}}},
+		{ScannedAt: time.Unix(40, 0).UTC(), Responses: []client.DockleResponse{{Target: "fake:20 (linux)"}}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestHistoryStoreAt(t *testing.T) {
+	tests := []struct {
+		name string
+		in   time.Time
+		want string
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			time.Unix(25, 0),
+			"fake:20",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			time.Unix(20, 0),
+			"fake:20",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			time.Unix(100, 0),
+			"fake:30",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			time.Unix(5, 0),
+			"",
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			store := newHistoryStore(t, 0)
+			entry, err := store.At(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ""
+			if entry != nil {
+				got = entry.Responses[0].Target
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHistoryStoreNotificationTimes(t *testing.T) {
+	store := newHistoryStore(t, 0)
+	for _, times := range []map[string]time.Time{
+		{"first": time.Unix(1, 0).UTC(), "second": time.Unix(2, 0).UTC()},
+		{"second": time.Unix(3, 0).UTC()},
+	} {
+		if err := store.SetNotificationTimes(times); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := store.NotificationTimes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(map[string]time.Time{"second": time.Unix(3, 0).UTC()}, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"strings"
+)
+
+const (
+	DefaultRegistry = "docker.io"
+	defaultTag      = "latest"
+)
+
+type ImageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImageReference parses an image reference in the same way as docker, which fills the default registry and tag.
+func ParseImageReference(image string) ImageReference {
+	var reference ImageReference
+	remainder := image
+	if i := strings.Index(remainder, "@"); i >= 0 {
+		reference.Digest = remainder[i+1:]
+		remainder = remainder[:i]
+	}
+	if i := strings.LastIndex(remainder, ":"); i >= 0 && !strings.Contains(remainder[i+1:], "/") {
+		reference.Tag = remainder[i+1:]
+		remainder = remainder[:i]
+	}
+	if i := strings.Index(remainder, "/"); i >= 0 && (strings.ContainsAny(remainder[:i], ".:") || remainder[:i] == "localhost") {
+		reference.Registry = remainder[:i]
+		remainder = remainder[i+1:]
+	} else {
+		reference.Registry = DefaultRegistry
+		if !strings.Contains(remainder, "/") {
+			remainder = "library/" + remainder
+		}
+	}
+	reference.Repository = remainder
+	if reference.Tag == "" && reference.Digest == "" {
+		reference.Tag = defaultTag
+	}
+	return reference
+}
+
+func (r *ImageReference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
@@ -0,0 +1,64 @@
+package client_test
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			"nginx",
+			"docker.io/library/nginx:latest",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			"kaidotdev/kube-dockle-exporter:v0.1.0",
+			"docker.io/kaidotdev/kube-dockle-exporter:v0.1.0",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			"localhost:5000/fake@sha256:abc",
+			"localhost:5000/fake@sha256:abc",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			"gcr.io/distroless/static:nonroot@sha256:abc",
+			"gcr.io/distroless/static:nonroot@sha256:abc",
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			reference := client.ParseImageReference(in)
+			if diff := cmp.Diff(want, reference.String()); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
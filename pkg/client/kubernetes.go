@@ -6,6 +6,7 @@ import (
 	"golang.org/x/xerrors"
 	v1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -13,16 +14,70 @@ type KubernetesClient struct {
 	Inner kubernetes.Interface
 }
 
-func (c *KubernetesClient) Containers() ([]v1.Container, error) {
+type Workload struct {
+	Cluster    string
+	Remote     bool
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	UID        types.UID
+	PodSpec    v1.PodSpec
+	// Replicas is the desired number of pods, or 0 if unknown.
+	Replicas int32
+	// Owner holds allowed labels and annotations of the namespace, which tell the owner of the workload.
+	Owner map[string]string
+	// NamespaceLabels holds labels of the namespace, which severities and routes match.
+	NamespaceLabels map[string]string
+}
+
+type Namespace struct {
+	Cluster     string
+	Name        string
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+func (w *Workload) Images() []string {
+	keys := make(map[string]bool)
+	var images []string
+	for _, container := range w.PodSpec.Containers {
+		if _, value := keys[container.Image]; !value {
+			keys[container.Image] = true
+			images = append(images, container.Image)
+		}
+	}
+	return images
+}
+
+func (w *Workload) ObjectReference() v1.ObjectReference {
+	return v1.ObjectReference{
+		APIVersion: w.APIVersion,
+		Kind:       w.Kind,
+		Namespace:  w.Namespace,
+		Name:       w.Name,
+		UID:        w.UID,
+	}
+}
+
+func (c *KubernetesClient) Workloads() ([]Workload, error) {
 	// nolint:prealloc
-	var containers []v1.Container
+	var workloads []Workload
 
 	deployments, err := c.Inner.AppsV1().Deployments("").List(context.Background(), metaV1.ListOptions{})
 	if err != nil {
 		return nil, xerrors.Errorf("could not get deployment: %w", err)
 	}
 	for _, deployment := range deployments.Items {
-		containers = append(containers, deployment.Spec.Template.Spec.Containers...)
+		workloads = append(workloads, Workload{
+			APIVersion: "apps/v1",
+			Kind:       "Deployment",
+			Namespace:  deployment.Namespace,
+			Name:       deployment.Name,
+			UID:        deployment.UID,
+			PodSpec:    deployment.Spec.Template.Spec,
+			Replicas:   replicasOf(deployment.Spec.Replicas),
+		})
 	}
 
 	statefulSets, err := c.Inner.AppsV1().StatefulSets("").List(context.Background(), metaV1.ListOptions{})
@@ -30,7 +85,15 @@ func (c *KubernetesClient) Containers() ([]v1.Container, error) {
 		return nil, xerrors.Errorf("could not get stateful set: %w", err)
 	}
 	for _, statefulSet := range statefulSets.Items {
-		containers = append(containers, statefulSet.Spec.Template.Spec.Containers...)
+		workloads = append(workloads, Workload{
+			APIVersion: "apps/v1",
+			Kind:       "StatefulSet",
+			Namespace:  statefulSet.Namespace,
+			Name:       statefulSet.Name,
+			UID:        statefulSet.UID,
+			PodSpec:    statefulSet.Spec.Template.Spec,
+			Replicas:   replicasOf(statefulSet.Spec.Replicas),
+		})
 	}
 
 	daemonSets, err := c.Inner.AppsV1().DaemonSets("").List(context.Background(), metaV1.ListOptions{})
@@ -38,8 +101,65 @@ func (c *KubernetesClient) Containers() ([]v1.Container, error) {
 		return nil, xerrors.Errorf("could not get daemon set: %w", err)
 	}
 	for _, daemonSet := range daemonSets.Items {
-		containers = append(containers, daemonSet.Spec.Template.Spec.Containers...)
+		workloads = append(workloads, Workload{
+			APIVersion: "apps/v1",
+			Kind:       "DaemonSet",
+			Namespace:  daemonSet.Namespace,
+			Name:       daemonSet.Name,
+			UID:        daemonSet.UID,
+			PodSpec:    daemonSet.Spec.Template.Spec,
+			Replicas:   daemonSet.Status.DesiredNumberScheduled,
+		})
+	}
+
+	return workloads, nil
+}
+
+// replicasOf returns replicas of the spec, which defaults to 1.
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+func (c *KubernetesClient) Namespaces() ([]Namespace, error) {
+	namespaces, err := c.Inner.CoreV1().Namespaces().List(context.Background(), metaV1.ListOptions{})
+	if err != nil {
+		return nil, xerrors.Errorf("could not get namespace: %w", err)
 	}
+	result := make([]Namespace, 0, len(namespaces.Items))
+	for _, namespace := range namespaces.Items {
+		result = append(result, Namespace{
+			Name:        namespace.Name,
+			Labels:      namespace.Labels,
+			Annotations: namespace.Annotations,
+		})
+	}
+	return result, nil
+}
 
+func (c *KubernetesClient) StatefulSetReplicas(namespace string, name string) (int, error) {
+	statefulSet, err := c.Inner.AppsV1().StatefulSets(namespace).Get(context.Background(), name, metaV1.GetOptions{})
+	if err != nil {
+		return 0, xerrors.Errorf("could not get stateful set: %w", err)
+	}
+	if statefulSet.Spec.Replicas == nil {
+		return 1, nil
+	}
+	return int(*statefulSet.Spec.Replicas), nil
+}
+
+func (c *KubernetesClient) Containers() ([]v1.Container, error) {
+	workloads, err := c.Workloads()
+	if err != nil {
+		return nil, err
+	}
+
+	// nolint:prealloc
+	var containers []v1.Container
+	for _, workload := range workloads {
+		containers = append(containers, workload.PodSpec.Containers...)
+	}
 	return containers, nil
 }
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/xerrors"
+)
+
+const (
+	outboxBucket = "outbox"
+)
+
+type OutboxEntry struct {
+	Sequence uint64
+	Event    CloudEvent
+}
+
+// Outbox persists events until they are delivered, so that they survive restarts.
+type Outbox struct {
+	db *bolt.DB
+}
+
+func NewOutbox(path string) (*Outbox, error) {
+	db, err := bolt.Open(path, os.FileMode(0600), &bolt.Options{Timeout: storeTimeout})
+	if err != nil {
+		return nil, xerrors.Errorf("could not open %s: %w", path, err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(outboxBucket))
+		return err
+	}); err != nil {
+		_ = db.Close()
+		return nil, xerrors.Errorf("could not create bucket: %w", err)
+	}
+	return &Outbox{
+		db: db,
+	}, nil
+}
+
+func outboxKey(sequence uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, sequence)
+	return key
+}
+
+func (o *Outbox) Enqueue(events []CloudEvent) error {
+	return o.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(outboxBucket))
+		for _, event := range events {
+			value, err := json.Marshal(event)
+			if err != nil {
+				return xerrors.Errorf("failed to marshal event: %w", err)
+			}
+			sequence, err := bucket.NextSequence()
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(outboxKey(sequence), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Pending returns undelivered events in the order of enqueueing.
+func (o *Outbox) Pending() ([]OutboxEntry, error) {
+	var entries []OutboxEntry
+	if err := o.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(outboxBucket)).ForEach(func(k, v []byte) error {
+			entry := OutboxEntry{
+				Sequence: binary.BigEndian.Uint64(k),
+			}
+			if err := json.Unmarshal(v, &entry.Event); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+	}); err != nil {
+		return nil, xerrors.Errorf("failed to read outbox: %w", err)
+	}
+	return entries, nil
+}
+
+func (o *Outbox) Remove(sequence uint64) error {
+	return o.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket([]byte(outboxBucket)).Delete(outboxKey(sequence))
+	})
+}
+
+func (o *Outbox) Close() error {
+	return o.db.Close()
+}
@@ -0,0 +1,56 @@
+package client_test
+
+import (
+	"io/ioutil"
+	"kube-dockle-exporter/pkg/client"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOutbox(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outbox.db")
+
+	outbox, err := client.NewOutbox(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Enqueue([]client.CloudEvent{{ID: "first"}, {ID: "second"}, {ID: "third"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Remove(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Undelivered events survive reopening.
+	outbox, err = client.NewOutbox(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer outbox.Close()
+	if err := outbox.Enqueue([]client.CloudEvent{{ID: "fourth"}}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := outbox.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []client.OutboxEntry{
+		{Sequence: 2, Event: client.CloudEvent{ID: "second"}},
+		{Sequence: 3, Event: client.CloudEvent{ID: "third"}},
+		{Sequence: 4, Event: client.CloudEvent{ID: "fourth"}},
+	}
+	if diff := cmp.Diff(want, entries); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
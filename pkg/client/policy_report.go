@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	PolicyReportAPIVersion     = "wgpolicyk8s.io/v1alpha2"
+	PolicyReportKind           = "PolicyReport"
+	ClusterPolicyReportKind    = "ClusterPolicyReport"
+	PolicyReportManagedByLabel = "app.kubernetes.io/managed-by"
+	PolicyReportManagedBy      = "kube-dockle-exporter"
+)
+
+type PolicyReport struct {
+	metaV1.TypeMeta   `json:",inline"`
+	metaV1.ObjectMeta `json:"metadata,omitempty"`
+	Summary           PolicyReportSummary  `json:"summary"`
+	Results           []PolicyReportResult `json:"results,omitempty"`
+}
+
+type PolicyReportSummary struct {
+	Pass  int `json:"pass"`
+	Fail  int `json:"fail"`
+	Warn  int `json:"warn"`
+	Error int `json:"error"`
+	Skip  int `json:"skip"`
+}
+
+type PolicyReportResult struct {
+	Source     string               `json:"source"`
+	Policy     string               `json:"policy"`
+	Category   string               `json:"category,omitempty"`
+	Severity   string               `json:"severity,omitempty"`
+	Timestamp  metaV1.Timestamp     `json:"timestamp"`
+	Result     string               `json:"result"`
+	Scored     bool                 `json:"scored"`
+	Message    string               `json:"message,omitempty"`
+	Resources  []v1.ObjectReference `json:"resources,omitempty"`
+	Properties map[string]string    `json:"properties,omitempty"`
+}
+
+type PolicyReportClient struct {
+	Inner dynamic.Interface
+}
+
+func (c *PolicyReportClient) resource(kind string) dynamic.NamespaceableResourceInterface {
+	resource := "policyreports"
+	if kind == ClusterPolicyReportKind {
+		resource = "clusterpolicyreports"
+	}
+	return c.Inner.Resource(schema.GroupVersionResource{
+		Group:    "wgpolicyk8s.io",
+		Version:  "v1alpha2",
+		Resource: resource,
+	})
+}
+
+func (c *PolicyReportClient) namespaced(kind string, namespace string) dynamic.ResourceInterface {
+	if kind == ClusterPolicyReportKind {
+		return c.resource(kind)
+	}
+	return c.resource(kind).Namespace(namespace)
+}
+
+func (c *PolicyReportClient) Apply(ctx context.Context, report *PolicyReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal %s: %w", report.Kind, err)
+	}
+	object := &unstructured.Unstructured{}
+	if err := object.UnmarshalJSON(body); err != nil {
+		return xerrors.Errorf("failed to convert %s: %w", report.Kind, err)
+	}
+
+	resource := c.namespaced(report.Kind, report.Namespace)
+	current, err := resource.Get(ctx, report.Name, metaV1.GetOptions{})
+	if errors.IsNotFound(err) {
+		if _, err := resource.Create(ctx, object, metaV1.CreateOptions{}); err != nil {
+			return xerrors.Errorf("could not create %s %s/%s: %w", report.Kind, report.Namespace, report.Name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return xerrors.Errorf("could not get %s %s/%s: %w", report.Kind, report.Namespace, report.Name, err)
+	}
+	object.SetResourceVersion(current.GetResourceVersion())
+	if _, err := resource.Update(ctx, object, metaV1.UpdateOptions{}); err != nil {
+		return xerrors.Errorf("could not update %s %s/%s: %w", report.Kind, report.Namespace, report.Name, err)
+	}
+	return nil
+}
+
+func (c *PolicyReportClient) List(ctx context.Context) ([]PolicyReport, error) {
+	// nolint:prealloc
+	var reports []PolicyReport
+	for _, kind := range []string{PolicyReportKind, ClusterPolicyReportKind} {
+		list, err := c.resource(kind).List(ctx, metaV1.ListOptions{
+			LabelSelector: PolicyReportManagedByLabel + "=" + PolicyReportManagedBy,
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("could not list %s: %w", kind, err)
+		}
+		for _, item := range list.Items {
+			reports = append(reports, PolicyReport{
+				TypeMeta: metaV1.TypeMeta{
+					APIVersion: PolicyReportAPIVersion,
+					Kind:       kind,
+				},
+				ObjectMeta: metaV1.ObjectMeta{
+					Namespace: item.GetNamespace(),
+					Name:      item.GetName(),
+				},
+			})
+		}
+	}
+	return reports, nil
+}
+
+func (c *PolicyReportClient) Delete(ctx context.Context, report *PolicyReport) error {
+	err := c.namespaced(report.Kind, report.Namespace).Delete(ctx, report.Name, metaV1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return xerrors.Errorf("could not delete %s %s/%s: %w", report.Kind, report.Namespace, report.Name, err)
+	}
+	return nil
+}
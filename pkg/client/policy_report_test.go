@@ -0,0 +1,118 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func TestPolicyReportClient(t *testing.T) {
+	type want struct {
+		first []client.PolicyReport
+	}
+
+	tests := []struct {
+		name     string
+		receiver *client.PolicyReportClient
+		in       []*client.PolicyReport
+		want     want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&client.PolicyReportClient{
+				Inner: fake.NewSimpleDynamicClient(k8sRuntime.NewScheme()),
+			},
+			[]*client.PolicyReport{
+				{
+					TypeMeta: metaV1.TypeMeta{
+						APIVersion: client.PolicyReportAPIVersion,
+						Kind:       client.PolicyReportKind,
+					},
+					ObjectMeta: metaV1.ObjectMeta{
+						Namespace: "fake",
+						Name:      "fake",
+						Labels: map[string]string{
+							client.PolicyReportManagedByLabel: client.PolicyReportManagedBy,
+						},
+					},
+				},
+				{
+					TypeMeta: metaV1.TypeMeta{
+						APIVersion: client.PolicyReportAPIVersion,
+						Kind:       client.PolicyReportKind,
+					},
+					ObjectMeta: metaV1.ObjectMeta{
+						Namespace: "fake",
+						Name:      "fake",
+						Labels: map[string]string{
+							client.PolicyReportManagedByLabel: client.PolicyReportManagedBy,
+						},
+					},
+					Summary: client.PolicyReportSummary{
+						Fail: 1,
+					},
+				},
+			},
+			want{
+				[]client.PolicyReport{
+					{
+						TypeMeta: metaV1.TypeMeta{
+							APIVersion: client.PolicyReportAPIVersion,
+							Kind:       client.PolicyReportKind,
+						},
+						ObjectMeta: metaV1.ObjectMeta{
+							Namespace: "fake",
+							Name:      "fake",
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		receiver := tt.receiver
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			for _, report := range in {
+				if err := receiver.Apply(ctx, report); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := receiver.List(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want.first, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+
+			for i := range got {
+				if err := receiver.Delete(ctx, &got[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err = receiver.List(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(0, len(got)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
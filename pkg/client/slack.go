@@ -0,0 +1,111 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+	"golang.org/x/xerrors"
+)
+
+type SlackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []SlackBlock `json:"blocks,omitempty"`
+}
+
+type SlackBlock struct {
+	Type     string      `json:"type"`
+	Text     *SlackText  `json:"text,omitempty"`
+	Fields   []SlackText `json:"fields,omitempty"`
+	Elements []SlackText `json:"elements,omitempty"`
+}
+
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type SlackClient struct {
+	webhookURL string
+	httpClient *http.Client
+	limiter    *rate.Limiter
+	retries    int
+	backoff    time.Duration
+}
+
+// NewSlackClient creates a client of incoming webhook which sends at most perSecond messages per second.
+func NewSlackClient(webhookURL string, perSecond float64, retries int) *SlackClient {
+	return &SlackClient{
+		webhookURL: webhookURL,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		limiter: rate.NewLimiter(rate.Limit(perSecond), 1),
+		retries: retries,
+		backoff: time.Second,
+	}
+}
+
+func (c *SlackClient) SetBackoff(backoff time.Duration) {
+	c.backoff = backoff
+}
+
+func (c *SlackClient) Post(ctx context.Context, message *SlackMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return xerrors.Errorf("failed to marshal slack message: %w", err)
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return xerrors.Errorf("failed to wait rate limiter: %w", err)
+		}
+		wait, err := c.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if attempt >= c.retries {
+			return xerrors.Errorf("failed to post slack message after %d attempts: %w", attempt+1, err)
+		}
+		if wait < backoff {
+			wait = backoff
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// post returns the duration which the server requested to wait before retrying.
+func (c *SlackClient) post(ctx context.Context, body []byte) (time.Duration, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, xerrors.Errorf("failed to create request: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return 0, xerrors.Errorf("failed to send request: %w", err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode/100 == 2 {
+		return 0, nil
+	}
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	}
+	return wait, xerrors.Errorf("unexpected status code: %d", response.StatusCode)
+}
@@ -0,0 +1,93 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSlackClientPost(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		retries    int
+		wantCalled int32
+		wantErr    bool
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]int{http.StatusOK},
+			3,
+			1,
+			false,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]int{http.StatusInternalServerError, http.StatusTooManyRequests, http.StatusOK},
+			3,
+			3,
+			false,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK},
+			1,
+			2,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		statuses := tt.statuses
+		retries := tt.retries
+		wantCalled := tt.wantCalled
+		wantErr := tt.wantErr
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var called int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				index := atomic.AddInt32(&called, 1) - 1
+				var message client.SlackMessage
+				if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+					t.Errorf("failed to decode message: %s", err)
+				}
+				if diff := cmp.Diff("fake", message.Text); diff != "" {
+					t.Errorf("(-want +got):\n%s", diff)
+				}
+				w.WriteHeader(statuses[index])
+			}))
+			defer server.Close()
+
+			slackClient := client.NewSlackClient(server.URL, 1000, retries)
+			slackClient.SetBackoff(time.Millisecond)
+			err := slackClient.Post(context.Background(), &client.SlackMessage{
+				Text: "fake",
+			})
+			if diff := cmp.Diff(wantErr, err != nil); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(wantCalled, atomic.LoadInt32(&called)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
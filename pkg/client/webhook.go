@@ -0,0 +1,145 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	CloudEventsSpecVersion = "1.0"
+	WebhookModeStructured  = "structured"
+	WebhookModeBinary      = "binary"
+	WebhookSignatureHeader = "X-Dockle-Signature-256"
+)
+
+// ErrWebhookRejected is returned when the receiver rejects an event, so that sending it again is pointless.
+var ErrWebhookRejected = xerrors.New("webhook rejected event")
+
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data,omitempty"`
+}
+
+type WebhookClient struct {
+	url        string
+	secret     []byte
+	mode       string
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+}
+
+func NewWebhookClient(url string, secret string, mode string, retries int) (*WebhookClient, error) {
+	if mode != WebhookModeStructured && mode != WebhookModeBinary {
+		return nil, xerrors.Errorf("unknown webhook mode: %s", mode)
+	}
+	return &WebhookClient{
+		url:    url,
+		secret: []byte(secret),
+		mode:   mode,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		retries: retries,
+		backoff: time.Second,
+	}, nil
+}
+
+func (c *WebhookClient) SetBackoff(backoff time.Duration) {
+	c.backoff = backoff
+}
+
+// Sign returns the value of the signature header which is the hex-encoded HMAC-SHA256 of body.
+func (c *WebhookClient) Sign(body []byte) string {
+	mac := hmac.New(sha256.New, c.secret)
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (c *WebhookClient) Send(ctx context.Context, event *CloudEvent) error {
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		err := c.send(ctx, event)
+		if err == nil {
+			return nil
+		}
+		if xerrors.Is(err, ErrWebhookRejected) {
+			return err
+		}
+		if attempt >= c.retries {
+			return xerrors.Errorf("failed to send %s after %d attempts: %w", event.ID, attempt+1, err)
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+func (c *WebhookClient) send(ctx context.Context, event *CloudEvent) error {
+	var body []byte
+	var err error
+	if c.mode == WebhookModeStructured {
+		body, err = json.Marshal(event)
+		if err != nil {
+			return xerrors.Errorf("failed to marshal event: %w", err)
+		}
+	} else {
+		body = event.Data
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return xerrors.Errorf("failed to create request: %w", err)
+	}
+	if c.mode == WebhookModeStructured {
+		request.Header.Set("Content-Type", "application/cloudevents+json; charset=utf-8")
+	} else {
+		request.Header.Set("Content-Type", event.DataContentType)
+		request.Header.Set("ce-specversion", event.SpecVersion)
+		request.Header.Set("ce-id", event.ID)
+		request.Header.Set("ce-source", event.Source)
+		request.Header.Set("ce-type", event.Type)
+		request.Header.Set("ce-time", event.Time.UTC().Format(time.RFC3339Nano))
+		if event.Subject != "" {
+			request.Header.Set("ce-subject", event.Subject)
+		}
+	}
+	if len(c.secret) > 0 {
+		request.Header.Set(WebhookSignatureHeader, c.Sign(body))
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return xerrors.Errorf("failed to send request: %w", err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, response.Body)
+
+	switch {
+	case response.StatusCode/100 == 2:
+		return nil
+	case response.StatusCode/100 == 4 && response.StatusCode != http.StatusRequestTimeout && response.StatusCode != http.StatusTooManyRequests:
+		return xerrors.Errorf("unexpected status code %d: %w", response.StatusCode, ErrWebhookRejected)
+	default:
+		return xerrors.Errorf("unexpected status code: %d", response.StatusCode)
+	}
+}
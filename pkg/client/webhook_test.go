@@ -0,0 +1,156 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"kube-dockle-exporter/pkg/client"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/xerrors"
+)
+
+func TestWebhookClientSend(t *testing.T) {
+	event := &client.CloudEvent{
+		SpecVersion:     client.CloudEventsSpecVersion,
+		ID:              "fake",
+		Source:          "kube-dockle-exporter",
+		Type:            "scan.completed",
+		Time:            time.Unix(1, 0).UTC(),
+		DataContentType: "application/json",
+		Data:            json.RawMessage(`{"images":1}`),
+	}
+
+	type want struct {
+		contentType string
+		specVersion string
+		body        string
+		called      int32
+		rejected    bool
+	}
+
+	tests := []struct {
+		name     string
+		mode     string
+		statuses []int
+		want     want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			client.WebhookModeStructured,
+			[]int{http.StatusOK},
+			want{
+				"application/cloudevents+json; charset=utf-8",
+				"",
+				`{"specversion":"1.0","id":"fake","source":"kube-dockle-exporter","type":"scan.completed","time":"1970-01-01T00:00:01Z","datacontenttype":"application/json","data":{"images":1}}`,
+				1,
+				false,
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			client.WebhookModeBinary,
+			[]int{http.StatusServiceUnavailable, http.StatusAccepted},
+			want{
+				"application/json",
+				"1.0",
+				`{"images":1}`,
+				2,
+				false,
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			client.WebhookModeBinary,
+			[]int{http.StatusBadRequest, http.StatusOK},
+			want{
+				"application/json",
+				"1.0",
+				`{"images":1}`,
+				1,
+				true,
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		mode := tt.mode
+		statuses := tt.statuses
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			signer, err := client.NewWebhookClient("", "secret", mode, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var called int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				index := atomic.AddInt32(&called, 1) - 1
+				body, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("failed to read body: %s", err)
+				}
+				if diff := cmp.Diff(want.body, string(body)); diff != "" {
+					t.Errorf("(-want +got):\n%s", diff)
+				}
+				if diff := cmp.Diff(want.contentType, r.Header.Get("Content-Type")); diff != "" {
+					t.Errorf("(-want +got):\n%s", diff)
+				}
+				if diff := cmp.Diff(want.specVersion, r.Header.Get("ce-specversion")); diff != "" {
+					t.Errorf("(-want +got):\n%s", diff)
+				}
+				if diff := cmp.Diff(signer.Sign(body), r.Header.Get(client.WebhookSignatureHeader)); diff != "" {
+					t.Errorf("(-want +got):\n%s", diff)
+				}
+				w.WriteHeader(statuses[index])
+			}))
+			defer server.Close()
+
+			webhookClient, err := client.NewWebhookClient(server.URL, "secret", mode, 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			webhookClient.SetBackoff(time.Millisecond)
+			err = webhookClient.Send(context.Background(), event)
+			if diff := cmp.Diff(want.rejected, xerrors.Is(err, client.ErrWebhookRejected)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			if !want.rejected && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(want.called, atomic.LoadInt32(&called)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWebhookClientSign(t *testing.T) {
+	webhookClient, err := client.NewWebhookClient("", "It's a Secret to Everybody", client.WebhookModeStructured, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The example of the signature of GitHub webhooks.
+	want := "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+	if diff := cmp.Diff(want, webhookClient.Sign([]byte("Hello, World!"))); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
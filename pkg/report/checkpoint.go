@@ -0,0 +1,39 @@
+package report
+
+import "strings"
+
+const checkpointURL = "https://github.com/goodwithtech/dockle/blob/master/CHECKPOINT.md"
+
+type Checkpoint struct {
+	Title string
+	Help  string
+}
+
+// Checkpoints returns the help text of checks which dockle executes, since its JSON output only carries titles.
+func Checkpoints() map[string]Checkpoint {
+	return map[string]Checkpoint{
+		"CIS-DI-0001": {"Create a user for the container", "Run the container as a non-root user by adding USER to the Dockerfile."},
+		"CIS-DI-0002": {"Use trusted base images for containers", "Build images only from base images from trusted sources."},
+		"CIS-DI-0003": {"Do not install unnecessary packages in the container", "Keep the image minimal to reduce its attack surface."},
+		"CIS-DI-0004": {"Scan and rebuild the images to include security patches", "Rebuild images regularly to pick up security patches."},
+		"CIS-DI-0005": {"Enable Content trust for Docker", "Set DOCKER_CONTENT_TRUST=1 to pull and run only signed images."},
+		"CIS-DI-0006": {"Add HEALTHCHECK instruction to the container image", "Add HEALTHCHECK to the Dockerfile so that the container health can be checked."},
+		"CIS-DI-0007": {"Do not use update instructions alone in the Dockerfile", "Combine update instructions with install instructions in the same RUN."},
+		"CIS-DI-0008": {"Confirm safety of setuid/setgid files", "Remove setuid and setgid permissions from binaries which do not need them."},
+		"CIS-DI-0009": {"Use COPY instead of ADD in Dockerfile", "Use COPY unless the features of ADD such as remote URLs are required."},
+		"CIS-DI-0010": {"Do not store credential in environment variables/files", "Remove secrets from the image and inject them at runtime."},
+		"DKL-DI-0001": {"Avoid sudo command", "Do not install or use sudo in the image."},
+		"DKL-DI-0002": {"Avoid sensitive directory mounting", "Do not declare VOLUME on sensitive directories such as /proc or /dev."},
+		"DKL-DI-0003": {"Avoid apt-get dist-upgrade", "Do not run apt-get dist-upgrade inside the image."},
+		"DKL-DI-0004": {"Use apk add with --no-cache", "Use apk add --no-cache to keep the package index out of the image."},
+		"DKL-DI-0005": {"Clear apt-get caches", "Run rm -rf /var/lib/apt/lists after apt-get install."},
+		"DKL-DI-0006": {"Avoid latest tag", "Pin images to a specific tag or digest instead of latest."},
+		"DKL-LI-0001": {"Avoid empty password", "Set passwords for all users in /etc/shadow or lock them."},
+		"DKL-LI-0002": {"Be unique UID/GROUPs", "Do not reuse the same UID or GID for different users or groups."},
+		"DKL-LI-0003": {"Only put necessary files", "Remove files such as .git or .DS_Store which are not needed at runtime."},
+	}
+}
+
+func CheckpointURL(code string) string {
+	return checkpointURL + "#" + strings.ToLower(code)
+}
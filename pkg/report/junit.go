@@ -0,0 +1,101 @@
+package report
+
+import (
+	"encoding/xml"
+	"io"
+	"kube-dockle-exporter/pkg/client"
+	"strings"
+)
+
+type JUnitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	TestSuites []JUnitTestSuite `xml:"testsuite"`
+}
+
+type JUnitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	TestCases []JUnitTestCase `xml:"testcase"`
+}
+
+type JUnitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+	Skipped   *JUnitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type JUnitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// NewJUnit makes a test suite per image and a test case per check, in which FATAL and WARN fail and SKIP is skipped.
+func NewJUnit(responses []client.DockleResponse) *JUnitTestSuites {
+	suites := &JUnitTestSuites{
+		Name:       "dockle",
+		TestSuites: make([]JUnitTestSuite, 0, len(responses)),
+	}
+	for _, response := range responses {
+		image := response.ExtractImage()
+		suite := JUnitTestSuite{
+			Name:      image,
+			TestCases: make([]JUnitTestCase, 0, len(response.Details)),
+		}
+		for _, detail := range response.Details {
+			testCase := JUnitTestCase{
+				ClassName: image,
+				Name:      detail.Code + ": " + detail.Title,
+			}
+			text := strings.Join(alerts(detail), "\n")
+			switch detail.Level {
+			case client.LevelFatal, client.LevelWarn:
+				testCase.Failure = &JUnitFailure{
+					Message: detail.Title,
+					Type:    detail.Level,
+					Text:    text,
+				}
+				suite.Failures++
+			case client.LevelSkip:
+				testCase.Skipped = &JUnitSkipped{
+					Message: text,
+				}
+				suite.Skipped++
+			default:
+				testCase.SystemOut = text
+			}
+			suite.TestCases = append(suite.TestCases, testCase)
+			suite.Tests++
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.TestSuites = append(suites.TestSuites, suite)
+	}
+	return suites
+}
+
+func WriteJUnit(w io.Writer, responses []client.DockleResponse) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(NewJUnit(responses)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
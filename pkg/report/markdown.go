@@ -0,0 +1,56 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"kube-dockle-exporter/pkg/client"
+	"strings"
+)
+
+func WriteMarkdown(w io.Writer, responses []client.DockleResponse) error {
+	var builder strings.Builder
+	builder.WriteString("# Dockle results\n\n")
+
+	counts := make(map[string]int)
+	for _, response := range responses {
+		for _, detail := range response.Details {
+			counts[detail.Level]++
+		}
+	}
+	builder.WriteString("| Level | Findings |\n|-------|----------|\n")
+	for _, level := range []string{client.LevelFatal, client.LevelWarn, client.LevelInfo, client.LevelSkip} {
+		fmt.Fprintf(&builder, "| %s | %d |\n", level, counts[level])
+	}
+
+	for _, response := range responses {
+		fmt.Fprintf(&builder, "\n## `%s`\n\n", response.ExtractImage())
+		if len(response.Details) == 0 {
+			builder.WriteString("No findings.\n")
+			continue
+		}
+		builder.WriteString("| Code | Level | Title | Alerts |\n|------|-------|-------|--------|\n")
+		for _, detail := range response.Details {
+			escaped := make([]string, 0, len(detail.Alerts))
+			for _, alert := range detail.Alerts {
+				escaped = append(escaped, markdownEscape(strings.TrimSpace(alert)))
+			}
+			fmt.Fprintf(
+				&builder,
+				"| [%s](%s) | %s | %s | %s |\n",
+				detail.Code,
+				CheckpointURL(detail.Code),
+				detail.Level,
+				markdownEscape(detail.Title),
+				strings.Join(escaped, "<br>"),
+			)
+		}
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// markdownEscape keeps text in a cell of tables.
+func markdownEscape(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+}
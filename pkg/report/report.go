@@ -0,0 +1,56 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"kube-dockle-exporter/pkg/client"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	FormatTable    = "table"
+	FormatJSON     = "json"
+	FormatSARIF    = "sarif"
+	FormatJUnit    = "junit"
+	FormatMarkdown = "markdown"
+)
+
+func Formats() []string {
+	return []string{FormatTable, FormatJSON, FormatSARIF, FormatJUnit, FormatMarkdown}
+}
+
+// Write renders responses in the format.
+func Write(w io.Writer, format string, responses []client.DockleResponse) error {
+	switch format {
+	case FormatTable:
+		return WriteTable(w, responses)
+	case FormatJSON:
+		return WriteJSON(w, responses)
+	case FormatSARIF:
+		return WriteSARIF(w, responses)
+	case FormatJUnit:
+		return WriteJUnit(w, responses)
+	case FormatMarkdown:
+		return WriteMarkdown(w, responses)
+	default:
+		return xerrors.Errorf("unknown format: %s", format)
+	}
+}
+
+func WriteJSON(w io.Writer, responses []client.DockleResponse) error {
+	if responses == nil {
+		responses = []client.DockleResponse{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(responses)
+}
+
+// alerts returns alerts of the detail, or its title if it has none.
+func alerts(detail client.DockleDetail) []string {
+	if len(detail.Alerts) == 0 {
+		return []string{detail.Title}
+	}
+	return detail.Alerts
+}
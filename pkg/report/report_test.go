@@ -0,0 +1,160 @@
+package report_test
+
+import (
+	"bytes"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/report"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	type in struct {
+		first  string
+		second []client.DockleResponse
+	}
+
+	type want struct {
+		first       string
+		errorString string
+	}
+
+	responses := []client.DockleResponse{
+		{
+			Target: "fake:1 (alpine)",
+			Details: []client.DockleDetail{
+				{
+					Code:   "CIS-DI-0001",
+					Title:  "Create a user for the container",
+					Level:  "WARN",
+					Alerts: []string{"Last user should not be root"},
+				},
+				{
+					Code:   "DKL-LI-0003",
+					Title:  "Only put necessary files",
+					Level:  "SKIP",
+					Alerts: []string{"a|b"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		in   in
+		want want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				"table",
+				responses,
+			},
+			want{
+				"IMAGE   CODE         LEVEL  TITLE                            ALERTS\n" +
+					"fake:1  CIS-DI-0001  WARN   Create a user for the container  Last user should not be root\n" +
+					"fake:1  DKL-LI-0003  SKIP   Only put necessary files         a|b\n",
+				"",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				"json",
+				nil,
+			},
+			want{
+				"[]\n",
+				"",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				"junit",
+				responses,
+			},
+			want{
+				`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="dockle" tests="2" failures="1" skipped="1">
+  <testsuite name="fake:1" tests="2" failures="1" skipped="1">
+    <testcase classname="fake:1" name="CIS-DI-0001: Create a user for the container">
+      <failure message="Create a user for the container" type="WARN">Last user should not be root</failure>
+    </testcase>
+    <testcase classname="fake:1" name="DKL-LI-0003: Only put necessary files">
+      <skipped message="a|b"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+				"",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				"markdown",
+				responses,
+			},
+			want{
+				"# Dockle results\n\n" +
+					"| Level | Findings |\n|-------|----------|\n| FATAL | 0 |\n| WARN | 1 |\n| INFO | 0 |\n| SKIP | 1 |\n\n" +
+					"## `fake:1`\n\n" +
+					"| Code | Level | Title | Alerts |\n|------|-------|-------|--------|\n" +
+					"| [CIS-DI-0001](https://github.com/goodwithtech/dockle/blob/master/CHECKPOINT.md#cis-di-0001) | WARN | Create a user for the container | Last user should not be root |\n" +
+					"| [DKL-LI-0003](https://github.com/goodwithtech/dockle/blob/master/CHECKPOINT.md#dkl-li-0003) | SKIP | Only put necessary files | a\\|b |\n",
+				"",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				"fake",
+				responses,
+			},
+			want{
+				"",
+				"unknown format: fake",
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			buffer := &bytes.Buffer{}
+			err := report.Write(buffer, in.first, in.second)
+			if diff := cmp.Diff(want.first, buffer.String()); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			gotErrorString := ""
+			if err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(want.errorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
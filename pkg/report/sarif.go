@@ -0,0 +1,180 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"kube-dockle-exporter/pkg/client"
+	"sort"
+	"strings"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+)
+
+type SARIF struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+type SARIFRun struct {
+	Tool    SARIFTool     `json:"tool"`
+	Results []SARIFResult `json:"results"`
+}
+
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+type SARIFRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     SARIFMessage       `json:"shortDescription"`
+	FullDescription      SARIFMessage       `json:"fullDescription"`
+	Help                 SARIFMessage       `json:"help"`
+	HelpURI              string             `json:"helpUri"`
+	DefaultConfiguration SARIFConfiguration `json:"defaultConfiguration"`
+}
+
+type SARIFConfiguration struct {
+	Level string `json:"level"`
+}
+
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+type SARIFResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      string            `json:"level"`
+	Message    SARIFMessage      `json:"message"`
+	Locations  []SARIFLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+}
+
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFLevel maps dockle levels onto the levels of SARIF results.
+func SARIFLevel(level string) string {
+	switch level {
+	case client.LevelFatal:
+		return "error"
+	case client.LevelWarn:
+		return "warning"
+	case client.LevelInfo:
+		return "note"
+	default:
+		return "none"
+	}
+}
+
+func NewSARIF(responses []client.DockleResponse) *SARIF {
+	checkpoints := Checkpoints()
+	rules := make(map[string]SARIFRule)
+	results := make([]SARIFResult, 0)
+
+	for _, response := range responses {
+		image := response.ExtractImage()
+		for _, detail := range response.Details {
+			if _, ok := rules[detail.Code]; !ok {
+				checkpoint, ok := checkpoints[detail.Code]
+				if !ok {
+					checkpoint = Checkpoint{
+						Title: detail.Title,
+						Help:  detail.Title,
+					}
+				}
+				rules[detail.Code] = SARIFRule{
+					ID:               detail.Code,
+					Name:             detail.Code,
+					ShortDescription: SARIFMessage{Text: checkpoint.Title},
+					FullDescription:  SARIFMessage{Text: checkpoint.Help},
+					Help:             SARIFMessage{Text: checkpoint.Help + " See " + CheckpointURL(detail.Code)},
+					HelpURI:          CheckpointURL(detail.Code),
+					// Severities remap levels per image, so the rule defaults to the level given by dockle, and
+					// results carry the remapped ones.
+					DefaultConfiguration: SARIFConfiguration{
+						Level: SARIFLevel(detail.DockleLevel()),
+					},
+				}
+			}
+
+			messages := make([]string, 0, len(detail.Alerts))
+			for _, alert := range detail.Alerts {
+				messages = append(messages, detail.Title+": "+strings.TrimSpace(alert))
+			}
+			if len(messages) == 0 {
+				messages = append(messages, detail.Title)
+			}
+			for _, message := range messages {
+				results = append(results, SARIFResult{
+					RuleID:  detail.Code,
+					Level:   SARIFLevel(detail.Level),
+					Message: SARIFMessage{Text: message},
+					Locations: []SARIFLocation{
+						{
+							PhysicalLocation: SARIFPhysicalLocation{
+								ArtifactLocation: SARIFArtifactLocation{URI: image},
+							},
+						},
+					},
+					Properties: map[string]string{
+						"image": image,
+						"level": detail.Level,
+					},
+				})
+			}
+		}
+	}
+
+	codes := make([]string, 0, len(rules))
+	for code := range rules {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	sortedRules := make([]SARIFRule, 0, len(codes))
+	for _, code := range codes {
+		sortedRules = append(sortedRules, rules[code])
+	}
+
+	return &SARIF{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []SARIFRun{
+			{
+				Tool: SARIFTool{
+					Driver: SARIFDriver{
+						Name:           "dockle",
+						InformationURI: "https://github.com/goodwithtech/dockle",
+						Rules:          sortedRules,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+}
+
+func WriteSARIF(w io.Writer, responses []client.DockleResponse) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(NewSARIF(responses))
+}
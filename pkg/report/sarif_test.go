@@ -0,0 +1,208 @@
+package report_test
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/report"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewSARIF(t *testing.T) {
+	type in struct {
+		first []client.DockleResponse
+	}
+
+	tests := []struct {
+		name string
+		in   in
+		want *report.SARIF
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				[]client.DockleResponse{
+					{
+						Target: "fake",
+						Details: []client.DockleDetail{
+							{
+								Code:   "CIS-DI-0010",
+								Title:  "Do not store credential in environment variables/files",
+								Level:  "FATAL",
+								Alerts: []string{"Suspicious filename found : a", "Suspicious filename found : b"},
+							},
+						},
+					},
+				},
+			},
+			&report.SARIF{
+				Version: "2.1.0",
+				Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+				Runs: []report.SARIFRun{
+					{
+						Tool: report.SARIFTool{
+							Driver: report.SARIFDriver{
+								Name:           "dockle",
+								InformationURI: "https://github.com/goodwithtech/dockle",
+								Rules: []report.SARIFRule{
+									{
+										ID:               "CIS-DI-0010",
+										Name:             "CIS-DI-0010",
+										ShortDescription: report.SARIFMessage{Text: "Do not store credential in environment variables/files"},
+										FullDescription:  report.SARIFMessage{Text: "Remove secrets from the image and inject them at runtime."},
+										Help:             report.SARIFMessage{Text: "Remove secrets from the image and inject them at runtime. See https://github.com/goodwithtech/dockle/blob/master/CHECKPOINT.md#cis-di-0010"},
+										HelpURI:          "https://github.com/goodwithtech/dockle/blob/master/CHECKPOINT.md#cis-di-0010",
+										DefaultConfiguration: report.SARIFConfiguration{
+											Level: "error",
+										},
+									},
+								},
+							},
+						},
+						Results: []report.SARIFResult{
+							{
+								RuleID:  "CIS-DI-0010",
+								Level:   "error",
+								Message: report.SARIFMessage{Text: "Do not store credential in environment variables/files: Suspicious filename found : a"},
+								Locations: []report.SARIFLocation{
+									{PhysicalLocation: report.SARIFPhysicalLocation{ArtifactLocation: report.SARIFArtifactLocation{URI: "fake"}}},
+								},
+								Properties: map[string]string{"image": "fake", "level": "FATAL"},
+							},
+							{
+								RuleID:  "CIS-DI-0010",
+								Level:   "error",
+								Message: report.SARIFMessage{Text: "Do not store credential in environment variables/files: Suspicious filename found : b"},
+								Locations: []report.SARIFLocation{
+									{PhysicalLocation: report.SARIFPhysicalLocation{ArtifactLocation: report.SARIFArtifactLocation{URI: "fake"}}},
+								},
+								Properties: map[string]string{"image": "fake", "level": "FATAL"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				[]client.DockleResponse{
+					{
+						Target: "remapped",
+						Details: []client.DockleDetail{
+							{
+								Code:          "CIS-DI-0010",
+								Title:         "Do not store credential in environment variables/files",
+								Level:         "INFO",
+								OriginalLevel: "FATAL",
+							},
+						},
+					},
+					{
+						Target: "fake",
+						Details: []client.DockleDetail{
+							{
+								Code:  "CIS-DI-0010",
+								Title: "Do not store credential in environment variables/files",
+								Level: "FATAL",
+							},
+						},
+					},
+				},
+			},
+			&report.SARIF{
+				Version: "2.1.0",
+				Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+				Runs: []report.SARIFRun{
+					{
+						Tool: report.SARIFTool{
+							Driver: report.SARIFDriver{
+								Name:           "dockle",
+								InformationURI: "https://github.com/goodwithtech/dockle",
+								Rules: []report.SARIFRule{
+									{
+										ID:               "CIS-DI-0010",
+										Name:             "CIS-DI-0010",
+										ShortDescription: report.SARIFMessage{Text: "Do not store credential in environment variables/files"},
+										FullDescription:  report.SARIFMessage{Text: "Remove secrets from the image and inject them at runtime."},
+										Help:             report.SARIFMessage{Text: "Remove secrets from the image and inject them at runtime. See https://github.com/goodwithtech/dockle/blob/master/CHECKPOINT.md#cis-di-0010"},
+										HelpURI:          "https://github.com/goodwithtech/dockle/blob/master/CHECKPOINT.md#cis-di-0010",
+										DefaultConfiguration: report.SARIFConfiguration{
+											Level: "error",
+										},
+									},
+								},
+							},
+						},
+						Results: []report.SARIFResult{
+							{
+								RuleID:  "CIS-DI-0010",
+								Level:   "note",
+								Message: report.SARIFMessage{Text: "Do not store credential in environment variables/files"},
+								Locations: []report.SARIFLocation{
+									{PhysicalLocation: report.SARIFPhysicalLocation{ArtifactLocation: report.SARIFArtifactLocation{URI: "remapped"}}},
+								},
+								Properties: map[string]string{"image": "remapped", "level": "INFO"},
+							},
+							{
+								RuleID:  "CIS-DI-0010",
+								Level:   "error",
+								Message: report.SARIFMessage{Text: "Do not store credential in environment variables/files"},
+								Locations: []report.SARIFLocation{
+									{PhysicalLocation: report.SARIFPhysicalLocation{ArtifactLocation: report.SARIFArtifactLocation{URI: "fake"}}},
+								},
+								Properties: map[string]string{"image": "fake", "level": "FATAL"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				nil,
+			},
+			&report.SARIF{
+				Version: "2.1.0",
+				Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+				Runs: []report.SARIFRun{
+					{
+						Tool: report.SARIFTool{
+							Driver: report.SARIFDriver{
+								Name:           "dockle",
+								InformationURI: "https://github.com/goodwithtech/dockle",
+								Rules:          []report.SARIFRule{},
+							},
+						},
+						Results: []report.SARIFResult{},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := report.NewSARIF(in.first)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
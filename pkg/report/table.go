@@ -0,0 +1,33 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"kube-dockle-exporter/pkg/client"
+	"strings"
+	"text/tabwriter"
+)
+
+func WriteTable(w io.Writer, responses []client.DockleResponse) error {
+	writer := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	if _, err := fmt.Fprintln(writer, "IMAGE\tCODE\tLEVEL\tTITLE\tALERTS"); err != nil {
+		return err
+	}
+	for _, response := range responses {
+		image := response.ExtractImage()
+		for _, detail := range response.Details {
+			if _, err := fmt.Fprintf(
+				writer,
+				"%s\t%s\t%s\t%s\t%s\n",
+				image,
+				detail.Code,
+				detail.Level,
+				detail.Title,
+				strings.Join(detail.Alerts, ", "),
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,103 @@
+package scan
+
+type Args struct {
+	Config            string
+	Kubeconfig        string
+	Context           string
+	KubeAPIQPS        float64
+	KubeAPIBurst      int64
+	DockleConcurrency int64
+	IgnoreCodes       []string
+	IgnoreImages      []string
+	Format            string
+	Output            string
+	FailOn            string
+	ExitCode          int64
+}
+
+func DefaultArgs() *Args {
+	return &Args{
+		Config:            "",
+		Kubeconfig:        "",
+		Context:           "",
+		KubeAPIQPS:        5,
+		KubeAPIBurst:      10,
+		DockleConcurrency: 10,
+		IgnoreCodes:       []string{},
+		IgnoreImages:      []string{},
+		Format:            "table",
+		Output:            "",
+		FailOn:            "",
+		ExitCode:          1,
+	}
+}
+
+type CheckArgs struct {
+	Files             []string
+	Config            string
+	DockleConcurrency int64
+	IgnoreCodes       []string
+	IgnoreImages      []string
+	Format            string
+	Output            string
+	FailOn            string
+	ExitCode          int64
+}
+
+func DefaultCheckArgs() *CheckArgs {
+	return &CheckArgs{
+		Files:             []string{},
+		Config:            "",
+		DockleConcurrency: 10,
+		IgnoreCodes:       []string{},
+		IgnoreImages:      []string{},
+		Format:            "table",
+		Output:            "",
+		FailOn:            "",
+		ExitCode:          1,
+	}
+}
+
+type ImagesArgs struct {
+	Config                 string
+	Kubeconfig             string
+	Context                string
+	KubeAPIQPS             float64
+	KubeAPIBurst           int64
+	ClusterName            string
+	ClusterContexts        []string
+	ClusterSecretNamespace string
+	ClusterSecretSelector  string
+	IgnoreImages           []string
+	Format                 string
+}
+
+func DefaultImagesArgs() *ImagesArgs {
+	return &ImagesArgs{
+		Config:                 "",
+		Kubeconfig:             "",
+		Context:                "",
+		KubeAPIQPS:             5,
+		KubeAPIBurst:           10,
+		ClusterName:            "",
+		ClusterContexts:        []string{},
+		ClusterSecretNamespace: "default",
+		ClusterSecretSelector:  "",
+		IgnoreImages:           []string{},
+		Format:                 "table",
+	}
+}
+
+type PolicyTestArgs struct {
+	Paths   []string
+	Run     string
+	Verbose bool
+}
+
+func DefaultPolicyTestArgs() *PolicyTestArgs {
+	return &PolicyTestArgs{
+		Paths:   []string{},
+		Run:     "",
+		Verbose: false,
+	}
+}
@@ -0,0 +1,62 @@
+package scan
+
+import (
+	"io"
+	"kube-dockle-exporter/pkg/client"
+	"os"
+
+	"golang.org/x/xerrors"
+)
+
+// RunCheck scans images of manifests in files, or stdin if none or "-" is given, without clusters.
+func RunCheck(a *CheckArgs) error {
+	if err := validate(a.Format, a.FailOn); err != nil {
+		return err
+	}
+
+	files := a.Files
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	var workloads []client.Workload
+	for _, file := range files {
+		fileWorkloads, err := readManifests(file)
+		if err != nil {
+			return err
+		}
+		workloads = append(workloads, fileWorkloads...)
+	}
+
+	filters, err := loadFilters(a.Config, a.IgnoreCodes, a.IgnoreImages, nil)
+	if err != nil {
+		return err
+	}
+
+	return scanAndReport(
+		&manifestClient{
+			workloads: workloads,
+		},
+		a.DockleConcurrency,
+		filters,
+		a.Format,
+		a.Output,
+		a.FailOn,
+	)
+}
+
+func readManifests(file string) ([]client.Workload, error) {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to open %s: %w", file, err)
+		}
+		defer f.Close()
+		r = f
+	}
+	workloads, err := ParseManifests(r)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to parse manifests of %s: %w", file, err)
+	}
+	return workloads, nil
+}
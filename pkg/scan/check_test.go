@@ -0,0 +1,38 @@
+package scan_test
+
+import (
+	"io/ioutil"
+	"kube-dockle-exporter/pkg/scan"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunCheckNamespaceLabels(t *testing.T) {
+	dir, err := ioutil.TempDir("", "check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	manifest := filepath.Join(dir, "manifest.yaml")
+	if err := ioutil.WriteFile(manifest, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: fake\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(config, []byte("severities:\n  - code: CIS-DI-0001\n    namespaceLabels: {environment: production}\n    level: FATAL\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	args := scan.DefaultCheckArgs()
+	args.Files = []string{manifest}
+	args.Config = config
+	gotErrorString := ""
+	if err := scan.RunCheck(args); err != nil {
+		gotErrorString = err.Error()
+	}
+	if diff := cmp.Diff("severities[0]: namespaceLabels cannot be matched without clusters", gotErrorString); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,139 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/xerrors"
+	"k8s.io/client-go/kubernetes"
+)
+
+type InventoryImage struct {
+	Image     string   `json:"image"`
+	Reference string   `json:"reference"`
+	Registry  string   `json:"registry"`
+	Workloads []string `json:"workloads"`
+	Skipped   bool     `json:"skipped"`
+}
+
+// RunImages prints images which discovery of scans finds in clusters, without scanning them.
+func RunImages(a *ImagesArgs) error {
+	if a.Format != "table" && a.Format != "json" {
+		return xerrors.Errorf("unknown format: %s", a.Format)
+	}
+
+	kubeConfig, err := client.NewRESTConfig(a.Kubeconfig, a.Context, float32(a.KubeAPIQPS), int(a.KubeAPIBurst))
+	if err != nil {
+		return xerrors.Errorf("failed to create kubernetes config: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return xerrors.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	clusters := []client.Cluster{
+		{
+			Name:   a.ClusterName,
+			Client: &client.KubernetesClient{Inner: clientset},
+		},
+	}
+	for _, kubeContext := range a.ClusterContexts {
+		cluster, err := client.NewClusterFromContext(a.Kubeconfig, kubeContext, float32(a.KubeAPIQPS), int(a.KubeAPIBurst))
+		if err != nil {
+			return xerrors.Errorf("failed to create client of cluster: %w", err)
+		}
+		clusters = append(clusters, *cluster)
+	}
+	if a.ClusterSecretSelector != "" {
+		secretClusters, err := client.NewClustersFromSecrets(
+			clientset,
+			a.ClusterSecretNamespace,
+			a.ClusterSecretSelector,
+			float32(a.KubeAPIQPS),
+			int(a.KubeAPIBurst),
+		)
+		if err != nil {
+			return xerrors.Errorf("failed to create clients of clusters: %w", err)
+		}
+		clusters = append(clusters, secretClusters...)
+	}
+	multiClusterClient := &client.MultiClusterClient{
+		Clusters: clusters,
+	}
+
+	// Images are skipped by the same filters as scans, including ignore of the config.
+	filters, err := loadFilters(a.Config, nil, a.IgnoreImages, multiClusterClient)
+	if err != nil {
+		return err
+	}
+	// Unlike the server, the inventory is not complete without any of clusters.
+	workloads, err := multiClusterClient.Workloads()
+	if err != nil {
+		return xerrors.Errorf("failed to get workloads: %w", err)
+	}
+
+	return WriteInventory(os.Stdout, a.Format, Inventory(workloads, filters.ignore))
+}
+
+// Inventory returns unique images of workloads sorted by them.
+func Inventory(workloads []client.Workload, ignore *collector.Ignore) []InventoryImage {
+	snapshot := &collector.Snapshot{
+		Workloads: workloads,
+	}
+	images := snapshot.Images()
+	sort.Strings(images)
+	inventory := make([]InventoryImage, 0, len(images))
+	for _, image := range images {
+		reference := client.ParseImageReference(image)
+		imageWorkloads := snapshot.WorkloadsOf(image)
+		names := make([]string, 0, len(imageWorkloads))
+		for _, workload := range imageWorkloads {
+			name := fmt.Sprintf("%s/%s/%s", workload.Kind, workload.Namespace, workload.Name)
+			if workload.Cluster != "" {
+				name = workload.Cluster + ":" + name
+			}
+			names = append(names, name)
+		}
+		inventory = append(inventory, InventoryImage{
+			Image:     image,
+			Reference: reference.String(),
+			Registry:  reference.Registry,
+			Workloads: names,
+			Skipped:   ignore.IgnoresImage(image),
+		})
+	}
+	return inventory
+}
+
+func WriteInventory(w io.Writer, format string, inventory []InventoryImage) error {
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(inventory)
+	}
+	writer := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	if _, err := fmt.Fprintln(writer, "IMAGE\tREFERENCE\tREGISTRY\tSKIPPED\tWORKLOADS"); err != nil {
+		return err
+	}
+	for _, image := range inventory {
+		if _, err := fmt.Fprintf(
+			writer,
+			"%s\t%s\t%s\t%t\t%s\n",
+			image.Image,
+			image.Reference,
+			image.Registry,
+			image.Skipped,
+			strings.Join(image.Workloads, ", "),
+		); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,154 @@
+package scan_test
+
+import (
+	"bytes"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/scan"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestInventory(t *testing.T) {
+	type in struct {
+		first  []client.Workload
+		second *collector.Ignore
+	}
+
+	tests := []struct {
+		name string
+		in   in
+		want []scan.InventoryImage
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				[]client.Workload{
+					{
+						Kind:      "Deployment",
+						Namespace: "fake",
+						Name:      "a",
+						PodSpec: v1.PodSpec{
+							Containers: []v1.Container{{Image: "quay.io/fake/sidecar:1"}, {Image: "alpine"}},
+						},
+					},
+					{
+						Kind:      "DaemonSet",
+						Namespace: "fake",
+						Name:      "b",
+						PodSpec: v1.PodSpec{
+							Containers: []v1.Container{{Image: "alpine"}},
+						},
+					},
+				},
+				&collector.Ignore{
+					Images: []string{"quay.io/*/*"},
+				},
+			},
+			[]scan.InventoryImage{
+				{
+					Image:     "alpine",
+					Reference: "docker.io/library/alpine:latest",
+					Registry:  "docker.io",
+					Workloads: []string{"Deployment/fake/a", "DaemonSet/fake/b"},
+					Skipped:   false,
+				},
+				{
+					Image:     "quay.io/fake/sidecar:1",
+					Reference: "quay.io/fake/sidecar:1",
+					Registry:  "quay.io",
+					Workloads: []string{"Deployment/fake/a"},
+					Skipped:   true,
+				},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				nil,
+				&collector.Ignore{},
+			},
+			[]scan.InventoryImage{},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				[]client.Workload{
+					{
+						Kind:      "Deployment",
+						Namespace: "fake",
+						Name:      "a",
+						PodSpec: v1.PodSpec{
+							Containers: []v1.Container{{Image: "alpine"}},
+						},
+					},
+					{
+						Cluster:   "remote",
+						Kind:      "Deployment",
+						Namespace: "fake",
+						Name:      "a",
+						PodSpec: v1.PodSpec{
+							Containers: []v1.Container{{Image: "alpine"}},
+						},
+					},
+				},
+				&collector.Ignore{},
+			},
+			[]scan.InventoryImage{
+				{
+					Image:     "alpine",
+					Reference: "docker.io/library/alpine:latest",
+					Registry:  "docker.io",
+					Workloads: []string{"Deployment/fake/a", "remote:Deployment/fake/a"},
+					Skipped:   false,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := scan.Inventory(in.first, in.second)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteInventory(t *testing.T) {
+	inventory := []scan.InventoryImage{
+		{
+			Image:     "alpine",
+			Reference: "docker.io/library/alpine:latest",
+			Registry:  "docker.io",
+			Workloads: []string{"Deployment/fake/a", "DaemonSet/fake/b"},
+		},
+	}
+	buffer := &bytes.Buffer{}
+	if err := scan.WriteInventory(buffer, "table", inventory); err != nil {
+		t.Fatal(err)
+	}
+	want := "IMAGE   REFERENCE                        REGISTRY   SKIPPED  WORKLOADS\n" +
+		"alpine  docker.io/library/alpine:latest  docker.io  false    Deployment/fake/a, DaemonSet/fake/b\n"
+	if diff := cmp.Diff(want, buffer.String()); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,115 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"kube-dockle-exporter/pkg/client"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// podSpecPaths are paths of pod specs in kinds which run pods, regardless of their API groups and versions.
+var podSpecPaths = map[string][]string{ // nolint:gochecknoglobals
+	"Pod":                   {"spec"},
+	"PodTemplate":           {"template", "spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// manifestClient serves workloads of manifests in place of clusters.
+type manifestClient struct {
+	workloads []client.Workload
+}
+
+func (c *manifestClient) Workloads() ([]client.Workload, error) {
+	return c.workloads, nil
+}
+
+// ParseManifests returns workloads of pod-bearing kinds in multi-document YAML or JSON, including items of lists.
+// Init containers are taken as containers, so that their images are checked as well.
+// Documents of the other kinds are ignored.
+func ParseManifests(r io.Reader) ([]client.Workload, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	var workloads []client.Workload
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return workloads, nil
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("could not read document: %w", err)
+		}
+		if len(bytes.TrimSpace(document)) == 0 {
+			continue
+		}
+		var object map[string]interface{}
+		if err := yaml.Unmarshal(document, &object); err != nil {
+			return nil, xerrors.Errorf("could not parse document: %w", err)
+		}
+		if object == nil {
+			continue
+		}
+		documentWorkloads, err := objectWorkloads(&unstructured.Unstructured{Object: object})
+		if err != nil {
+			return nil, err
+		}
+		workloads = append(workloads, documentWorkloads...)
+	}
+}
+
+func objectWorkloads(object *unstructured.Unstructured) ([]client.Workload, error) {
+	if object.IsList() {
+		list, err := object.ToList()
+		if err != nil {
+			return nil, xerrors.Errorf("could not parse list: %w", err)
+		}
+		var workloads []client.Workload
+		for i := range list.Items {
+			itemWorkloads, err := objectWorkloads(&list.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			workloads = append(workloads, itemWorkloads...)
+		}
+		return workloads, nil
+	}
+
+	fields, ok := podSpecPaths[object.GetKind()]
+	if !ok {
+		return nil, nil
+	}
+	spec, found, err := unstructured.NestedMap(object.Object, fields...)
+	if err != nil {
+		return nil, xerrors.Errorf("could not get pod spec of %s %s: %w", object.GetKind(), object.GetName(), err)
+	}
+	if !found {
+		return nil, nil
+	}
+	var podSpec v1.PodSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(spec, &podSpec); err != nil {
+		return nil, xerrors.Errorf("could not convert pod spec of %s %s: %w", object.GetKind(), object.GetName(), err)
+	}
+	// Workloads of clusters scan images of containers only, while manifests are checked before init containers run.
+	podSpec.Containers = append(podSpec.InitContainers, podSpec.Containers...)
+	podSpec.InitContainers = nil
+	return []client.Workload{
+		{
+			APIVersion: object.GetAPIVersion(),
+			Kind:       object.GetKind(),
+			Namespace:  object.GetNamespace(),
+			Name:       object.GetName(),
+			PodSpec:    podSpec,
+		},
+	}, nil
+}
@@ -0,0 +1,154 @@
+package scan_test
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/scan"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseManifests(t *testing.T) {
+	type in struct {
+		first string
+	}
+
+	type want struct {
+		first       []string
+		errorString string
+	}
+
+	tests := []struct {
+		name string
+		in   in
+		want want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				`---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment
+  namespace: fake
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: init
+          image: busybox:1
+      containers:
+        - name: app
+          image: alpine:3
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: batch/v1beta1
+    kind: CronJob
+    metadata:
+      name: cron-job
+    spec:
+      jobTemplate:
+        spec:
+          template:
+            spec:
+              containers:
+                - name: job
+                  image: alpine:3
+  - apiVersion: v1
+    kind: Pod
+    metadata:
+      name: pod
+    spec:
+      containers:
+        - name: pod
+          image: nginx
+`,
+			},
+			want{
+				[]string{
+					"apps/v1 Deployment fake/deployment busybox:1,alpine:3",
+					"batch/v1beta1 CronJob /cron-job alpine:3",
+					"v1 Pod /pod nginx",
+				},
+				"",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				`{"apiVersion": "batch/v1", "kind": "Job", "metadata": {"name": "job"}, "spec": {"template": {"spec": {"containers": [{"name": "job", "image": "alpine:3"}]}}}}`,
+			},
+			want{
+				[]string{
+					"batch/v1 Job /job alpine:3",
+				},
+				"",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				`---
+apiVersion: v1
+kind: Pod
+spec:
+  containers: fake
+`,
+			},
+			want{
+				nil,
+				"could not convert pod spec of Pod : cannot restore slice from string",
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			workloads, err := scan.ParseManifests(strings.NewReader(in.first))
+			var got []string
+			for _, workload := range workloads {
+				got = append(got, fmt.Sprintf(
+					"%s %s %s/%s %s",
+					workload.APIVersion,
+					workload.Kind,
+					workload.Namespace,
+					workload.Name,
+					strings.Join(workload.Images(), ","),
+				))
+			}
+			if diff := cmp.Diff(want.first, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			gotErrorString := ""
+			if err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(want.errorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
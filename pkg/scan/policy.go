@@ -0,0 +1,69 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/open-policy-agent/opa/tester"
+	"golang.org/x/xerrors"
+)
+
+// ErrPolicyTestFailed is returned when some tests of policies fail.
+var ErrPolicyTestFailed = xerrors.New("policy tests failed")
+
+// RunPolicyTest runs test_ rules of Rego files, as `opa test` does.
+func RunPolicyTest(a *PolicyTestArgs) error {
+	return RunPolicyTests(context.Background(), os.Stdout, a.Paths, a.Run, a.Verbose)
+}
+
+// RunPolicyTests writes failed tests, or all tests if verbose, and a summary to w.
+func RunPolicyTests(ctx context.Context, w io.Writer, paths []string, run string, verbose bool) error {
+	modules, store, err := tester.Load(paths, func(abspath string, info os.FileInfo, depth int) bool {
+		return !info.IsDir() && !strings.HasSuffix(info.Name(), ".rego")
+	})
+	if err != nil {
+		return xerrors.Errorf("failed to load policies: %w", err)
+	}
+	results, err := tester.NewRunner().SetStore(store).Filter(run).Run(ctx, modules)
+	if err != nil {
+		return xerrors.Errorf("failed to run tests: %w", err)
+	}
+
+	total := 0
+	passed := 0
+	for result := range results {
+		total++
+		if result.Pass() {
+			passed++
+		}
+		if !verbose && result.Pass() {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", result.String()); err != nil {
+			return xerrors.Errorf("failed to write result: %w", err)
+		}
+		if result.Error != nil {
+			if _, err := fmt.Fprintf(w, "  %s\n", result.Error.Error()); err != nil {
+				return xerrors.Errorf("failed to write result: %w", err)
+			}
+		}
+	}
+	if total == 0 {
+		return xerrors.New("no tests found")
+	}
+
+	outcome := "PASS"
+	if passed < total {
+		outcome = "FAIL"
+	}
+	if _, err := fmt.Fprintf(w, "%s: %d/%d\n", outcome, passed, total); err != nil {
+		return xerrors.Errorf("failed to write result: %w", err)
+	}
+	if passed < total {
+		return ErrPolicyTestFailed
+	}
+	return nil
+}
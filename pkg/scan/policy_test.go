@@ -0,0 +1,149 @@
+package scan_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"kube-dockle-exporter/pkg/scan"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRunPolicyTests(t *testing.T) {
+	type in struct {
+		files   map[string]string
+		run     string
+		verbose bool
+	}
+
+	type want struct {
+		output      string
+		errorString string
+	}
+
+	policy := `package dockle.latest
+
+deny[msg] {
+	endswith(input.image, ":latest")
+	msg := "latest tag is not allowed"
+}
+`
+
+	tests := []struct {
+		name string
+		in   in
+		want want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				map[string]string{
+					"latest.rego": policy,
+					"latest_test.rego": `package dockle.latest
+
+test_latest {
+	deny["latest tag is not allowed"] with input as {"image": "app:latest"}
+}
+
+test_pinned {
+	count(deny) == 0 with input as {"image": "app:1"}
+}
+`,
+				},
+				"",
+				true,
+			},
+			want{
+				"data.dockle.latest.test_latest: PASS\ndata.dockle.latest.test_pinned: PASS\nPASS: 2/2\n",
+				"",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				map[string]string{
+					"latest.rego": policy,
+					"latest_test.rego": `package dockle.latest
+
+test_latest {
+	deny["latest tag is not allowed"] with input as {"image": "app:latest"}
+}
+
+test_pinned {
+	count(deny) == 0 with input as {"image": "app:latest"}
+}
+`,
+				},
+				"",
+				false,
+			},
+			want{
+				"data.dockle.latest.test_pinned: FAIL\nFAIL: 1/2\n",
+				"policy tests failed",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				map[string]string{
+					"latest.rego": policy,
+				},
+				"",
+				false,
+			},
+			want{
+				"",
+				"no tests found",
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, err := ioutil.TempDir("", "policy")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			for filename, content := range in.files {
+				if err := ioutil.WriteFile(filepath.Join(dir, filename), []byte(content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			output := &bytes.Buffer{}
+			err = scan.RunPolicyTests(context.Background(), output, []string{dir}, in.run, in.verbose)
+			// Durations vary by runs.
+			got := regexp.MustCompile(` \(.+\)\n`).ReplaceAllString(output.String(), "\n")
+			if diff := cmp.Diff(want.output, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			gotErrorString := ""
+			if err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(want.errorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package scan
+
+import (
+	"context"
+	"io"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/report"
+	"kube-dockle-exporter/pkg/server"
+	"kube-dockle-exporter/pkg/server/collector"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"golang.org/x/xerrors"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ErrThresholdExceeded is returned when findings at or above the level of --fail-on are found.
+var ErrThresholdExceeded = xerrors.New("findings exceed threshold")
+
+func Run(a *Args) error {
+	if err := validate(a.Format, a.FailOn); err != nil {
+		return err
+	}
+
+	kubeConfig, err := client.NewRESTConfig(a.Kubeconfig, a.Context, float32(a.KubeAPIQPS), int(a.KubeAPIBurst))
+	if err != nil {
+		return xerrors.Errorf("failed to create kubernetes config: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return xerrors.Errorf("failed to create kubernetes client: %w", err)
+	}
+
+	kubernetesClient := &client.KubernetesClient{
+		Inner: clientset,
+	}
+	filters, err := loadFilters(a.Config, a.IgnoreCodes, a.IgnoreImages, kubernetesClient)
+	if err != nil {
+		return err
+	}
+
+	return scanAndReport(
+		kubernetesClient,
+		a.DockleConcurrency,
+		filters,
+		a.Format,
+		a.Output,
+		a.FailOn,
+	)
+}
+
+// filters are settings of the server which scans share.
+type filters struct {
+	ignore     *collector.Ignore
+	exceptions *collector.Exceptions
+	severities *collector.Severities
+	registries []client.Registry
+	namespaces collector.INamespaceClient
+}
+
+// loadFilters reads ignore, exceptions, severities and registries from the config of the server if given.
+// Labels of namespaces are fetched with namespaces on each scan, and severities matching them are rejected if it is nil.
+func loadFilters(
+	path string,
+	ignoreCodes []string,
+	ignoreImages []string,
+	namespaces collector.INamespaceClient,
+) (*filters, error) {
+	config := &server.Config{}
+	if path != "" {
+		var err error
+		config, err = server.LoadConfig(path)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to load config: %w", err)
+		}
+	}
+	ignore, err := collector.NewIgnore(
+		append(append([]string{}, ignoreCodes...), config.Ignore.Codes...),
+		append(append([]string{}, ignoreImages...), config.Ignore.Images...),
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create ignore: %w", err)
+	}
+	// Without clusters, labels of namespaces are unknown, and severities matching them would never apply silently.
+	if namespaces == nil {
+		for i, severity := range config.Severities {
+			if len(severity.NamespaceLabels) > 0 {
+				return nil, xerrors.Errorf("severities[%d]: namespaceLabels cannot be matched without clusters", i)
+			}
+		}
+	}
+	exceptions, err := collector.NewExceptions(config.Exceptions)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create exceptions: %w", err)
+	}
+	severities, err := collector.NewSeverities(config.Severities)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create severities: %w", err)
+	}
+	registries, err := config.ResolveRegistries()
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load config: %w", err)
+	}
+	return &filters{
+		ignore:     ignore,
+		exceptions: exceptions,
+		severities: severities,
+		registries: registries,
+		namespaces: namespaces,
+	}, nil
+}
+
+func validate(format string, failOn string) error {
+	valid := false
+	for _, f := range report.Formats() {
+		if f == format {
+			valid = true
+		}
+	}
+	if !valid {
+		return xerrors.Errorf("unknown format: %s", format)
+	}
+	if failOn != "" && client.LevelSeverity(failOn) == 0 {
+		return xerrors.Errorf("unknown level: %s", failOn)
+	}
+	return nil
+}
+
+// scanAndReport scans images of workloads with the same collector as the server, and writes the results.
+func scanAndReport(
+	kubernetesClient collector.IKubernetesClient,
+	concurrency int64,
+	filters *filters,
+	format string,
+	output string,
+	failOn string,
+) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(quit)
+	go func() {
+		select {
+		case <-quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	dockleClient := &client.DockleClient{}
+	dockleClient.SetRegistries(filters.registries)
+	dockleCollector := collector.NewDockleCollector(
+		client.NewStandardLogger(false),
+		kubernetesClient,
+		dockleClient,
+		concurrency,
+	)
+	dockleCollector.SetIgnore(filters.ignore)
+	dockleCollector.SetExceptions(filters.exceptions)
+	dockleCollector.SetSeverities(filters.severities)
+	dockleCollector.SetNamespaces(filters.namespaces)
+	if err := dockleCollector.Scan(ctx); err != nil {
+		return xerrors.Errorf("failed to scan: %w", err)
+	}
+	snapshot := dockleCollector.Snapshot()
+	responses := snapshot.SortedResponses()
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		file, err := os.Create(output)
+		if err != nil {
+			return xerrors.Errorf("failed to create output: %w", err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := report.Write(w, format, responses); err != nil {
+		return xerrors.Errorf("failed to write report: %w", err)
+	}
+
+	// Images which could not be scanned must not pass checks silently.
+	var failed []string
+	for _, image := range snapshot.Images() {
+		if _, ok := snapshot.Responses[image]; !ok && !filters.ignore.IgnoresImage(image) {
+			failed = append(failed, image)
+		}
+	}
+	if len(failed) > 0 {
+		return xerrors.Errorf("failed to scan images: %s", strings.Join(failed, ", "))
+	}
+	if failOn != "" && Exceeds(responses, failOn) {
+		return ErrThresholdExceeded
+	}
+	return nil
+}
+
+// Exceeds reports whether any finding of responses is at or above the level.
+func Exceeds(responses []client.DockleResponse, level string) bool {
+	threshold := client.LevelSeverity(level)
+	for _, response := range responses {
+		for _, detail := range response.Details {
+			if client.LevelSeverity(detail.Level) >= threshold {
+				return true
+			}
+		}
+	}
+	return false
+}
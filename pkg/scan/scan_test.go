@@ -0,0 +1,81 @@
+package scan_test
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/scan"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExceeds(t *testing.T) {
+	type in struct {
+		first  []client.DockleResponse
+		second string
+	}
+
+	responses := []client.DockleResponse{
+		{
+			Target: "fake",
+			Details: []client.DockleDetail{
+				{Code: "CIS-DI-0001", Level: "WARN"},
+				{Code: "DKL-LI-0003", Level: "INFO"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		in   in
+		want bool
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				responses,
+				"FATAL",
+			},
+			false,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				responses,
+				"WARN",
+			},
+			true,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				nil,
+				"SKIP",
+			},
+			false,
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := scan.Exceeds(in.first, in.second)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
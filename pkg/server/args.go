@@ -1,39 +1,155 @@
 package server
 
-import "math"
+import (
+	"kube-dockle-exporter/pkg/client"
+	"math"
+
+	"golang.org/x/xerrors"
+)
 
 type Args struct {
-	APIAddress               string
-	APIMaxConnections        int64
-	MonitorAddress           string
-	MonitorMaxConnections    int64
-	MonitoringJaegerEndpoint string
-	EnableProfiling          bool
-	EnableTracing            bool
-	TracingSampleRate        float64
-	KeepAlived               bool
-	ReUsePort                bool
-	TCPKeepAliveInterval     int64
-	DockleConcurrency        int64
-	CollectorLoopInterval    int64
-	Verbose                  bool
+	Config                      string
+	ConfigReloadInterval        int64
+	Kubeconfig                  string
+	Context                     string
+	KubeAPIQPS                  float64
+	KubeAPIBurst                int64
+	ClusterName                 string
+	ClusterContexts             []string
+	ClusterSecretNamespace      string
+	ClusterSecretSelector       string
+	APIAddress                  string
+	APIMaxConnections           int64
+	MonitorAddress              string
+	MonitorMaxConnections       int64
+	MonitoringJaegerEndpoint    string
+	EnableProfiling             bool
+	EnableTracing               bool
+	TracingSampleRate           float64
+	KeepAlived                  bool
+	ReUsePort                   bool
+	TCPKeepAliveInterval        int64
+	DockleConcurrency           int64
+	IgnoreCodes                 []string
+	IgnoreImages                []string
+	PolicyPaths                 []string
+	DisableCorrelationRules     []string
+	NamespaceOwnerLabels        []string
+	NamespaceOwnerAnnotations   []string
+	CollectorLoopInterval       int64
+	LeaderElect                 bool
+	LeaderElectionNamespace     string
+	LeaderElectionName          string
+	LeaderElectionLeaseDuration int64
+	LeaderElectionRenewDeadline int64
+	LeaderElectionRetryPeriod   int64
+	Shard                       bool
+	ShardNamespace              string
+	ShardStatefulSetName        string
+	EnablePolicyReport          bool
+	PolicyReportScope           string
+	EnableEvents                bool
+	EventLevel                  string
+	EventQPS                    float64
+	EventBurst                  int64
+	HistoryPath                 string
+	HistoryRetention            int64
+	NotificationLevel           string
+	NotificationConfig          string
+	SlackWebhookURL             string
+	SlackRateLimit              float64
+	SlackRetries                int64
+	ExternalURL                 string
+	WebhookURL                  string
+	WebhookMode                 string
+	WebhookSecretFile           string
+	WebhookSource               string
+	WebhookRetries              int64
+	WebhookOutboxPath           string
+	AlertmanagerURL             string
+	AlertmanagerLevel           string
+	Verbose                     bool
 }
 
 func DefaultArgs() *Args {
 	return &Args{
-		APIAddress:               "127.0.0.1:8000",
-		APIMaxConnections:        math.MaxInt64,
-		MonitorAddress:           "127.0.0.1:9090",
-		MonitorMaxConnections:    math.MaxInt64,
-		MonitoringJaegerEndpoint: "jaeger-agent.istio-system.svc.cluster.local:6831",
-		EnableProfiling:          false,
-		EnableTracing:            false,
-		TracingSampleRate:        0,
-		KeepAlived:               true,
-		ReUsePort:                false,
-		TCPKeepAliveInterval:     0,
-		DockleConcurrency:        10,
-		CollectorLoopInterval:    60,
-		Verbose:                  false,
+		Config:                      "",
+		ConfigReloadInterval:        10,
+		Kubeconfig:                  "",
+		Context:                     "",
+		KubeAPIQPS:                  5,
+		KubeAPIBurst:                10,
+		ClusterName:                 "",
+		ClusterContexts:             []string{},
+		ClusterSecretNamespace:      "default",
+		ClusterSecretSelector:       "",
+		APIAddress:                  "127.0.0.1:8000",
+		APIMaxConnections:           math.MaxInt64,
+		MonitorAddress:              "127.0.0.1:9090",
+		MonitorMaxConnections:       math.MaxInt64,
+		MonitoringJaegerEndpoint:    "jaeger-agent.istio-system.svc.cluster.local:6831",
+		EnableProfiling:             false,
+		EnableTracing:               false,
+		TracingSampleRate:           0,
+		KeepAlived:                  true,
+		ReUsePort:                   false,
+		TCPKeepAliveInterval:        0,
+		DockleConcurrency:           10,
+		IgnoreCodes:                 []string{},
+		IgnoreImages:                []string{},
+		PolicyPaths:                 []string{},
+		DisableCorrelationRules:     []string{},
+		NamespaceOwnerLabels:        []string{},
+		NamespaceOwnerAnnotations:   []string{},
+		CollectorLoopInterval:       60,
+		LeaderElect:                 false,
+		LeaderElectionNamespace:     "default",
+		LeaderElectionName:          "kube-dockle-exporter",
+		LeaderElectionLeaseDuration: 15,
+		LeaderElectionRenewDeadline: 10,
+		LeaderElectionRetryPeriod:   2,
+		Shard:                       false,
+		ShardNamespace:              "default",
+		ShardStatefulSetName:        "kube-dockle-exporter",
+		EnablePolicyReport:          false,
+		PolicyReportScope:           "namespace",
+		EnableEvents:                false,
+		EventLevel:                  "WARN",
+		EventQPS:                    1. / 300.,
+		EventBurst:                  25,
+		HistoryPath:                 "",
+		HistoryRetention:            30 * 24 * 60 * 60,
+		NotificationLevel:           "FATAL",
+		NotificationConfig:          "",
+		SlackWebhookURL:             "",
+		SlackRateLimit:              1,
+		SlackRetries:                3,
+		ExternalURL:                 "",
+		WebhookURL:                  "",
+		WebhookMode:                 "structured",
+		WebhookSecretFile:           "",
+		WebhookSource:               "kube-dockle-exporter",
+		WebhookRetries:              3,
+		WebhookOutboxPath:           "",
+		AlertmanagerURL:             "",
+		AlertmanagerLevel:           "WARN",
+		Verbose:                     false,
+	}
+}
+
+// Validate rejects unknown levels, which would otherwise let every finding through.
+func (a *Args) Validate() error {
+	for _, flag := range []struct {
+		name  string
+		level string
+	}{
+		{"event-level", a.EventLevel},
+		{"notification-level", a.NotificationLevel},
+		{"alertmanager-level", a.AlertmanagerLevel},
+	} {
+		if client.LevelSeverity(flag.level) == 0 {
+			return xerrors.Errorf("%s: unknown level: %s", flag.name, flag.level)
+		}
 	}
+	return nil
 }
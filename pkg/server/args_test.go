@@ -0,0 +1,65 @@
+package server_test
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/server"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestArgsValidate(t *testing.T) {
+	tests := []struct {
+		name            string
+		in              func(*server.Args)
+		wantErrorString string
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func(a *server.Args) {},
+			"",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func(a *server.Args) {
+				a.EventLevel = "warn"
+			},
+			"event-level: unknown level: warn",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func(a *server.Args) {
+				a.AlertmanagerLevel = "PASS"
+			},
+			"alertmanager-level: unknown level: PASS",
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		in := tt.in
+		wantErrorString := tt.wantErrorString
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			args := server.DefaultArgs()
+			in(args)
+			gotErrorString := ""
+			if err := args.Validate(); err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(wantErrorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
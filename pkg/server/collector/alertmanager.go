@@ -0,0 +1,117 @@
+package collector
+
+import (
+	"context"
+	"kube-dockle-exporter/pkg/client"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	alertName = "DockleFinding"
+)
+
+// AlertmanagerPublisher posts findings at or above the level as alerts every scan.
+// Alerts expire after the timeout unless a later scan refreshes them, and are resolved at once when findings disappear.
+// Alerts of images which failed to be scanned are refreshed until the next successful scan, so that they do not flap.
+type AlertmanagerPublisher struct {
+	client  IAlertmanagerClient
+	level   string
+	timeout time.Duration
+	apiURL  string
+	firing  map[string]client.Alert
+	now     func() time.Time
+}
+
+func NewAlertmanagerPublisher(alertmanagerClient IAlertmanagerClient, level string, timeout time.Duration, apiURL string) *AlertmanagerPublisher {
+	return &AlertmanagerPublisher{
+		client:  alertmanagerClient,
+		level:   level,
+		timeout: timeout,
+		apiURL:  strings.TrimSuffix(apiURL, "/"),
+		firing:  make(map[string]client.Alert),
+		now:     time.Now,
+	}
+}
+
+func (p *AlertmanagerPublisher) Alerts(snapshot *Snapshot) map[string]client.Alert {
+	alerts := make(map[string]client.Alert)
+	for _, finding := range snapshot.WorkloadFindings() {
+		if !finding.AtLeast(p.level) {
+			continue
+		}
+		alert := client.Alert{
+			Labels: map[string]string{
+				"alertname":     alertName,
+				"image":         finding.Image,
+				"code":          finding.Code,
+				"level":         finding.Level,
+				"namespace":     finding.Workload.Namespace,
+				"workload":      finding.Workload.Name,
+				"workload_kind": finding.Workload.Kind,
+			},
+			Annotations: map[string]string{
+				"summary": finding.Title,
+			},
+		}
+		if finding.Workload.Cluster != "" {
+			alert.Labels["cluster"] = finding.Workload.Cluster
+		}
+		if len(finding.Alerts) > 0 {
+			alert.Annotations["description"] = strings.Join(finding.Alerts, "\n")
+		}
+		if p.apiURL != "" {
+			alert.GeneratorURL = p.apiURL + "/ui/images/" + finding.Image
+		}
+		alerts[finding.Key()] = alert
+	}
+	return alerts
+}
+
+func (p *AlertmanagerPublisher) Publish(ctx context.Context, snapshot *Snapshot) error {
+	now := p.now()
+	current := p.Alerts(snapshot)
+	unscanned := snapshot.unscanned()
+	for key, alert := range p.firing {
+		if _, ok := current[key]; !ok && unscanned[alert.Labels["cluster"]+" "+alert.Labels["image"]] {
+			current[key] = alert
+		}
+	}
+
+	alerts := make([]client.Alert, 0, len(current)+len(p.firing))
+	for key, alert := range current {
+		if firing, ok := p.firing[key]; ok {
+			alert.StartsAt = firing.StartsAt
+		} else {
+			alert.StartsAt = now
+		}
+		alert.EndsAt = now.Add(p.timeout)
+		current[key] = alert
+		alerts = append(alerts, alert)
+	}
+	for key, alert := range p.firing {
+		if _, ok := current[key]; ok {
+			continue
+		}
+		alert.EndsAt = now
+		alerts = append(alerts, alert)
+	}
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	// Firing alerts are kept on failure, so that the next scan resolves disappeared ones again.
+	if err := p.client.PostAlerts(ctx, alerts); err != nil {
+		for key, alert := range p.firing {
+			if _, ok := current[key]; !ok {
+				current[key] = alert
+			}
+		}
+		p.firing = current
+		return xerrors.Errorf("failed to post alerts: %w", err)
+	}
+	p.firing = current
+	return nil
+}
@@ -0,0 +1,165 @@
+package collector_test
+
+import (
+	"context"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/xerrors"
+)
+
+func TestAlertmanagerPublisherAlerts(t *testing.T) {
+	publisher := collector.NewAlertmanagerPublisher(&alertmanagerClientMock{}, "WARN", time.Hour, "http://exporter.example/")
+	want := map[string]client.Alert{
+		"Deployment/fake/fake fake CIS-DI-0001": {
+			Labels: map[string]string{
+				"alertname":     "DockleFinding",
+				"image":         "fake",
+				"code":          "CIS-DI-0001",
+				"level":         "WARN",
+				"namespace":     "fake",
+				"workload":      "fake",
+				"workload_kind": "Deployment",
+			},
+			Annotations: map[string]string{
+				"summary":     "Create a user for the container",
+				"description": "Last user should not be root",
+			},
+			GeneratorURL: "http://exporter.example/ui/images/fake",
+		},
+	}
+	if diff := cmp.Diff(want, publisher.Alerts(fakeSnapshot())); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestAlertmanagerPublisherPublish(t *testing.T) {
+	withFinding := func() *collector.Snapshot {
+		snapshot := fakeSnapshot()
+		response := snapshot.Responses["fake"]
+		response.Details = append(response.Details, client.DockleDetail{
+			Code:  "CIS-DI-0005",
+			Title: "Enable Content trust for Docker",
+			Level: "FATAL",
+		})
+		snapshot.Responses["fake"] = response
+		return snapshot
+	}
+	failed := func() *collector.Snapshot {
+		snapshot := fakeSnapshot()
+		snapshot.Responses = map[string]client.DockleResponse{}
+		snapshot.Failed = map[string]bool{"fake": true}
+		return snapshot
+	}
+
+	tests := []struct {
+		name           string
+		fakePostAlerts func(context.Context, []client.Alert) error
+		in             []*collector.Snapshot
+		want           [][]string
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			nil,
+			[]*collector.Snapshot{
+				fakeSnapshot(),
+				withFinding(),
+				withFinding(),
+				fakeSnapshot(),
+				fakeSnapshot(),
+			},
+			[][]string{
+				{"firing CIS-DI-0005"},
+				{"firing CIS-DI-0005"},
+				{"resolved CIS-DI-0005"},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func() func(context.Context, []client.Alert) error {
+				called := 0
+				return func(context.Context, []client.Alert) error {
+					called++
+					if called == 2 {
+						return xerrors.New("fake")
+					}
+					return nil
+				}
+			}(),
+			[]*collector.Snapshot{
+				withFinding(),
+				fakeSnapshot(),
+				fakeSnapshot(),
+				fakeSnapshot(),
+			},
+			[][]string{
+				{"firing CIS-DI-0005"},
+				{"resolved CIS-DI-0005"},
+				{"resolved CIS-DI-0005"},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			nil,
+			[]*collector.Snapshot{
+				withFinding(),
+				failed(),
+				withFinding(),
+			},
+			[][]string{
+				{"firing CIS-DI-0005"},
+				{"firing CIS-DI-0005"},
+				{"firing CIS-DI-0005"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		fakePostAlerts := tt.fakePostAlerts
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			alertmanagerClient := &alertmanagerClientMock{
+				fakePostAlerts: fakePostAlerts,
+			}
+			publisher := collector.NewAlertmanagerPublisher(alertmanagerClient, "FATAL", time.Hour, "")
+			for _, snapshot := range in {
+				_ = publisher.Publish(context.Background(), snapshot)
+			}
+
+			got := make([][]string, 0, len(alertmanagerClient.posted))
+			for _, alerts := range alertmanagerClient.posted {
+				states := make([]string, 0, len(alerts))
+				for _, alert := range alerts {
+					state := "firing"
+					if !alert.EndsAt.After(time.Now()) {
+						state = "resolved"
+					}
+					states = append(states, state+" "+alert.Labels["code"])
+				}
+				sort.Strings(states)
+				got = append(got, states)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
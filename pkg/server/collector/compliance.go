@@ -0,0 +1,77 @@
+package collector
+
+import (
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/report"
+	"sort"
+)
+
+// CheckCompliance counts scanned images of a cluster by their results of a check.
+type CheckCompliance struct {
+	Cluster string
+	Code    string
+	// Failing counts images failing the check by its levels.
+	Failing map[string]int
+	// Passing counts images which neither fail nor skip the check.
+	Passing int
+}
+
+// Compliance returns the number of scanned images of each cluster and results of each check in each cluster,
+// covering checks which no image fails as well unless ignore drops them.
+// Findings which exceptions suppress are out of responses, and do not fail checks.
+func (s *Snapshot) Compliance(ignore *Ignore) (map[string]int, []CheckCompliance) {
+	imageClusters := s.ImageClusters()
+	scanned := make(map[string]int)
+	checks := make(map[string]map[string]*CheckCompliance)
+	notPassing := make(map[string]map[string]bool)
+	for image, response := range s.Responses {
+		for _, cluster := range imageClusters[image] {
+			if checks[cluster] == nil {
+				checks[cluster] = make(map[string]*CheckCompliance)
+				for code := range report.Checkpoints() {
+					if ignore != nil && ignore.IgnoresCode(code) {
+						continue
+					}
+					checks[cluster][code] = &CheckCompliance{Cluster: cluster, Code: code, Failing: map[string]int{}}
+				}
+			}
+			scanned[cluster]++
+			for _, detail := range response.Details {
+				check, ok := checks[cluster][detail.Code]
+				if !ok {
+					check = &CheckCompliance{Cluster: cluster, Code: detail.Code, Failing: map[string]int{}}
+					checks[cluster][detail.Code] = check
+				}
+				if detail.Level == client.LevelPass {
+					continue
+				}
+				key := cluster + " " + detail.Code
+				if notPassing[key] == nil {
+					notPassing[key] = make(map[string]bool)
+				}
+				if notPassing[key][image] {
+					continue
+				}
+				notPassing[key][image] = true
+				if detail.Level != client.LevelSkip {
+					check.Failing[detail.Level]++
+				}
+			}
+		}
+	}
+
+	var compliance []CheckCompliance
+	for cluster, clusterChecks := range checks {
+		for code, check := range clusterChecks {
+			check.Passing = scanned[cluster] - len(notPassing[cluster+" "+code])
+			compliance = append(compliance, *check)
+		}
+	}
+	sort.Slice(compliance, func(i, j int) bool {
+		if compliance[i].Cluster != compliance[j].Cluster {
+			return compliance[i].Cluster < compliance[j].Cluster
+		}
+		return compliance[i].Code < compliance[j].Code
+	})
+	return scanned, compliance
+}
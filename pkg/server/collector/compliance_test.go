@@ -0,0 +1,83 @@
+package collector_test
+
+import (
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestSnapshotCompliance(t *testing.T) {
+	workload := func(cluster string, image string) client.Workload {
+		return client.Workload{
+			Cluster: cluster,
+			PodSpec: v1.PodSpec{Containers: []v1.Container{{Image: image}}},
+		}
+	}
+	snapshot := &collector.Snapshot{
+		Workloads: []client.Workload{
+			workload("", "app:1"),
+			workload("", "app:2"),
+			workload("", "app:3"),
+			workload("remote", "app:2"),
+		},
+		Responses: map[string]client.DockleResponse{
+			"app:1": {
+				Target: "app:1",
+				Details: []client.DockleDetail{
+					{Code: "CIS-DI-0001", Level: "WARN"},
+					{Code: "CIS-DI-0005", Level: "INFO"},
+				},
+			},
+			"app:2": {
+				Target: "app:2",
+				Details: []client.DockleDetail{
+					{Code: "CIS-DI-0001", Level: "FATAL"},
+					{Code: "CIS-DI-0005", Level: "SKIP"},
+					{Code: "CUSTOM-0001", Level: "WARN"},
+				},
+			},
+			"app:3": {
+				Target: "app:3",
+			},
+		},
+	}
+
+	ignore, err := collector.NewIgnore([]string{"CIS-DI-0006"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanned, compliance := snapshot.Compliance(ignore)
+	if diff := cmp.Diff(map[string]int{"": 3, "remote": 1}, scanned); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	checks := make(map[string]collector.CheckCompliance, len(compliance))
+	for _, check := range compliance {
+		checks[check.Cluster+" "+check.Code] = check
+	}
+	want := map[string]collector.CheckCompliance{
+		" CIS-DI-0001": {Code: "CIS-DI-0001", Failing: map[string]int{"FATAL": 1, "WARN": 1}, Passing: 1},
+		// Skipped images neither fail nor pass.
+		" CIS-DI-0005": {Code: "CIS-DI-0005", Failing: map[string]int{"INFO": 1}, Passing: 1},
+		// Checks which no image fails are covered as well.
+		" CIS-DI-0010": {Code: "CIS-DI-0010", Failing: map[string]int{}, Passing: 3},
+		" CUSTOM-0001": {Code: "CUSTOM-0001", Failing: map[string]int{"WARN": 1}, Passing: 2},
+		// Images are counted in each cluster running them.
+		"remote CIS-DI-0001": {Cluster: "remote", Code: "CIS-DI-0001", Failing: map[string]int{"FATAL": 1}, Passing: 0},
+		"remote CIS-DI-0010": {Cluster: "remote", Code: "CIS-DI-0010", Failing: map[string]int{}, Passing: 1},
+	}
+	for key, wantCheck := range want {
+		if diff := cmp.Diff(wantCheck, checks[key]); diff != "" {
+			t.Errorf("%s (-want +got):\n%s", key, diff)
+		}
+	}
+	// Ignored checks would pass on every image otherwise.
+	for key := range checks {
+		if strings.HasSuffix(key, " CIS-DI-0006") {
+			t.Errorf("%s is not ignored", key)
+		}
+	}
+}
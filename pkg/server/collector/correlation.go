@@ -0,0 +1,218 @@
+package collector
+
+import (
+	"sort"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// StatusEffective is the status of findings which workloads are exposed to.
+	StatusEffective = "effective"
+	// StatusMitigated is the status of findings which specs of workloads mitigate.
+	StatusMitigated = "mitigated"
+)
+
+// ICorrelationRule decides whether the spec of a container mitigates findings of its codes.
+type ICorrelationRule interface {
+	Name() string
+	Codes() []string
+	Mitigates(podSpec *v1.PodSpec, container *v1.Container) bool
+}
+
+// CorrelatedFinding is a finding on a workload with its status, and the rule which mitigates it if any.
+type CorrelatedFinding struct {
+	Workload WorkloadRef `json:"workload"`
+	Finding
+	Status string `json:"status"`
+	Rule   string `json:"rule,omitempty"`
+}
+
+func (f *CorrelatedFinding) Key() string {
+	return f.Workload.key() + " " + f.Finding.Key()
+}
+
+// Correlator evaluates findings against specs of workloads running their images.
+type Correlator struct {
+	rules map[string][]ICorrelationRule
+}
+
+// DefaultCorrelationRules returns the built-in rules.
+func DefaultCorrelationRules() []ICorrelationRule {
+	return []ICorrelationRule{
+		&NonRootRule{},
+		&LivenessProbeRule{},
+		&NoPrivilegeEscalationRule{},
+	}
+}
+
+// NewCorrelator uses rules except those whose names are disabled.
+func NewCorrelator(rules []ICorrelationRule, disabled []string) (*Correlator, error) {
+	names := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		names[rule.Name()] = true
+	}
+	disabledNames := make(map[string]bool, len(disabled))
+	for _, name := range disabled {
+		if !names[name] {
+			return nil, xerrors.Errorf("unknown correlation rule: %s", name)
+		}
+		disabledNames[name] = true
+	}
+
+	correlator := &Correlator{
+		rules: make(map[string][]ICorrelationRule),
+	}
+	for _, rule := range rules {
+		if disabledNames[rule.Name()] {
+			continue
+		}
+		for _, code := range rule.Codes() {
+			correlator.rules[code] = append(correlator.rules[code], rule)
+		}
+	}
+	return correlator, nil
+}
+
+// mitigatingRule returns a rule which mitigates the code on all containers of the workload running the image.
+func (c *Correlator) mitigatingRule(podSpec *v1.PodSpec, image string, code string) ICorrelationRule {
+	var containers []*v1.Container
+	for i := range podSpec.InitContainers {
+		if podSpec.InitContainers[i].Image == image {
+			containers = append(containers, &podSpec.InitContainers[i])
+		}
+	}
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Image == image {
+			containers = append(containers, &podSpec.Containers[i])
+		}
+	}
+	if len(containers) == 0 {
+		return nil
+	}
+
+	var mitigating ICorrelationRule
+	for _, container := range containers {
+		var found ICorrelationRule
+		for _, rule := range c.rules[code] {
+			if rule.Mitigates(podSpec, container) {
+				found = rule
+				break
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		if mitigating == nil {
+			mitigating = found
+		}
+	}
+	return mitigating
+}
+
+// Apply correlates findings of each workload into the snapshot.
+func (c *Correlator) Apply(snapshot *Snapshot) {
+	var correlated []CorrelatedFinding
+	for _, finding := range snapshot.WorkloadFindings() {
+		correlatedFinding := CorrelatedFinding{
+			Workload: refOf(&finding.Workload),
+			Finding:  finding.Finding,
+			Status:   StatusEffective,
+		}
+		if rule := c.mitigatingRule(&finding.Workload.PodSpec, finding.Image, finding.Code); rule != nil {
+			correlatedFinding.Status = StatusMitigated
+			correlatedFinding.Rule = rule.Name()
+		}
+		correlated = append(correlated, correlatedFinding)
+	}
+	sort.Slice(correlated, func(i, j int) bool {
+		return correlated[i].Key() < correlated[j].Key()
+	})
+	snapshot.Correlated = correlated
+}
+
+// Mitigated returns whether findings are mitigated on all workloads running their images in each cluster,
+// keyed by names of clusters and keys of findings.
+func (s *Snapshot) Mitigated() map[string]bool {
+	mitigated := make(map[string]bool)
+	for _, finding := range s.Correlated {
+		key := finding.Workload.Cluster + " " + finding.Finding.Key()
+		if current, ok := mitigated[key]; ok && !current {
+			continue
+		}
+		mitigated[key] = finding.Status == StatusMitigated
+	}
+	return mitigated
+}
+
+// securityContext returns the security context of the container overriding that of the pod.
+func securityContext(podSpec *v1.PodSpec, container *v1.Container) (runAsNonRoot *bool, runAsUser *int64) {
+	if podSpec.SecurityContext != nil {
+		runAsNonRoot = podSpec.SecurityContext.RunAsNonRoot
+		runAsUser = podSpec.SecurityContext.RunAsUser
+	}
+	if container.SecurityContext != nil {
+		if container.SecurityContext.RunAsNonRoot != nil {
+			runAsNonRoot = container.SecurityContext.RunAsNonRoot
+		}
+		if container.SecurityContext.RunAsUser != nil {
+			runAsUser = container.SecurityContext.RunAsUser
+		}
+	}
+	return runAsNonRoot, runAsUser
+}
+
+// NonRootRule mitigates the root user of images when containers run as a user other than root.
+type NonRootRule struct{}
+
+func (r *NonRootRule) Name() string {
+	return "non-root"
+}
+
+func (r *NonRootRule) Codes() []string {
+	return []string{"CIS-DI-0001"}
+}
+
+func (r *NonRootRule) Mitigates(podSpec *v1.PodSpec, container *v1.Container) bool {
+	runAsNonRoot, runAsUser := securityContext(podSpec, container)
+	if runAsUser != nil {
+		return *runAsUser != 0
+	}
+	// Kubelet refuses to start containers whose images run as root.
+	return runAsNonRoot != nil && *runAsNonRoot
+}
+
+// LivenessProbeRule mitigates missing HEALTHCHECK of images, which Kubernetes ignores, when containers have liveness probes.
+type LivenessProbeRule struct{}
+
+func (r *LivenessProbeRule) Name() string {
+	return "liveness-probe"
+}
+
+func (r *LivenessProbeRule) Codes() []string {
+	return []string{"CIS-DI-0006"}
+}
+
+func (r *LivenessProbeRule) Mitigates(podSpec *v1.PodSpec, container *v1.Container) bool {
+	return container.LivenessProbe != nil
+}
+
+// NoPrivilegeEscalationRule mitigates setuid and setgid files of images when containers cannot gain privileges by them.
+type NoPrivilegeEscalationRule struct{}
+
+func (r *NoPrivilegeEscalationRule) Name() string {
+	return "no-privilege-escalation"
+}
+
+func (r *NoPrivilegeEscalationRule) Codes() []string {
+	return []string{"CIS-DI-0008"}
+}
+
+func (r *NoPrivilegeEscalationRule) Mitigates(podSpec *v1.PodSpec, container *v1.Container) bool {
+	securityContext := container.SecurityContext
+	if securityContext == nil || securityContext.AllowPrivilegeEscalation == nil {
+		return false
+	}
+	return !*securityContext.AllowPrivilegeEscalation && (securityContext.Privileged == nil || !*securityContext.Privileged)
+}
@@ -0,0 +1,322 @@
+package collector_test
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+)
+
+func boolPointer(b bool) *bool {
+	return &b
+}
+
+func int64Pointer(i int64) *int64 {
+	return &i
+}
+
+type correlationRuleCase struct {
+	name      string
+	podSpec   v1.PodSpec
+	container v1.Container
+	want      bool
+}
+
+func testCorrelationRule(t *testing.T, rule collector.ICorrelationRule, tests []correlationRuleCase) {
+	for _, tt := range tests {
+		name := tt.name
+		podSpec := tt.podSpec
+		container := tt.container
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := rule.Mitigates(&podSpec, &container)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNonRootRule(t *testing.T) {
+	testCorrelationRule(t, &collector.NonRootRule{}, []correlationRuleCase{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			v1.PodSpec{},
+			v1.Container{},
+			false,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			v1.PodSpec{
+				SecurityContext: &v1.PodSecurityContext{
+					RunAsNonRoot: boolPointer(true),
+					RunAsUser:    int64Pointer(1000),
+				},
+			},
+			v1.Container{},
+			true,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			v1.PodSpec{},
+			v1.Container{
+				SecurityContext: &v1.SecurityContext{
+					RunAsNonRoot: boolPointer(true),
+				},
+			},
+			true,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			v1.PodSpec{
+				SecurityContext: &v1.PodSecurityContext{
+					RunAsUser: int64Pointer(1000),
+				},
+			},
+			v1.Container{
+				SecurityContext: &v1.SecurityContext{
+					RunAsUser: int64Pointer(0),
+				},
+			},
+			false,
+		},
+	})
+}
+
+func TestLivenessProbeRule(t *testing.T) {
+	testCorrelationRule(t, &collector.LivenessProbeRule{}, []correlationRuleCase{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			v1.PodSpec{},
+			v1.Container{
+				ReadinessProbe: &v1.Probe{},
+			},
+			false,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			v1.PodSpec{},
+			v1.Container{
+				LivenessProbe: &v1.Probe{},
+			},
+			true,
+		},
+	})
+}
+
+func TestNoPrivilegeEscalationRule(t *testing.T) {
+	testCorrelationRule(t, &collector.NoPrivilegeEscalationRule{}, []correlationRuleCase{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			v1.PodSpec{},
+			v1.Container{},
+			false,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			v1.PodSpec{},
+			v1.Container{
+				SecurityContext: &v1.SecurityContext{
+					AllowPrivilegeEscalation: boolPointer(false),
+				},
+			},
+			true,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			v1.PodSpec{},
+			v1.Container{
+				SecurityContext: &v1.SecurityContext{
+					AllowPrivilegeEscalation: boolPointer(false),
+					Privileged:               boolPointer(true),
+				},
+			},
+			false,
+		},
+	})
+}
+
+func TestCorrelatorApply(t *testing.T) {
+	type want struct {
+		correlated  []string
+		mitigated   map[string]bool
+		errorString string
+	}
+
+	snapshot := func() *collector.Snapshot {
+		nonRoot := &v1.SecurityContext{RunAsNonRoot: boolPointer(true)}
+		return &collector.Snapshot{
+			Workloads: []client.Workload{
+				{
+					Cluster:   "remote",
+					Kind:      "Deployment",
+					Namespace: "fake",
+					Name:      "hardened",
+					PodSpec: v1.PodSpec{
+						Containers: []v1.Container{
+							{Image: "app:1", SecurityContext: nonRoot, LivenessProbe: &v1.Probe{}},
+						},
+					},
+				},
+				{
+					Kind:      "Deployment",
+					Namespace: "fake",
+					Name:      "sidecar",
+					PodSpec: v1.PodSpec{
+						Containers: []v1.Container{
+							{Image: "app:1", SecurityContext: nonRoot},
+							{Image: "app:1"},
+						},
+					},
+				},
+			},
+			Responses: map[string]client.DockleResponse{
+				"app:1": {
+					Target: "app:1",
+					Details: []client.DockleDetail{
+						{Code: "CIS-DI-0001", Level: "WARN"},
+						{Code: "CIS-DI-0006", Level: "INFO"},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		disabled []string
+		want     want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			nil,
+			want{
+				[]string{
+					// One of the containers runs as root.
+					"Deployment/fake/sidecar app:1 CIS-DI-0001 effective ",
+					"Deployment/fake/sidecar app:1 CIS-DI-0006 effective ",
+					"Deployment/fake/hardened app:1 CIS-DI-0001 mitigated non-root",
+					"Deployment/fake/hardened app:1 CIS-DI-0006 mitigated liveness-probe",
+				},
+				map[string]bool{
+					" app:1 CIS-DI-0001":       false,
+					" app:1 CIS-DI-0006":       false,
+					"remote app:1 CIS-DI-0001": true,
+					"remote app:1 CIS-DI-0006": true,
+				},
+				"",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]string{"non-root"},
+			want{
+				[]string{
+					"Deployment/fake/sidecar app:1 CIS-DI-0001 effective ",
+					"Deployment/fake/sidecar app:1 CIS-DI-0006 effective ",
+					"Deployment/fake/hardened app:1 CIS-DI-0001 effective ",
+					"Deployment/fake/hardened app:1 CIS-DI-0006 mitigated liveness-probe",
+				},
+				map[string]bool{
+					" app:1 CIS-DI-0001":       false,
+					" app:1 CIS-DI-0006":       false,
+					"remote app:1 CIS-DI-0001": false,
+					"remote app:1 CIS-DI-0006": true,
+				},
+				"",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]string{"fake"},
+			want{
+				nil,
+				nil,
+				"unknown correlation rule: fake",
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		disabled := tt.disabled
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			correlator, err := collector.NewCorrelator(collector.DefaultCorrelationRules(), disabled)
+			gotErrorString := ""
+			if err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(want.errorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+
+			s := snapshot()
+			correlator.Apply(s)
+			var correlated []string
+			for _, finding := range s.Correlated {
+				correlated = append(correlated, fmt.Sprintf(
+					"%s/%s/%s %s %s %s",
+					finding.Workload.Kind,
+					finding.Workload.Namespace,
+					finding.Workload.Name,
+					finding.Finding.Key(),
+					finding.Status,
+					finding.Rule,
+				))
+			}
+			if diff := cmp.Diff(want.correlated, correlated); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(want.mitigated, s.Mitigated()); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
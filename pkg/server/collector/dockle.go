@@ -10,19 +10,44 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/prometheus/client_golang/prometheus"
-	v1 "k8s.io/api/core/v1"
 )
 
 const (
 	namespace = "dockle"
 )
 
+// DockleCollector scans images of workloads, and exports the results as metrics and snapshots.
+// Settings under the mutex, i.e. those other than publishers and the image filter, can be set while scanning, and take
+// effect from the next scan.
 type DockleCollector struct {
 	Logger           ILogger
 	KubernetesClient IKubernetesClient
 	DockleClient     IDockleClient
 	concurrency      int64
 	vulnerabilities  *prometheus.GaugeVec
+	suppressed       *prometheus.GaugeVec
+	expired          *prometheus.GaugeVec
+	policyViolations *prometheus.GaugeVec
+	imageRisk        *prometheus.GaugeVec
+	workloadRisk     *prometheus.GaugeVec
+	namespaceRisk    *prometheus.GaugeVec
+	failingImages    *prometheus.GaugeVec
+	passingImages    *prometheus.GaugeVec
+	scannedImages    *prometheus.GaugeVec
+	publishers       []IPublisher
+	imageFilter      IImageFilter
+	namespaces       INamespaceClient
+	ignore           *Ignore
+	exceptions       *Exceptions
+	severities       *Severities
+	policies         *Policies
+	correlator       *Correlator
+	risk             *Risk
+	ownership        *Ownership
+	snapshot         *Snapshot
+	mutex            sync.RWMutex
+	// scanning serializes scans, so that publishers see them in order and Reset waits for the one in flight.
+	scanning sync.Mutex
 }
 
 func NewDockleCollector(
@@ -40,27 +65,222 @@ func NewDockleCollector(
 			Namespace: namespace,
 			Name:      "cis_benchmarks_total",
 			Help:      "CIS benchmarks executed by dockle",
-		}, []string{"image", "code", "level"}),
+		}, []string{"image", "code", "level", "cluster", "original_level", "status"}),
+		suppressed: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "suppressed_cis_benchmarks_total",
+			Help:      "CIS benchmarks executed by dockle which exceptions suppress",
+		}, []string{"image", "code", "level", "cluster", "owner"}),
+		expired: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "expired_exceptions",
+			Help:      "Number of exceptions which expired and no longer suppress findings",
+		}, []string{"owner"}),
+		policyViolations: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "policy_violations",
+			Help:      "Number of violations of policies by workloads",
+		}, []string{"policy", "cluster", "namespace", "kind", "name"}),
+		imageRisk: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "image_risk_score",
+			Help:      "Risk score of findings of the image",
+		}, []string{"cluster", "image"}),
+		workloadRisk: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "workload_risk_score",
+			Help:      "Risk score of findings of images of the workload",
+		}, []string{"cluster", "namespace", "kind", "name"}),
+		namespaceRisk: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "namespace_risk_score",
+			Help:      "Risk score of workloads in the namespace",
+		}, []string{"cluster", "namespace"}),
+		failingImages: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "check_failing_images",
+			Help:      "Number of scanned images failing the check",
+		}, []string{"code", "level", "cluster"}),
+		passingImages: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "check_passing_images",
+			Help:      "Number of scanned images passing the check",
+		}, []string{"code", "cluster"}),
+		scannedImages: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "scanned_images_total",
+			Help:      "Number of images scanned by dockle",
+		}, []string{"cluster"}),
 	}
 }
 
-func uniqueContainerImages(containers []v1.Container) []string {
-	keys := make(map[string]bool)
-	var images []string
-	for _, container := range containers {
-		image := container.Image
-		if _, value := keys[image]; !value {
-			keys[image] = true
-			images = append(images, image)
+func (c *DockleCollector) AddPublisher(publisher IPublisher) {
+	c.publishers = append(c.publishers, publisher)
+}
+
+// SetImageFilter restricts images to scan, and snapshots have responses of filtered images and workloads running them
+// only.
+func (c *DockleCollector) SetImageFilter(imageFilter IImageFilter) {
+	c.imageFilter = imageFilter
+}
+
+// SetIgnore skips ignored images, and drops ignored checks from responses.
+func (c *DockleCollector) SetIgnore(ignore *Ignore) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.ignore = ignore
+}
+
+// SetExceptions suppresses findings which exceptions cover.
+func (c *DockleCollector) SetExceptions(exceptions *Exceptions) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.exceptions = exceptions
+}
+
+// SetSeverities remaps levels of findings.
+func (c *DockleCollector) SetSeverities(severities *Severities) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.severities = severities
+}
+
+// SetPolicies evaluates policies over results of each scan.
+func (c *DockleCollector) SetPolicies(policies *Policies) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.policies = policies
+}
+
+// SetCorrelator correlates findings with specs of workloads.
+func (c *DockleCollector) SetCorrelator(correlator *Correlator) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.correlator = correlator
+}
+
+// SetRisk computes risk scores of each scan.
+func (c *DockleCollector) SetRisk(risk *Risk) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.risk = risk
+}
+
+// SetNamespaces fetches namespaces once on each scan, whose labels severities, ownership and routes share.
+func (c *DockleCollector) SetNamespaces(namespaces INamespaceClient) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.namespaces = namespaces
+}
+
+// SetOwnership tells owners of workloads from namespaces on each scan.
+func (c *DockleCollector) SetOwnership(ownership *Ownership) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.ownership = ownership
+}
+
+func (c *DockleCollector) Snapshot() *Snapshot {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.snapshot
+}
+
+// Reset drops the last results after the scan in flight if any, so that neither metrics nor the API serve them until
+// the next scan. Publishers implementing IResetter drop theirs as well.
+func (c *DockleCollector) Reset() {
+	c.scanning.Lock()
+	defer c.scanning.Unlock()
+	c.mutex.Lock()
+	for _, collector := range c.collectors() {
+		collector.(*prometheus.GaugeVec).Reset()
+	}
+	c.snapshot = nil
+	c.mutex.Unlock()
+
+	for _, publisher := range c.publishers {
+		if resetter, ok := publisher.(IResetter); ok {
+			resetter.Reset()
 		}
 	}
-	return images
 }
 
+// Scan scans images of workloads, and publishes the results unless ctx is done before, e.g. when the lease is lost.
 func (c *DockleCollector) Scan(ctx context.Context) error {
-	containers, err := c.KubernetesClient.Containers()
-	if err != nil {
-		return xerrors.Errorf("failed to get containers: %w", err)
+	c.scanning.Lock()
+	defer c.scanning.Unlock()
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("scan was canceled: %w", err)
+	}
+
+	c.mutex.RLock()
+	ignore := c.ignore
+	exceptions := c.exceptions
+	severities := c.severities
+	policies := c.policies
+	correlator := c.correlator
+	risk := c.risk
+	ownership := c.ownership
+	namespaces := c.namespaces
+	c.mutex.RUnlock()
+
+	workloads, err := c.KubernetesClient.Workloads()
+	var clusterErrors client.ClusterErrors
+	if xerrors.As(err, &clusterErrors) {
+		for name, clusterErr := range clusterErrors {
+			c.Logger.Errorf("Failed to get workloads of cluster %q, using the last ones: %s\n", name, clusterErr.Error())
+		}
+	} else if err != nil {
+		err = xerrors.Errorf("failed to get workloads: %w", err)
+		c.publishFailure(ctx, err)
+		return err
+	}
+	snapshot := &Snapshot{
+		Workloads: workloads,
+		Responses: make(map[string]client.DockleResponse),
+		Failed:    make(map[string]bool),
+		ScannedAt: time.Now(),
+	}
+
+	// Failures of namespaces leave workloads without owners and labels, but fail the scan only if severities match
+	// labels, since their levels would be wrong.
+	if namespaces != nil {
+		fetched, err := namespaces.Namespaces()
+		if xerrors.As(err, &clusterErrors) {
+			for name, clusterErr := range clusterErrors {
+				c.Logger.Errorf("Failed to get namespaces of cluster %q, using the last ones: %s\n", name, clusterErr.Error())
+			}
+		} else if err != nil {
+			if severities != nil && severities.usesLabels {
+				err = xerrors.Errorf("failed to get namespaces: %w", err)
+				c.publishFailure(ctx, err)
+				return err
+			}
+			c.Logger.Errorf("Failed to get namespaces: %s\n", err.Error())
+		}
+		snapshot.setNamespaces(fetched, ownership)
+	}
+
+	images := snapshot.Images()
+	// Images are filtered before ignored, so that the scope covers workloads running only ignored images as well.
+	if c.imageFilter != nil {
+		images, err = c.imageFilter.Filter(images)
+		if err != nil {
+			err = xerrors.Errorf("failed to filter images: %w", err)
+			c.publishFailure(ctx, err)
+			return err
+		}
+		snapshot.scopeTo(images)
+	}
+	if ignore != nil {
+		// nolint:prealloc
+		var filtered []string
+		for _, image := range images {
+			if !ignore.IgnoresImage(image) {
+				filtered = append(filtered, image)
+			}
+		}
+		images = filtered
 	}
 
 	semaphore := make(chan struct{}, c.concurrency)
@@ -68,9 +288,13 @@ func (c *DockleCollector) Scan(ctx context.Context) error {
 
 	wg := sync.WaitGroup{}
 	mutex := &sync.Mutex{}
+	fail := func(image string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		snapshot.Failed[image] = true
+	}
 
-	var dockleResponses []client.DockleResponse
-	for _, image := range uniqueContainerImages(containers) {
+	for _, image := range images {
 		wg.Add(1)
 		go func(image string) {
 			defer wg.Done()
@@ -82,39 +306,164 @@ func (c *DockleCollector) Scan(ctx context.Context) error {
 			out, err := c.DockleClient.Do(ctx, image)
 			if err != nil {
 				c.Logger.Errorf("Failed to execute CIS benchmark at %s: %s\n", image, err.Error())
+				fail(image)
 				return
 			}
 
 			var response client.DockleResponse
 			if err := json.Unmarshal(out, &response); err != nil {
 				c.Logger.Errorf("Failed to parse dockle response at %s: %s\n", image, err.Error())
+				fail(image)
 				return
 			}
 			response.Target = image
+			if ignore != nil {
+				response = ignore.Apply(response)
+			}
 			func() {
 				mutex.Lock()
 				defer mutex.Unlock()
-				dockleResponses = append(dockleResponses, response)
+				snapshot.Responses[image] = response
 			}()
 		}(image)
 	}
 	wg.Wait()
+	// Images which failed by cancellation are missing, and the results must not replace the last ones.
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("scan was canceled: %w", err)
+	}
+
+	// Exceptions see remapped levels, which suppressed findings keep.
+	if severities != nil {
+		severities.Apply(snapshot)
+	}
+
+	c.expired.Reset()
+	if exceptions != nil {
+		exceptions.Apply(snapshot)
+		for _, exception := range exceptions.Expired(snapshot.ScannedAt) {
+			c.expired.WithLabelValues(exception.Owner).Inc()
+		}
+	}
+
+	if correlator != nil {
+		correlator.Apply(snapshot)
+	}
 
 	c.vulnerabilities.Reset()
-	for _, dockleResponse := range dockleResponses {
+	imageClusters := snapshot.ImageClusters()
+	mitigated := snapshot.Mitigated()
+	for image, dockleResponse := range snapshot.Responses {
 		for _, detail := range dockleResponse.Details {
-			labels := []string{
-				dockleResponse.ExtractImage(),
-				detail.Code,
-				detail.Level,
+			for _, cluster := range imageClusters[image] {
+				status := StatusEffective
+				if mitigated[cluster+" "+dockleResponse.ExtractImage()+" "+detail.Code] {
+					status = StatusMitigated
+				}
+				labels := []string{
+					dockleResponse.ExtractImage(),
+					detail.Code,
+					detail.Level,
+					cluster,
+					detail.DockleLevel(),
+					status,
+				}
+				c.vulnerabilities.WithLabelValues(labels...).Set(1)
 			}
-			c.vulnerabilities.WithLabelValues(labels...).Set(1)
+		}
+	}
+
+	c.suppressed.Reset()
+	for _, finding := range snapshot.Suppressed {
+		for _, cluster := range imageClusters[finding.Image] {
+			c.suppressed.WithLabelValues(finding.Image, finding.Code, finding.Level, cluster, finding.Owner).Set(1)
+		}
+	}
+
+	c.failingImages.Reset()
+	c.passingImages.Reset()
+	c.scannedImages.Reset()
+	scanned, compliance := snapshot.Compliance(ignore)
+	for cluster, images := range scanned {
+		c.scannedImages.WithLabelValues(cluster).Set(float64(images))
+	}
+	for _, check := range compliance {
+		for level, failing := range check.Failing {
+			c.failingImages.WithLabelValues(check.Code, level, check.Cluster).Set(float64(failing))
+		}
+		c.passingImages.WithLabelValues(check.Code, check.Cluster).Set(float64(check.Passing))
+	}
+
+	c.imageRisk.Reset()
+	c.workloadRisk.Reset()
+	c.namespaceRisk.Reset()
+	if risk != nil {
+		snapshot.Risk = risk.Score(snapshot)
+		for _, image := range snapshot.Risk.Images {
+			c.imageRisk.WithLabelValues(image.Cluster, image.Image).Set(image.Score)
+		}
+		for _, workload := range snapshot.Risk.Workloads {
+			c.workloadRisk.WithLabelValues(
+				workload.Workload.Cluster,
+				workload.Workload.Namespace,
+				workload.Workload.Kind,
+				workload.Workload.Name,
+			).Set(workload.Score)
+		}
+		for _, namespace := range snapshot.Risk.Namespaces {
+			c.namespaceRisk.WithLabelValues(namespace.Cluster, namespace.Namespace).Set(namespace.Score)
+		}
+	}
+
+	// Policies see results after ignore, severities and exceptions, and failures of them do not fail the scan.
+	c.policyViolations.Reset()
+	if policies != nil {
+		violations, err := policies.Evaluate(ctx, snapshot)
+		if err != nil {
+			c.Logger.Errorf("Failed to evaluate policies: %s\n", err.Error())
+		}
+		snapshot.Violations = violations
+		for _, violation := range violations {
+			c.policyViolations.WithLabelValues(
+				violation.Policy,
+				violation.Workload.Cluster,
+				violation.Workload.Namespace,
+				violation.Workload.Kind,
+				violation.Workload.Name,
+			).Inc()
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return xerrors.Errorf("scan was canceled: %w", err)
+	}
+	func() {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		c.snapshot = snapshot
+	}()
+
+	for _, publisher := range c.publishers {
+		if err := publisher.Publish(ctx, snapshot); err != nil {
+			c.Logger.Errorf("Failed to publish scan results: %s\n", err.Error())
 		}
 	}
 
 	return nil
 }
 
+func (c *DockleCollector) publishFailure(ctx context.Context, scanErr error) {
+	for _, publisher := range c.publishers {
+		failurePublisher, ok := publisher.(IFailurePublisher)
+		if !ok {
+			continue
+		}
+		if err := failurePublisher.PublishFailure(ctx, scanErr); err != nil {
+			c.Logger.Errorf("Failed to publish scan failure: %s\n", err.Error())
+		}
+	}
+}
+
 func (c *DockleCollector) StartLoop(ctx context.Context, interval time.Duration) {
 	go func(ctx context.Context) {
 		t := time.NewTicker(interval)
@@ -135,6 +484,15 @@ func (c *DockleCollector) StartLoop(ctx context.Context, interval time.Duration)
 func (c *DockleCollector) collectors() []prometheus.Collector {
 	return []prometheus.Collector{
 		c.vulnerabilities,
+		c.suppressed,
+		c.expired,
+		c.policyViolations,
+		c.imageRisk,
+		c.workloadRisk,
+		c.namespaceRisk,
+		c.failingImages,
+		c.passingImages,
+		c.scannedImages,
 	}
 }
 
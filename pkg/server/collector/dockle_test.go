@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"kube-dockle-exporter/pkg/client"
 	"kube-dockle-exporter/pkg/server/collector"
 	"reflect"
 	"runtime"
@@ -59,18 +60,18 @@ func TestDockleCollectorDescribe(t *testing.T) {
 					wantFakeDebugfCalled: 0,
 				},
 				&kubernetesClientMock{
-					wantFakeContainersCalled: 0,
+					wantFakeWorkloadsCalled: 0,
 				},
 				&dockleClientMock{
 					wantFakeDoCalled: 0,
 				},
 				1,
 			),
-			make(chan *prometheus.Desc, 1),
+			make(chan *prometheus.Desc, 10),
 			prometheus.NewDesc(
 				"dockle_cis_benchmarks_total",
 				"CIS benchmarks executed by dockle",
-				[]string{"image", "code", "level"},
+				[]string{"image", "code", "level", "cluster", "original_level", "status"},
 				nil,
 			),
 			func(got interface{}) cmp.Option {
@@ -124,14 +125,20 @@ func TestDockleCollectorCollect(t *testing.T) {
 					wantFakeDebugfCalled: 0,
 				},
 				&kubernetesClientMock{
-					fakeContainers: func() ([]v1.Container, error) {
-						return []v1.Container{
+					fakeWorkloads: func() ([]client.Workload, error) {
+						return []client.Workload{
 							{
-								Image: "fake",
+								PodSpec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Image: "fake",
+										},
+									},
+								},
 							},
 						}, nil
 					},
-					wantFakeContainersCalled: 1,
+					wantFakeWorkloadsCalled: 1,
 				},
 				&dockleClientMock{
 					fakeDo: func(ctx context.Context, image string) ([]byte, error) {
@@ -141,17 +148,20 @@ func TestDockleCollectorCollect(t *testing.T) {
 				},
 				1,
 			),
-			make(chan prometheus.Metric, 1),
+			make(chan prometheus.Metric, 32),
 			func() prometheus.Gauge {
 				gaugeVec := prometheus.NewGaugeVec(prometheus.GaugeOpts{
 					Namespace: "dockle",
 					Name:      "cis_benchmarks_total",
 					Help:      "CIS benchmarks executed by dockle",
-				}, []string{"image", "code", "level"})
+				}, []string{"image", "code", "level", "cluster", "original_level", "status"})
 				labels := []string{
 					"fake",
 					"fake",
 					"",
+					"",
+					"",
+					"effective",
 				}
 				gaugeVec.WithLabelValues(labels...).Set(1)
 				gauge, err := gaugeVec.GetMetricWithLabelValues(labels...)
@@ -195,7 +205,7 @@ func TestDockleCollectorCollect(t *testing.T) {
 			collector.NewDockleCollector(
 				&loggerMock{
 					fakeErrorf: func(format string, v ...interface{}) {
-						want := "Failed to scan: failed to get containers: fake\n"
+						want := "Failed to scan: failed to get workloads: fake\n"
 						got := fmt.Sprintf(format, v...)
 						if diff := cmp.Diff(want, got); diff != "" {
 							t.Errorf("(-want +got):\n%s", diff)
@@ -206,10 +216,10 @@ func TestDockleCollectorCollect(t *testing.T) {
 					wantFakeDebugfCalled: 0,
 				},
 				&kubernetesClientMock{
-					fakeContainers: func() ([]v1.Container, error) {
+					fakeWorkloads: func() ([]client.Workload, error) {
 						return nil, errors.New("fake")
 					},
-					wantFakeContainersCalled: 1,
+					wantFakeWorkloadsCalled: 1,
 				},
 				&dockleClientMock{
 					wantFakeDoCalled: 0,
@@ -275,10 +285,10 @@ func TestDockleCollectorScan(t *testing.T) {
 					wantFakeDebugfCalled: 0,
 				},
 				&kubernetesClientMock{
-					fakeContainers: func() ([]v1.Container, error) {
+					fakeWorkloads: func() ([]client.Workload, error) {
 						return nil, errors.New("fake")
 					},
-					wantFakeContainersCalled: 1,
+					wantFakeWorkloadsCalled: 1,
 				},
 				&dockleClientMock{
 					wantFakeDoCalled: 0,
@@ -288,7 +298,7 @@ func TestDockleCollectorScan(t *testing.T) {
 			in{
 				context.Background(),
 			},
-			"failed to get containers: fake",
+			"failed to get workloads: fake",
 		},
 		{
 			func() string {
@@ -309,14 +319,20 @@ func TestDockleCollectorScan(t *testing.T) {
 					wantFakeDebugfCalled: 0,
 				},
 				&kubernetesClientMock{
-					fakeContainers: func() ([]v1.Container, error) {
-						return []v1.Container{
+					fakeWorkloads: func() ([]client.Workload, error) {
+						return []client.Workload{
 							{
-								Image: "fake",
+								PodSpec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Image: "fake",
+										},
+									},
+								},
 							},
 						}, nil
 					},
-					wantFakeContainersCalled: 1,
+					wantFakeWorkloadsCalled: 1,
 				},
 				&dockleClientMock{
 					fakeDo: func(ctx context.Context, image string) ([]byte, error) {
@@ -350,14 +366,20 @@ func TestDockleCollectorScan(t *testing.T) {
 					wantFakeDebugfCalled: 0,
 				},
 				&kubernetesClientMock{
-					fakeContainers: func() ([]v1.Container, error) {
-						return []v1.Container{
+					fakeWorkloads: func() ([]client.Workload, error) {
+						return []client.Workload{
 							{
-								Image: "fake",
+								PodSpec: v1.PodSpec{
+									Containers: []v1.Container{
+										{
+											Image: "fake",
+										},
+									},
+								},
 							},
 						}, nil
 					},
-					wantFakeContainersCalled: 1,
+					wantFakeWorkloadsCalled: 1,
 				},
 				&dockleClientMock{
 					fakeDo: func(ctx context.Context, image string) ([]byte, error) {
@@ -399,3 +421,150 @@ func TestDockleCollectorScan(t *testing.T) {
 		})
 	}
 }
+
+func TestDockleCollectorReset(t *testing.T) {
+	c := collector.NewDockleCollector(
+		&loggerMock{},
+		&kubernetesClientMock{
+			fakeWorkloads: func() ([]client.Workload, error) {
+				return []client.Workload{
+					{
+						PodSpec: v1.PodSpec{
+							Containers: []v1.Container{{Image: "fake"}},
+						},
+					},
+				}, nil
+			},
+			wantFakeWorkloadsCalled: 1,
+		},
+		&dockleClientMock{
+			fakeDo: func(ctx context.Context, image string) ([]byte, error) {
+				return []byte(`{"Target":"fake","Details":[{"code":"fake","level":"WARN"}]}`), nil
+			},
+			wantFakeDoCalled: 1,
+		},
+		1,
+	)
+	if err := c.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	c.Reset()
+
+	if c.Snapshot() != nil {
+		t.Errorf("snapshot is not reset")
+	}
+	ch := make(chan prometheus.Metric, 32)
+	c.Collect(ch)
+	close(ch)
+	if diff := cmp.Diff(0, len(ch)); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestDockleCollectorScanCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := collector.NewDockleCollector(
+		&loggerMock{
+			fakeErrorf:           func(format string, v ...interface{}) {},
+			wantFakeErrorfCalled: 1,
+		},
+		&kubernetesClientMock{
+			fakeWorkloads: func() ([]client.Workload, error) {
+				return []client.Workload{
+					{
+						PodSpec: v1.PodSpec{
+							Containers: []v1.Container{{Image: "fake"}},
+						},
+					},
+				}, nil
+			},
+			wantFakeWorkloadsCalled: 1,
+		},
+		&dockleClientMock{
+			// The lease is lost while scanning.
+			fakeDo: func(ctx context.Context, image string) ([]byte, error) {
+				cancel()
+				return nil, ctx.Err()
+			},
+			wantFakeDoCalled: 1,
+		},
+		1,
+	)
+	publisher := &publisherMock{}
+	c.AddPublisher(publisher)
+
+	if err := c.Scan(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("want context canceled, but got %v", err)
+	}
+	// Scans after the lease is lost do nothing.
+	if err := c.Scan(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("want context canceled, but got %v", err)
+	}
+	c.Logger.(*loggerMock).assert(t)
+	c.KubernetesClient.(*kubernetesClientMock).assert(t)
+	c.DockleClient.(*dockleClientMock).assert(t)
+	publisher.assert(t)
+	if c.Snapshot() != nil {
+		t.Errorf("snapshot is committed")
+	}
+}
+
+func TestDockleCollectorResetWaitsForScan(t *testing.T) {
+	scanning := make(chan struct{})
+	resume := make(chan struct{})
+	c := collector.NewDockleCollector(
+		&loggerMock{},
+		&kubernetesClientMock{
+			fakeWorkloads: func() ([]client.Workload, error) {
+				return []client.Workload{
+					{
+						PodSpec: v1.PodSpec{
+							Containers: []v1.Container{{Image: "fake"}},
+						},
+					},
+				}, nil
+			},
+			wantFakeWorkloadsCalled: 1,
+		},
+		&dockleClientMock{
+			fakeDo: func(ctx context.Context, image string) ([]byte, error) {
+				close(scanning)
+				<-resume
+				return []byte(`{"Target":"fake","Details":[{"code":"fake","level":"WARN"}]}`), nil
+			},
+			wantFakeDoCalled: 1,
+		},
+		1,
+	)
+	publisher := &publisherMock{wantFakePublishCalled: 1}
+	c.AddPublisher(publisher)
+
+	scanned := make(chan error)
+	go func() {
+		scanned <- c.Scan(context.Background())
+	}()
+	<-scanning
+	reset := make(chan struct{})
+	go func() {
+		c.Reset()
+		close(reset)
+	}()
+	select {
+	case <-reset:
+		t.Fatal("reset does not wait for the scan in flight")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(resume)
+	if err := <-scanned; err != nil {
+		t.Fatal(err)
+	}
+	<-reset
+
+	c.KubernetesClient.(*kubernetesClientMock).assert(t)
+	c.DockleClient.(*dockleClientMock).assert(t)
+	publisher.assert(t)
+	if c.Snapshot() != nil {
+		t.Errorf("snapshot is not reset")
+	}
+}
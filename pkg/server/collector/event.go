@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	eventReason = "DockleFinding"
+)
+
+type EventPublisher struct {
+	recorder IEventRecorder
+	level    string
+	previous []WorkloadFinding
+	scanned  bool
+}
+
+func NewEventPublisher(recorder IEventRecorder, level string) *EventPublisher {
+	return &EventPublisher{
+		recorder: recorder,
+		level:    level,
+	}
+}
+
+func (p *EventPublisher) Publish(ctx context.Context, snapshot *Snapshot) error {
+	var current []WorkloadFinding
+	for _, finding := range snapshot.WorkloadFindings() {
+		if finding.AtLeast(p.level) {
+			current = append(current, finding)
+		}
+	}
+	// Findings of images which failed to be scanned are kept, so that Events are not recorded again after them.
+	unscanned := snapshot.unscanned()
+	for _, finding := range p.previous {
+		if unscanned[finding.Workload.Cluster+" "+finding.Image] {
+			current = append(current, finding)
+		}
+	}
+
+	// The first scan has nothing to compare with, so it only becomes the baseline.
+	if p.scanned {
+		recorded := make(map[string]bool)
+		for _, finding := range DiffWorkloadFindings(p.previous, current).Introduced {
+			// Events are recorded in the cluster of the exporter, which cannot refer to workloads of the others.
+			if finding.Workload.Remote {
+				continue
+			}
+			key := finding.Workload.Kind + "/" + finding.Workload.Namespace + "/" + finding.Workload.Name + " " + finding.Code
+			if recorded[key] {
+				continue
+			}
+			recorded[key] = true
+
+			reference := finding.Workload.ObjectReference()
+			p.recorder.Event(&reference, v1.EventTypeWarning, eventReason, eventMessage(&finding.Finding))
+		}
+	}
+	p.previous = current
+	p.scanned = true
+	return nil
+}
+
+func eventMessage(finding *Finding) string {
+	message := fmt.Sprintf("%s %s found in %s: %s", finding.Level, finding.Code, finding.Image, finding.Title)
+	if len(finding.Alerts) > 0 {
+		message += " (" + strings.Join(finding.Alerts, ", ") + ")"
+	}
+	return message
+}
@@ -0,0 +1,133 @@
+package collector_test
+
+import (
+	"context"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestEventPublisherPublish(t *testing.T) {
+	withFinding := func(level string) *collector.Snapshot {
+		snapshot := fakeSnapshot()
+		response := snapshot.Responses["fake"]
+		response.Details = append(response.Details, client.DockleDetail{
+			Code:  "CIS-DI-0005",
+			Title: "Enable Content trust for Docker",
+			Level: level,
+		})
+		snapshot.Responses["fake"] = response
+		return snapshot
+	}
+	failed := func() *collector.Snapshot {
+		snapshot := fakeSnapshot()
+		snapshot.Responses = map[string]client.DockleResponse{}
+		snapshot.Failed = map[string]bool{"fake": true}
+		return snapshot
+	}
+
+	tests := []struct {
+		name     string
+		receiver *eventRecorderMock
+		level    string
+		in       []*collector.Snapshot
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&eventRecorderMock{
+				fakeEvent: func(object k8sRuntime.Object, eventType string, reason string, message string) {
+					reference, ok := object.(*v1.ObjectReference)
+					if !ok {
+						t.Fatalf("unexpected object %T", object)
+					}
+					if diff := cmp.Diff("fake/fake", reference.Namespace+"/"+reference.Name); diff != "" {
+						t.Errorf("(-want +got):\n%s", diff)
+					}
+					if diff := cmp.Diff(v1.EventTypeWarning, eventType); diff != "" {
+						t.Errorf("(-want +got):\n%s", diff)
+					}
+					if diff := cmp.Diff("WARN CIS-DI-0005 found in fake: Enable Content trust for Docker", message); diff != "" {
+						t.Errorf("(-want +got):\n%s", diff)
+					}
+				},
+				wantFakeEventCalled: 1,
+			},
+			"WARN",
+			[]*collector.Snapshot{
+				fakeSnapshot(),
+				withFinding("WARN"),
+				withFinding("WARN"),
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&eventRecorderMock{
+				wantFakeEventCalled: 0,
+			},
+			"FATAL",
+			[]*collector.Snapshot{
+				fakeSnapshot(),
+				withFinding("WARN"),
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&eventRecorderMock{
+				wantFakeEventCalled: 0,
+			},
+			"WARN",
+			[]*collector.Snapshot{
+				withFinding("WARN"),
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&eventRecorderMock{
+				fakeEvent:           func(k8sRuntime.Object, string, string, string) {},
+				wantFakeEventCalled: 1,
+			},
+			"WARN",
+			[]*collector.Snapshot{
+				fakeSnapshot(),
+				withFinding("WARN"),
+				failed(),
+				withFinding("WARN"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		receiver := tt.receiver
+		level := tt.level
+		in := tt.in
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			publisher := collector.NewEventPublisher(receiver, level)
+			for _, snapshot := range in {
+				if err := publisher.Publish(context.Background(), snapshot); err != nil {
+					t.Fatal(err)
+				}
+			}
+			receiver.assert(t)
+		})
+	}
+}
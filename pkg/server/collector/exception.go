@@ -0,0 +1,167 @@
+package collector
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// ExceptionConfig accepts findings whose image, namespace and code match the patterns, and whose alerts contain Alert,
+// until Expires, which is a date at the start of the day in UTC or RFC 3339 time.
+type ExceptionConfig struct {
+	Image     string `json:"image,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Code      string `json:"code"`
+	Alert     string `json:"alert,omitempty"`
+	Owner     string `json:"owner"`
+	Reason    string `json:"reason"`
+	Expires   string `json:"expires"`
+}
+
+type Exception struct {
+	ExceptionConfig
+	expires time.Time
+}
+
+func (e *Exception) ExpiresAt() time.Time {
+	return e.expires
+}
+
+func (e *Exception) covers(namespace string, image string, code string, alert string) bool {
+	for _, pair := range [][2]string{{e.Image, image}, {e.Namespace, namespace}, {e.Code, code}} {
+		if pair[0] == "" {
+			continue
+		}
+		if ok, _ := path.Match(pair[0], pair[1]); !ok {
+			return false
+		}
+	}
+	return strings.Contains(alert, e.Alert)
+}
+
+type SuppressedFinding struct {
+	Finding
+	Owner   string    `json:"owner"`
+	Reason  string    `json:"reason"`
+	Expires time.Time `json:"expires"`
+}
+
+type Exceptions struct {
+	exceptions []*Exception
+}
+
+func NewExceptions(configs []ExceptionConfig) (*Exceptions, error) {
+	exceptions := make([]*Exception, 0, len(configs))
+	for i, config := range configs {
+		if config.Code == "" {
+			return nil, xerrors.Errorf("exceptions[%d]: code is required", i)
+		}
+		if config.Owner == "" {
+			return nil, xerrors.Errorf("exceptions[%d]: owner is required", i)
+		}
+		if config.Reason == "" {
+			return nil, xerrors.Errorf("exceptions[%d]: reason is required", i)
+		}
+		for _, pattern := range []string{config.Image, config.Namespace, config.Code} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, xerrors.Errorf("exceptions[%d]: invalid pattern %s: %w", i, pattern, err)
+			}
+		}
+		expires, err := time.Parse("2006-01-02", config.Expires)
+		if err != nil {
+			expires, err = time.Parse(time.RFC3339, config.Expires)
+			if err != nil {
+				return nil, xerrors.Errorf("exceptions[%d]: expires must be a date or RFC 3339 time: %s", i, config.Expires)
+			}
+		}
+		exceptions = append(exceptions, &Exception{
+			ExceptionConfig: config,
+			expires:         expires,
+		})
+	}
+	return &Exceptions{
+		exceptions: exceptions,
+	}, nil
+}
+
+// Expired returns exceptions which expired at the time.
+func (e *Exceptions) Expired(now time.Time) []*Exception {
+	var expired []*Exception
+	for _, exception := range e.exceptions {
+		if !now.Before(exception.expires) {
+			expired = append(expired, exception)
+		}
+	}
+	return expired
+}
+
+// find returns an unexpired exception covering all alerts of the detail, or the detail itself if it has no alerts.
+func (e *Exceptions) find(now time.Time, namespace string, image string, code string, alerts []string) *Exception {
+	if len(alerts) == 0 {
+		alerts = []string{""}
+	}
+	var found *Exception
+	for _, alert := range alerts {
+		var covering *Exception
+		for _, exception := range e.exceptions {
+			if now.Before(exception.expires) && exception.covers(namespace, image, code, alert) {
+				covering = exception
+				break
+			}
+		}
+		if covering == nil {
+			return nil
+		}
+		if found == nil {
+			found = covering
+		}
+	}
+	return found
+}
+
+// Apply moves details of responses which exceptions cover on all workloads running the image into suppressed findings,
+// and removes them from summaries.
+func (e *Exceptions) Apply(snapshot *Snapshot) {
+	for image, response := range snapshot.Responses {
+		workloads := snapshot.WorkloadsOf(image)
+		if len(workloads) == 0 {
+			continue
+		}
+		details := response.Details[:0:0]
+		for _, detail := range response.Details {
+			var exception *Exception
+			for _, workload := range workloads {
+				exception = e.find(snapshot.ScannedAt, workload.Namespace, image, detail.Code, detail.Alerts)
+				if exception == nil {
+					break
+				}
+			}
+			if exception == nil {
+				details = append(details, detail)
+				continue
+			}
+			uncount(&response.Summary, detail.Level)
+			snapshot.Suppressed = append(snapshot.Suppressed, SuppressedFinding{
+				Finding: Finding{
+					Image:         response.ExtractImage(),
+					Code:          detail.Code,
+					Title:         detail.Title,
+					Level:         detail.Level,
+					Alerts:        detail.Alerts,
+					OriginalLevel: detail.OriginalLevel,
+				},
+				Owner:   exception.Owner,
+				Reason:  exception.Reason,
+				Expires: exception.expires,
+			})
+		}
+		response.Details = details
+		snapshot.Responses[image] = response
+	}
+	sort.Slice(snapshot.Suppressed, func(i, j int) bool {
+		return snapshot.Suppressed[i].Key() < snapshot.Suppressed[j].Key()
+	})
+}
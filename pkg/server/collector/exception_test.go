@@ -0,0 +1,215 @@
+package collector_test
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+)
+
+func fakeExceptionSnapshot() *collector.Snapshot {
+	workload := func(namespace string, image string) client.Workload {
+		return client.Workload{
+			Kind:      "Deployment",
+			Namespace: namespace,
+			Name:      "fake",
+			PodSpec: v1.PodSpec{
+				Containers: []v1.Container{{Image: image}},
+			},
+		}
+	}
+	return &collector.Snapshot{
+		Workloads: []client.Workload{
+			workload("legacy", "legacy:1"),
+			workload("legacy", "shared:1"),
+			workload("default", "shared:1"),
+		},
+		Responses: map[string]client.DockleResponse{
+			"legacy:1": {
+				Target:  "legacy:1",
+				Summary: client.DockleSummary{Info: 1, Fatal: 1},
+				Details: []client.DockleDetail{
+					{Code: "CIS-DI-0005", Level: "INFO", Alerts: []string{"export DOCKER_CONTENT_TRUST=1"}},
+					{Code: "CIS-DI-0010", Level: "FATAL", Alerts: []string{"Suspicious ENV key found : PASSWORD", "Suspicious filename found : id_rsa"}},
+				},
+			},
+			"shared:1": {
+				Target:  "shared:1",
+				Summary: client.DockleSummary{Info: 1},
+				Details: []client.DockleDetail{
+					{Code: "CIS-DI-0005", Level: "INFO", Alerts: []string{"export DOCKER_CONTENT_TRUST=1"}},
+				},
+			},
+		},
+		ScannedAt: time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestExceptionsApply(t *testing.T) {
+	type want struct {
+		suppressed []string
+		active     []string
+	}
+
+	tests := []struct {
+		name    string
+		configs []collector.ExceptionConfig
+		want    want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]collector.ExceptionConfig{
+				{Namespace: "legacy", Code: "CIS-DI-0005", Owner: "security", Reason: "legacy", Expires: "2020-10-01"},
+			},
+			want{
+				// shared:1 also runs in the default namespace, which the exception does not cover.
+				[]string{"legacy:1 CIS-DI-0005 security"},
+				[]string{"legacy:1 CIS-DI-0010", "shared:1 CIS-DI-0005"},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]collector.ExceptionConfig{
+				{Code: "CIS-DI-0005", Owner: "security", Reason: "legacy", Expires: "2020-09-01"},
+			},
+			want{
+				nil,
+				[]string{"legacy:1 CIS-DI-0005", "legacy:1 CIS-DI-0010", "shared:1 CIS-DI-0005"},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]collector.ExceptionConfig{
+				{Image: "legacy:*", Code: "CIS-DI-0010", Alert: "PASSWORD", Owner: "security", Reason: "dummy", Expires: "2020-10-01"},
+			},
+			want{
+				nil,
+				[]string{"legacy:1 CIS-DI-0005", "legacy:1 CIS-DI-0010", "shared:1 CIS-DI-0005"},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]collector.ExceptionConfig{
+				{Image: "legacy:*", Code: "CIS-DI-0010", Alert: "PASSWORD", Owner: "security", Reason: "dummy", Expires: "2020-10-01"},
+				{Image: "legacy:*", Code: "CIS-DI-0010", Alert: "id_rsa", Owner: "platform", Reason: "test key", Expires: "2020-09-01T12:00:00Z"},
+			},
+			want{
+				[]string{"legacy:1 CIS-DI-0010 security"},
+				[]string{"legacy:1 CIS-DI-0005", "shared:1 CIS-DI-0005"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		configs := tt.configs
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			exceptions, err := collector.NewExceptions(configs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			snapshot := fakeExceptionSnapshot()
+			exceptions.Apply(snapshot)
+
+			var suppressed []string
+			for _, finding := range snapshot.Suppressed {
+				suppressed = append(suppressed, finding.Key()+" "+finding.Owner)
+			}
+			if diff := cmp.Diff(want.suppressed, suppressed); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			var active []string
+			for _, finding := range snapshot.Findings() {
+				active = append(active, finding.Key())
+			}
+			if diff := cmp.Diff(want.active, active); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewExceptions(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          collector.ExceptionConfig
+		wantErrorString string
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.ExceptionConfig{Code: "CIS-DI-0005", Reason: "legacy", Expires: "2020-10-01"},
+			"exceptions[0]: owner is required",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.ExceptionConfig{Code: "CIS-DI-0005", Owner: "security", Reason: "legacy", Expires: "Q3"},
+			"exceptions[0]: expires must be a date or RFC 3339 time: Q3",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.ExceptionConfig{Image: "[", Code: "CIS-DI-0005", Owner: "security", Reason: "legacy", Expires: "2020-10-01"},
+			"exceptions[0]: invalid pattern [: syntax error in pattern",
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		config := tt.config
+		wantErrorString := tt.wantErrorString
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			gotErrorString := ""
+			if _, err := collector.NewExceptions([]collector.ExceptionConfig{config}); err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(wantErrorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExceptionsExpired(t *testing.T) {
+	exceptions, err := collector.NewExceptions([]collector.ExceptionConfig{
+		{Code: "CIS-DI-0005", Owner: "security", Reason: "legacy", Expires: "2020-10-01"},
+		{Code: "CIS-DI-0006", Owner: "platform", Reason: "legacy", Expires: "2020-09-01"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, exception := range exceptions.Expired(time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC)) {
+		got = append(got, exception.Owner)
+	}
+	if diff := cmp.Diff([]string{"platform"}, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,194 @@
+package collector
+
+import (
+	"kube-dockle-exporter/pkg/client"
+	"sort"
+)
+
+type Finding struct {
+	Image  string   `json:"image"`
+	Code   string   `json:"code"`
+	Title  string   `json:"title"`
+	Level  string   `json:"level"`
+	Alerts []string `json:"alerts,omitempty"`
+	// OriginalLevel is the level given by dockle if Level is remapped.
+	OriginalLevel string `json:"originalLevel,omitempty"`
+}
+
+func (f *Finding) Key() string {
+	return f.Image + " " + f.Code
+}
+
+func (f *Finding) AtLeast(level string) bool {
+	return client.LevelSeverity(f.Level) >= client.LevelSeverity(level)
+}
+
+func findingsOf(response client.DockleResponse) []Finding {
+	findings := make([]Finding, 0, len(response.Details))
+	for _, detail := range response.Details {
+		findings = append(findings, Finding{
+			Image:         response.ExtractImage(),
+			Code:          detail.Code,
+			Title:         detail.Title,
+			Level:         detail.Level,
+			Alerts:        detail.Alerts,
+			OriginalLevel: detail.OriginalLevel,
+		})
+	}
+	return findings
+}
+
+func FindingsOf(responses []client.DockleResponse) []Finding {
+	var findings []Finding
+	for _, response := range responses {
+		findings = append(findings, findingsOf(response)...)
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		return findings[i].Key() < findings[j].Key()
+	})
+	return findings
+}
+
+func (s *Snapshot) Findings() []Finding {
+	return FindingsOf(s.SortedResponses())
+}
+
+type FindingDiff struct {
+	Introduced []Finding `json:"introduced"`
+	Resolved   []Finding `json:"resolved"`
+	Unchanged  []Finding `json:"unchanged"`
+}
+
+// diffKeys compares items by their keys, and returns indices of current items which are introduced or unchanged, and
+// of previous items which are resolved.
+func diffKeys(previous []string, current []string) (introduced []int, resolved []int, unchanged []int) {
+	previousKeys := make(map[string]bool, len(previous))
+	for _, key := range previous {
+		previousKeys[key] = true
+	}
+	currentKeys := make(map[string]bool, len(current))
+	for _, key := range current {
+		currentKeys[key] = true
+	}
+	for i, key := range current {
+		if previousKeys[key] {
+			unchanged = append(unchanged, i)
+		} else {
+			introduced = append(introduced, i)
+		}
+	}
+	for i, key := range previous {
+		if !currentKeys[key] {
+			resolved = append(resolved, i)
+		}
+	}
+	return introduced, resolved, unchanged
+}
+
+func DiffFindings(previous []Finding, current []Finding) *FindingDiff {
+	keysOf := func(findings []Finding) []string {
+		keys := make([]string, 0, len(findings))
+		for _, finding := range findings {
+			keys = append(keys, finding.Key())
+		}
+		return keys
+	}
+	introduced, resolved, unchanged := diffKeys(keysOf(previous), keysOf(current))
+
+	diff := &FindingDiff{}
+	for _, i := range introduced {
+		diff.Introduced = append(diff.Introduced, current[i])
+	}
+	for _, i := range resolved {
+		diff.Resolved = append(diff.Resolved, previous[i])
+	}
+	for _, i := range unchanged {
+		diff.Unchanged = append(diff.Unchanged, current[i])
+	}
+	return diff
+}
+
+// WorkloadRef identifies a workload in JSON documents.
+type WorkloadRef struct {
+	Cluster   string `json:"cluster,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Owner is the owner of the namespace, which identifies no workload.
+	Owner map[string]string `json:"owner,omitempty"`
+}
+
+func (r *WorkloadRef) key() string {
+	return r.Cluster + ":" + r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+func refOf(workload *client.Workload) WorkloadRef {
+	return WorkloadRef{
+		Cluster:   workload.Cluster,
+		Kind:      workload.Kind,
+		Namespace: workload.Namespace,
+		Name:      workload.Name,
+		Owner:     workload.Owner,
+	}
+}
+
+type WorkloadFinding struct {
+	Workload client.Workload
+	Finding
+}
+
+func (f *WorkloadFinding) Key() string {
+	key := f.Workload.Kind + "/" + f.Workload.Namespace + "/" + f.Workload.Name + " " + f.Finding.Key()
+	if f.Workload.Cluster != "" {
+		key = f.Workload.Cluster + ":" + key
+	}
+	return key
+}
+
+func (s *Snapshot) WorkloadFindings() []WorkloadFinding {
+	var findings []WorkloadFinding
+	for _, workload := range s.Workloads {
+		for _, image := range workload.Images() {
+			response, ok := s.Responses[image]
+			if !ok {
+				continue
+			}
+			for _, finding := range findingsOf(response) {
+				findings = append(findings, WorkloadFinding{
+					Workload: workload,
+					Finding:  finding,
+				})
+			}
+		}
+	}
+	return findings
+}
+
+type WorkloadFindingDiff struct {
+	Introduced []WorkloadFinding
+	Resolved   []WorkloadFinding
+	Unchanged  []WorkloadFinding
+}
+
+func DiffWorkloadFindings(previous []WorkloadFinding, current []WorkloadFinding) *WorkloadFindingDiff {
+	keysOf := func(findings []WorkloadFinding) []string {
+		keys := make([]string, 0, len(findings))
+		for _, finding := range findings {
+			keys = append(keys, finding.Key())
+		}
+		return keys
+	}
+	introduced, resolved, unchanged := diffKeys(keysOf(previous), keysOf(current))
+
+	diff := &WorkloadFindingDiff{}
+	for _, i := range introduced {
+		diff.Introduced = append(diff.Introduced, current[i])
+	}
+	for _, i := range resolved {
+		diff.Resolved = append(diff.Resolved, previous[i])
+	}
+	for _, i := range unchanged {
+		diff.Unchanged = append(diff.Unchanged, current[i])
+	}
+	return diff
+}
@@ -0,0 +1,75 @@
+package collector_test
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffFindings(t *testing.T) {
+	type in struct {
+		first  []collector.Finding
+		second []collector.Finding
+	}
+
+	tests := []struct {
+		name string
+		in   in
+		want *collector.FindingDiff
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				[]collector.Finding{
+					{Image: "fake", Code: "resolved"},
+					{Image: "fake", Code: "unchanged"},
+				},
+				[]collector.Finding{
+					{Image: "fake", Code: "unchanged"},
+					{Image: "fake", Code: "introduced"},
+				},
+			},
+			&collector.FindingDiff{
+				Introduced: []collector.Finding{
+					{Image: "fake", Code: "introduced"},
+				},
+				Resolved: []collector.Finding{
+					{Image: "fake", Code: "resolved"},
+				},
+				Unchanged: []collector.Finding{
+					{Image: "fake", Code: "unchanged"},
+				},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				nil,
+				nil,
+			},
+			&collector.FindingDiff{},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := collector.DiffFindings(in.first, in.second)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package collector
+
+import (
+	"context"
+	"kube-dockle-exporter/pkg/client"
+	"sort"
+
+	"golang.org/x/xerrors"
+)
+
+type HistoryPublisher struct {
+	store IHistoryStore
+}
+
+func NewHistoryPublisher(store IHistoryStore) *HistoryPublisher {
+	return &HistoryPublisher{
+		store: store,
+	}
+}
+
+func (p *HistoryPublisher) Publish(ctx context.Context, snapshot *Snapshot) error {
+	failed := make([]string, 0, len(snapshot.Failed))
+	for image := range snapshot.Failed {
+		failed = append(failed, image)
+	}
+	sort.Strings(failed)
+	if err := p.store.Put(&client.HistoryEntry{
+		ScannedAt: snapshot.ScannedAt,
+		Responses: snapshot.SortedResponses(),
+		Clusters:  snapshot.ImageClusters(),
+		Failed:    failed,
+	}); err != nil {
+		return xerrors.Errorf("failed to store history: %w", err)
+	}
+	if err := p.store.Prune(snapshot.ScannedAt); err != nil {
+		return xerrors.Errorf("failed to prune history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"kube-dockle-exporter/pkg/client"
+	"path"
+
+	"golang.org/x/xerrors"
+)
+
+// Ignore drops images and checks which are accepted, given as glob patterns.
+type Ignore struct {
+	Codes  []string
+	Images []string
+}
+
+func NewIgnore(codes []string, images []string) (*Ignore, error) {
+	for _, pattern := range append(append([]string{}, codes...), images...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, xerrors.Errorf("invalid pattern %s: %w", pattern, err)
+		}
+	}
+	return &Ignore{
+		Codes:  codes,
+		Images: images,
+	}, nil
+}
+
+func matchSome(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *Ignore) IgnoresImage(image string) bool {
+	return matchSome(i.Images, image)
+}
+
+func (i *Ignore) IgnoresCode(code string) bool {
+	return matchSome(i.Codes, code)
+}
+
+// Apply drops details of ignored codes from the response, and their counts from its summary.
+func (i *Ignore) Apply(response client.DockleResponse) client.DockleResponse {
+	if len(i.Codes) == 0 {
+		return response
+	}
+	details := make([]client.DockleDetail, 0, len(response.Details))
+	for _, detail := range response.Details {
+		if !matchSome(i.Codes, detail.Code) {
+			details = append(details, detail)
+			continue
+		}
+		uncount(&response.Summary, detail.Level)
+	}
+	response.Details = details
+	return response
+}
+
+// uncount removes a detail of the level from the summary.
+func uncount(summary *client.DockleSummary, level string) {
+	count(summary, level, -1)
+}
+
+// count adds n details of the level to the summary.
+func count(summary *client.DockleSummary, level string, n int) {
+	switch level {
+	case client.LevelFatal:
+		summary.Fatal += n
+	case client.LevelWarn:
+		summary.Warn += n
+	case client.LevelInfo:
+		summary.Info += n
+	case client.LevelSkip:
+		summary.Skip += n
+	case client.LevelPass:
+		summary.Pass += n
+	}
+}
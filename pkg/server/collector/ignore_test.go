@@ -0,0 +1,112 @@
+package collector_test
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewIgnore(t *testing.T) {
+	_, err := collector.NewIgnore([]string{"["}, nil)
+	if diff := cmp.Diff("invalid pattern [: syntax error in pattern", err.Error()); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestIgnoreApply(t *testing.T) {
+	type in struct {
+		first client.DockleResponse
+	}
+
+	tests := []struct {
+		name     string
+		receiver *collector.Ignore
+		in       in
+		want     client.DockleResponse
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&collector.Ignore{
+				Codes: []string{"CIS-DI-0001", "DKL-*"},
+			},
+			in{
+				client.DockleResponse{
+					Target:  "fake",
+					Summary: client.DockleSummary{Fatal: 1, Warn: 1, Info: 1},
+					Details: []client.DockleDetail{
+						{Code: "CIS-DI-0001", Level: "WARN"},
+						{Code: "CIS-DI-0010", Level: "FATAL"},
+						{Code: "DKL-LI-0003", Level: "INFO"},
+					},
+				},
+			},
+			client.DockleResponse{
+				Target:  "fake",
+				Summary: client.DockleSummary{Fatal: 1},
+				Details: []client.DockleDetail{
+					{Code: "CIS-DI-0010", Level: "FATAL"},
+				},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&collector.Ignore{},
+			in{
+				client.DockleResponse{
+					Target:  "fake",
+					Summary: client.DockleSummary{Warn: 1},
+					Details: []client.DockleDetail{
+						{Code: "CIS-DI-0001", Level: "WARN"},
+					},
+				},
+			},
+			client.DockleResponse{
+				Target:  "fake",
+				Summary: client.DockleSummary{Warn: 1},
+				Details: []client.DockleDetail{
+					{Code: "CIS-DI-0001", Level: "WARN"},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		receiver := tt.receiver
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := receiver.Apply(in.first)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestIgnoreIgnoresImage(t *testing.T) {
+	receiver := &collector.Ignore{
+		Images: []string{"docker.io/library/*", "vendor/*:1.*"},
+	}
+	for image, want := range map[string]bool{
+		"docker.io/library/alpine:3": true,
+		"vendor/agent:1.2":           true,
+		"vendor/agent:2.0":           false,
+		"alpine:3":                   false,
+	} {
+		if diff := cmp.Diff(want, receiver.IgnoresImage(image)); diff != "" {
+			t.Errorf("%s (-want +got):\n%s", image, diff)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package collector
 
 import (
 	"context"
+	"kube-dockle-exporter/pkg/client"
+	"time"
 
-	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type ILogger interface {
@@ -13,9 +15,87 @@ type ILogger interface {
 }
 
 type IKubernetesClient interface {
-	Containers() ([]v1.Container, error)
+	Workloads() ([]client.Workload, error)
 }
 
 type IDockleClient interface {
 	Do(context.Context, string) ([]byte, error)
 }
+
+type IPublisher interface {
+	Publish(context.Context, *Snapshot) error
+}
+
+type IPolicyReportClient interface {
+	Apply(context.Context, *client.PolicyReport) error
+	List(context.Context) ([]client.PolicyReport, error)
+	Delete(context.Context, *client.PolicyReport) error
+}
+
+type IEventRecorder interface {
+	Event(object runtime.Object, eventType string, reason string, message string)
+}
+
+type IHistoryStore interface {
+	Put(*client.HistoryEntry) error
+	Prune(time.Time) error
+}
+
+type IHistoryReader interface {
+	At(time.Time) (*client.HistoryEntry, error)
+}
+
+type IFindingStore interface {
+	FindingStates() (map[string]client.FindingState, error)
+	SetFindingStates(map[string]client.FindingState) error
+}
+
+type INotifier interface {
+	Notify(context.Context, []WorkloadFinding) error
+}
+
+type ISlackClient interface {
+	Post(context.Context, *client.SlackMessage) error
+}
+
+// IFailurePublisher is optionally implemented by publishers which are also interested in failed scans.
+type IFailurePublisher interface {
+	PublishFailure(context.Context, error) error
+}
+
+// IResetter is optionally implemented by publishers whose results go stale once the lease is lost.
+type IResetter interface {
+	Reset()
+}
+
+type IWebhookClient interface {
+	Send(context.Context, *client.CloudEvent) error
+}
+
+type IOutbox interface {
+	Enqueue([]client.CloudEvent) error
+	Pending() ([]client.OutboxEntry, error)
+	Remove(uint64) error
+}
+
+type IAlertmanagerClient interface {
+	PostAlerts(context.Context, []client.Alert) error
+}
+
+type INamespaceClient interface {
+	Namespaces() ([]client.Namespace, error)
+}
+
+type INotificationStore interface {
+	NotificationTimes() (map[string]time.Time, error)
+	SetNotificationTimes(map[string]time.Time) error
+}
+
+// IImageFilter selects images to scan among those of workloads.
+type IImageFilter interface {
+	Filter([]string) ([]string, error)
+}
+
+type IStatefulSetClient interface {
+	StatefulSetReplicas(namespace string, name string) (int, error)
+}
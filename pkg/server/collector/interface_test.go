@@ -2,11 +2,13 @@ package collector_test
 
 import (
 	"context"
+	"kube-dockle-exporter/pkg/client"
 	"kube-dockle-exporter/pkg/server/collector"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
-	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type loggerMock struct {
@@ -51,20 +53,20 @@ func (m *loggerMock) Debugf(format string, v ...interface{}) {
 
 type kubernetesClientMock struct {
 	collector.IKubernetesClient
-	fakeContainers           func() ([]v1.Container, error)
-	wantFakeContainersCalled int
-	fakeContainersCalled     int
+	fakeWorkloads           func() ([]client.Workload, error)
+	wantFakeWorkloadsCalled int
+	fakeWorkloadsCalled     int
 }
 
 func (m *kubernetesClientMock) assert(t *testing.T) {
-	if diff := cmp.Diff(m.wantFakeContainersCalled, m.fakeContainersCalled); diff != "" {
+	if diff := cmp.Diff(m.wantFakeWorkloadsCalled, m.fakeWorkloadsCalled); diff != "" {
 		t.Errorf("(-want +got):\n%s", diff)
 	}
 }
 
-func (m *kubernetesClientMock) Containers() ([]v1.Container, error) {
-	m.fakeContainersCalled++
-	return m.fakeContainers()
+func (m *kubernetesClientMock) Workloads() ([]client.Workload, error) {
+	m.fakeWorkloadsCalled++
+	return m.fakeWorkloads()
 }
 
 type dockleClientMock struct {
@@ -84,3 +86,236 @@ func (m *dockleClientMock) Do(ctx context.Context, image string) ([]byte, error)
 	m.fakeDoCalled++
 	return m.fakeDo(ctx, image)
 }
+
+type publisherMock struct {
+	collector.IPublisher
+	wantFakePublishCalled int
+	fakePublishCalled     int
+}
+
+func (m *publisherMock) assert(t *testing.T) {
+	if diff := cmp.Diff(m.wantFakePublishCalled, m.fakePublishCalled); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func (m *publisherMock) Publish(ctx context.Context, snapshot *collector.Snapshot) error {
+	m.fakePublishCalled++
+	return nil
+}
+
+type policyReportClientMock struct {
+	collector.IPolicyReportClient
+	fakeApply            func(context.Context, *client.PolicyReport) error
+	wantFakeApplyCalled  int
+	fakeApplyCalled      int
+	fakeList             func(context.Context) ([]client.PolicyReport, error)
+	wantFakeListCalled   int
+	fakeListCalled       int
+	fakeDelete           func(context.Context, *client.PolicyReport) error
+	wantFakeDeleteCalled int
+	fakeDeleteCalled     int
+}
+
+func (m *policyReportClientMock) assert(t *testing.T) {
+	if diff := cmp.Diff(m.wantFakeApplyCalled, m.fakeApplyCalled); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(m.wantFakeListCalled, m.fakeListCalled); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(m.wantFakeDeleteCalled, m.fakeDeleteCalled); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func (m *policyReportClientMock) Apply(ctx context.Context, report *client.PolicyReport) error {
+	m.fakeApplyCalled++
+	return m.fakeApply(ctx, report)
+}
+
+func (m *policyReportClientMock) List(ctx context.Context) ([]client.PolicyReport, error) {
+	m.fakeListCalled++
+	return m.fakeList(ctx)
+}
+
+func (m *policyReportClientMock) Delete(ctx context.Context, report *client.PolicyReport) error {
+	m.fakeDeleteCalled++
+	return m.fakeDelete(ctx, report)
+}
+
+type eventRecorderMock struct {
+	collector.IEventRecorder
+	fakeEvent           func(object runtime.Object, eventType string, reason string, message string)
+	wantFakeEventCalled int
+	fakeEventCalled     int
+}
+
+func (m *eventRecorderMock) assert(t *testing.T) {
+	if diff := cmp.Diff(m.wantFakeEventCalled, m.fakeEventCalled); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func (m *eventRecorderMock) Event(object runtime.Object, eventType string, reason string, message string) {
+	m.fakeEventCalled++
+	m.fakeEvent(object, eventType, reason, message)
+}
+
+type findingStoreMock struct {
+	collector.IFindingStore
+	states map[string]client.FindingState
+}
+
+func (m *findingStoreMock) FindingStates() (map[string]client.FindingState, error) {
+	states := make(map[string]client.FindingState, len(m.states))
+	for key, state := range m.states {
+		states[key] = state
+	}
+	return states, nil
+}
+
+func (m *findingStoreMock) SetFindingStates(states map[string]client.FindingState) error {
+	m.states = states
+	return nil
+}
+
+type slackClientMock struct {
+	collector.ISlackClient
+	fakePost func(context.Context, *client.SlackMessage) error
+	messages []client.SlackMessage
+}
+
+func (m *slackClientMock) Post(ctx context.Context, message *client.SlackMessage) error {
+	if m.fakePost != nil {
+		if err := m.fakePost(ctx, message); err != nil {
+			return err
+		}
+	}
+	m.messages = append(m.messages, *message)
+	return nil
+}
+
+type webhookClientMock struct {
+	collector.IWebhookClient
+	fakeSend func(context.Context, *client.CloudEvent) error
+	events   []client.CloudEvent
+}
+
+func (m *webhookClientMock) Send(ctx context.Context, event *client.CloudEvent) error {
+	if m.fakeSend != nil {
+		if err := m.fakeSend(ctx, event); err != nil {
+			return err
+		}
+	}
+	m.events = append(m.events, *event)
+	return nil
+}
+
+type outboxMock struct {
+	collector.IOutbox
+	entries  []client.OutboxEntry
+	sequence uint64
+}
+
+func (m *outboxMock) Enqueue(events []client.CloudEvent) error {
+	for _, event := range events {
+		m.sequence++
+		m.entries = append(m.entries, client.OutboxEntry{
+			Sequence: m.sequence,
+			Event:    event,
+		})
+	}
+	return nil
+}
+
+func (m *outboxMock) Pending() ([]client.OutboxEntry, error) {
+	entries := make([]client.OutboxEntry, len(m.entries))
+	copy(entries, m.entries)
+	return entries, nil
+}
+
+func (m *outboxMock) Remove(sequence uint64) error {
+	for i, entry := range m.entries {
+		if entry.Sequence == sequence {
+			m.entries = append(m.entries[:i], m.entries[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+type alertmanagerClientMock struct {
+	collector.IAlertmanagerClient
+	fakePostAlerts func(context.Context, []client.Alert) error
+	posted         [][]client.Alert
+}
+
+func (m *alertmanagerClientMock) PostAlerts(ctx context.Context, alerts []client.Alert) error {
+	m.posted = append(m.posted, alerts)
+	if m.fakePostAlerts != nil {
+		return m.fakePostAlerts(ctx, alerts)
+	}
+	return nil
+}
+
+type notifierMock struct {
+	collector.INotifier
+	notified [][]string
+	err      error
+}
+
+func (m *notifierMock) Notify(_ context.Context, findings []collector.WorkloadFinding) error {
+	if m.err != nil {
+		return m.err
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		keys = append(keys, finding.Key())
+	}
+	m.notified = append(m.notified, keys)
+	return nil
+}
+
+type namespaceClientMock struct {
+	collector.INamespaceClient
+	namespaces []client.Namespace
+	err        error
+}
+
+func (m *namespaceClientMock) Namespaces() ([]client.Namespace, error) {
+	return m.namespaces, m.err
+}
+
+type notificationStoreMock struct {
+	collector.INotificationStore
+	times map[string]time.Time
+}
+
+func (m *notificationStoreMock) NotificationTimes() (map[string]time.Time, error) {
+	times := make(map[string]time.Time, len(m.times))
+	for key, t := range m.times {
+		times[key] = t
+	}
+	return times, nil
+}
+
+func (m *notificationStoreMock) SetNotificationTimes(times map[string]time.Time) error {
+	m.times = make(map[string]time.Time, len(times))
+	for key, t := range times {
+		m.times[key] = t
+	}
+	return nil
+}
+
+type statefulSetClientMock struct {
+	collector.IStatefulSetClient
+	replicas int
+}
+
+func (m *statefulSetClientMock) StatefulSetReplicas(string, string) (int, error) {
+	return m.replicas, nil
+}
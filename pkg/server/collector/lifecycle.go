@@ -0,0 +1,153 @@
+package collector
+
+import (
+	"context"
+	"kube-dockle-exporter/pkg/client"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/xerrors"
+)
+
+type LifecycleCollector struct {
+	store      IFindingStore
+	states     map[string]client.FindingState
+	mutex      sync.Mutex
+	firstSeen  *prometheus.GaugeVec
+	introduced *prometheus.CounterVec
+	resolved   *prometheus.CounterVec
+}
+
+// NewLifecycleCollector tracks when findings appear and disappear, persisting the state to store if not nil.
+func NewLifecycleCollector(store IFindingStore) *LifecycleCollector {
+	return &LifecycleCollector{
+		store: store,
+		firstSeen: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "finding_first_seen_timestamp_seconds",
+			Help:      "Unix time when the finding was first seen",
+		}, []string{"image", "code", "level", "cluster"}),
+		introduced: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "findings_introduced_total",
+			Help:      "Number of findings which newly appeared",
+		}, []string{"level", "cluster"}),
+		resolved: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "findings_resolved_total",
+			Help:      "Number of findings which disappeared",
+		}, []string{"level", "cluster"}),
+	}
+}
+
+// Reset drops first-seen timestamps, e.g. when the lease is lost and the new leader exports its own.
+// States are loaded from the store again on the next publish, since the new leader keeps updating it meanwhile.
+func (c *LifecycleCollector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.firstSeen.Reset()
+	c.states = nil
+}
+
+func (c *LifecycleCollector) Publish(ctx context.Context, snapshot *Snapshot) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.states == nil {
+		c.states = make(map[string]client.FindingState)
+		if c.store != nil {
+			states, err := c.store.FindingStates()
+			if err != nil {
+				return xerrors.Errorf("failed to load finding states: %w", err)
+			}
+			c.states = states
+		}
+	}
+
+	// Findings are tracked per cluster, and keys of the unnamed cluster are kept compatible with stored ones.
+	stateKey := func(cluster string, finding *Finding) string {
+		if cluster == "" {
+			return finding.Key()
+		}
+		return cluster + ":" + finding.Key()
+	}
+	imageClusters := snapshot.ImageClusters()
+	current := make(map[string]bool)
+	// Suppressed findings are still there, and neither resolved nor introduced again when exceptions expire.
+	findings := snapshot.Findings()
+	for _, suppressed := range snapshot.Suppressed {
+		findings = append(findings, suppressed.Finding)
+	}
+	for _, finding := range findings {
+		for _, cluster := range imageClusters[finding.Image] {
+			key := stateKey(cluster, &finding)
+			current[key] = true
+			if _, ok := c.states[key]; ok {
+				continue
+			}
+			c.states[key] = client.FindingState{
+				Cluster:   cluster,
+				Image:     finding.Image,
+				Code:      finding.Code,
+				Level:     finding.Level,
+				FirstSeen: snapshot.ScannedAt,
+			}
+			c.introduced.WithLabelValues(finding.Level, cluster).Inc()
+		}
+	}
+
+	deployed := make(map[string]bool)
+	for image, clusters := range imageClusters {
+		for _, cluster := range clusters {
+			deployed[cluster+" "+image] = true
+		}
+	}
+	for key, state := range c.states {
+		if current[key] {
+			continue
+		}
+		// Findings of images which moved to other shards are theirs, and are neither resolved nor kept.
+		if !snapshot.InScope(state.Image) {
+			delete(c.states, key)
+			continue
+		}
+		// Findings of images which failed to be scanned are kept until the next successful scan.
+		if _, scanned := snapshot.Responses[state.Image]; !scanned && deployed[state.Cluster+" "+state.Image] {
+			continue
+		}
+		delete(c.states, key)
+		c.resolved.WithLabelValues(state.Level, state.Cluster).Inc()
+	}
+
+	c.firstSeen.Reset()
+	for _, state := range c.states {
+		c.firstSeen.WithLabelValues(state.Image, state.Code, state.Level, state.Cluster).Set(float64(state.FirstSeen.Unix()))
+	}
+
+	if c.store != nil {
+		if err := c.store.SetFindingStates(c.states); err != nil {
+			return xerrors.Errorf("failed to store finding states: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *LifecycleCollector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.firstSeen,
+		c.introduced,
+		c.resolved,
+	}
+}
+
+func (c *LifecycleCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range c.collectors() {
+		collector.Describe(ch)
+	}
+}
+
+func (c *LifecycleCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range c.collectors() {
+		collector.Collect(ch)
+	}
+}
@@ -0,0 +1,157 @@
+package collector_test
+
+import (
+	"context"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestLifecycleCollector(t *testing.T) {
+	withDetails := func(scannedAt int64, details ...client.DockleDetail) *collector.Snapshot {
+		snapshot := fakeSnapshot()
+		snapshot.ScannedAt = time.Unix(scannedAt, 0)
+		snapshot.Responses["fake"] = client.DockleResponse{
+			Target:  "fake",
+			Details: details,
+		}
+		return snapshot
+	}
+
+	tests := []struct {
+		name  string
+		store *findingStoreMock
+		in    []*collector.Snapshot
+		want  string
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&findingStoreMock{},
+			[]*collector.Snapshot{
+				withDetails(10, client.DockleDetail{Code: "resolved", Level: "WARN"}, client.DockleDetail{Code: "unchanged", Level: "FATAL"}),
+				withDetails(20, client.DockleDetail{Code: "unchanged", Level: "FATAL"}, client.DockleDetail{Code: "introduced", Level: "INFO"}),
+			},
+			`
+# HELP dockle_finding_first_seen_timestamp_seconds Unix time when the finding was first seen
+# TYPE dockle_finding_first_seen_timestamp_seconds gauge
+dockle_finding_first_seen_timestamp_seconds{cluster="",code="introduced",image="fake",level="INFO"} 20
+dockle_finding_first_seen_timestamp_seconds{cluster="",code="unchanged",image="fake",level="FATAL"} 10
+# HELP dockle_findings_introduced_total Number of findings which newly appeared
+# TYPE dockle_findings_introduced_total counter
+dockle_findings_introduced_total{cluster="",level="FATAL"} 1
+dockle_findings_introduced_total{cluster="",level="INFO"} 1
+dockle_findings_introduced_total{cluster="",level="WARN"} 1
+# HELP dockle_findings_resolved_total Number of findings which disappeared
+# TYPE dockle_findings_resolved_total counter
+dockle_findings_resolved_total{cluster="",level="WARN"} 1
+`,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&findingStoreMock{
+				states: map[string]client.FindingState{
+					"fake unchanged": {
+						Image:     "fake",
+						Code:      "unchanged",
+						Level:     "FATAL",
+						FirstSeen: time.Unix(5, 0),
+					},
+				},
+			},
+			[]*collector.Snapshot{
+				withDetails(10, client.DockleDetail{Code: "unchanged", Level: "FATAL"}),
+				func() *collector.Snapshot {
+					snapshot := withDetails(20)
+					delete(snapshot.Responses, "fake")
+					return snapshot
+				}(),
+			},
+			`
+# HELP dockle_finding_first_seen_timestamp_seconds Unix time when the finding was first seen
+# TYPE dockle_finding_first_seen_timestamp_seconds gauge
+dockle_finding_first_seen_timestamp_seconds{cluster="",code="unchanged",image="fake",level="FATAL"} 5
+`,
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		store := tt.store
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			receiver := collector.NewLifecycleCollector(store)
+			for _, snapshot := range in {
+				if err := receiver.Publish(context.Background(), snapshot); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := testutil.CollectAndCompare(receiver, strings.NewReader(want)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestLifecycleCollectorReset(t *testing.T) {
+	withDetails := func(scannedAt int64, details ...client.DockleDetail) *collector.Snapshot {
+		snapshot := fakeSnapshot()
+		snapshot.ScannedAt = time.Unix(scannedAt, 0)
+		snapshot.Responses["fake"] = client.DockleResponse{
+			Target:  "fake",
+			Details: details,
+		}
+		return snapshot
+	}
+	store := &findingStoreMock{}
+	receiver := collector.NewLifecycleCollector(store)
+	if err := receiver.Publish(context.Background(), withDetails(10, client.DockleDetail{Code: "fake", Level: "WARN"})); err != nil {
+		t.Fatal(err)
+	}
+
+	receiver.Reset()
+	want := `
+# HELP dockle_finding_first_seen_timestamp_seconds Unix time when the finding was first seen
+# TYPE dockle_finding_first_seen_timestamp_seconds gauge
+`
+	if err := testutil.CollectAndCompare(
+		receiver,
+		strings.NewReader(want),
+		"dockle_finding_first_seen_timestamp_seconds",
+	); err != nil {
+		t.Error(err)
+	}
+
+	// The state stored by the new leader meanwhile is loaded again.
+	store.states = map[string]client.FindingState{
+		"fake fake": {Image: "fake", Code: "fake", Level: "WARN", FirstSeen: time.Unix(5, 0)},
+	}
+	if err := receiver.Publish(context.Background(), withDetails(20, client.DockleDetail{Code: "fake", Level: "WARN"})); err != nil {
+		t.Fatal(err)
+	}
+	want = `
+# HELP dockle_finding_first_seen_timestamp_seconds Unix time when the finding was first seen
+# TYPE dockle_finding_first_seen_timestamp_seconds gauge
+dockle_finding_first_seen_timestamp_seconds{cluster="",code="fake",image="fake",level="WARN"} 5
+`
+	if err := testutil.CollectAndCompare(
+		receiver,
+		strings.NewReader(want),
+		"dockle_finding_first_seen_timestamp_seconds",
+	); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// DeliveryError tells that findings failed to be delivered, but are kept and sent again by the notifier itself, so
+// that they must not be given again.
+type DeliveryError struct {
+	Err error
+}
+
+func (e *DeliveryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// NotificationPublisher passes findings at or above the level which a scan newly introduced to notifiers.
+// Each notifier is compared with findings which it was last given successfully, so that findings which it failed to
+// deliver are introduced again on the next scan. Findings of images which failed to be scanned are kept until the next
+// successful scan, so that they are not introduced again after it.
+type NotificationPublisher struct {
+	notifiers []INotifier
+	level     string
+	previous  [][]WorkloadFinding
+	scanned   bool
+}
+
+func NewNotificationPublisher(level string) *NotificationPublisher {
+	return &NotificationPublisher{
+		level: level,
+	}
+}
+
+func (p *NotificationPublisher) AddNotifier(notifier INotifier) {
+	p.notifiers = append(p.notifiers, notifier)
+	p.previous = append(p.previous, nil)
+}
+
+func (p *NotificationPublisher) Publish(ctx context.Context, snapshot *Snapshot) error {
+	var current []WorkloadFinding
+	for _, finding := range snapshot.WorkloadFindings() {
+		if finding.AtLeast(p.level) {
+			current = append(current, finding)
+		}
+	}
+
+	// The first scan has nothing to compare with, so it only becomes the baseline.
+	if !p.scanned {
+		for i := range p.previous {
+			p.previous[i] = current
+		}
+		p.scanned = true
+		return nil
+	}
+
+	// Notifiers are called even without new findings, so that they can send what they deferred.
+	unscanned := snapshot.unscanned()
+	var errs []error
+	for i, notifier := range p.notifiers {
+		known := append([]WorkloadFinding{}, current...)
+		for _, finding := range p.previous[i] {
+			if unscanned[finding.Workload.Cluster+" "+finding.Image] {
+				known = append(known, finding)
+			}
+		}
+		introduced := DiffWorkloadFindings(p.previous[i], known).Introduced
+		if err := notifier.Notify(ctx, introduced); err != nil {
+			errs = append(errs, err)
+			var deliveryErr *DeliveryError
+			if !xerrors.As(err, &deliveryErr) {
+				continue
+			}
+		}
+		p.previous[i] = known
+	}
+	if len(errs) > 0 {
+		return xerrors.Errorf("failed to notify %d of %d notifiers: %w", len(errs), len(p.notifiers), errs[0])
+	}
+	return nil
+}
+
+// ReloadableNotifier passes findings to a notifier which can be replaced, e.g. on reload of config, or drops them if
+// the notifier is nil. Notifications in progress finish before it is replaced.
+type ReloadableNotifier struct {
+	inner INotifier
+	mutex sync.Mutex
+}
+
+func NewReloadableNotifier(inner INotifier) *ReloadableNotifier {
+	return &ReloadableNotifier{
+		inner: inner,
+	}
+}
+
+// Replace calls the function with the current notifier, and replaces it with the result.
+func (n *ReloadableNotifier) Replace(replace func(current INotifier) INotifier) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.inner = replace(n.inner)
+}
+
+func (n *ReloadableNotifier) Notify(ctx context.Context, findings []WorkloadFinding) error {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.inner == nil {
+		return nil
+	}
+	return n.inner.Notify(ctx, findings)
+}
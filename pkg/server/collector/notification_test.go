@@ -0,0 +1,35 @@
+package collector_test
+
+import (
+	"context"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNotificationPublisherPublishFailedImages(t *testing.T) {
+	failed := fakeSnapshot()
+	failed.Responses = map[string]client.DockleResponse{}
+	failed.Failed = map[string]bool{"fake": true}
+	withFinding := fakeSnapshot()
+	response := withFinding.Responses["fake"]
+	response.Details = append(response.Details, client.DockleDetail{Code: "CIS-DI-0005", Level: "FATAL"})
+	withFinding.Responses["fake"] = response
+
+	notifier := &notifierMock{}
+	publisher := collector.NewNotificationPublisher("WARN")
+	publisher.AddNotifier(notifier)
+	for _, snapshot := range []*collector.Snapshot{fakeSnapshot(), failed, withFinding} {
+		if err := publisher.Publish(context.Background(), snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Findings of the image which failed to be scanned are not introduced again, unlike the new one.
+	want := [][]string{{"Deployment/fake/fake fake CIS-DI-0005"}}
+	if diff := cmp.Diff(want, notifier.notified); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,224 @@
+package collector
+
+import (
+	"context"
+	"kube-dockle-exporter/pkg/client"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/xerrors"
+)
+
+// ownerReservedLabels are labels of info metrics which owner labels cannot take.
+// nolint:gochecknoglobals
+var ownerReservedLabels = []string{"cluster", "namespace", "kind", "name", "image"}
+
+// NamespaceOwner holds allowed labels and annotations of a namespace keyed by their keys.
+type NamespaceOwner struct {
+	Cluster   string            `json:"cluster,omitempty"`
+	Namespace string            `json:"namespace"`
+	Owner     map[string]string `json:"owner"`
+}
+
+// Ownership tells owners of workloads by allowed labels and annotations of their namespaces.
+type Ownership struct {
+	labels      []string
+	annotations []string
+	labelNames  []string
+}
+
+// NewOwnership validates keys, which are exported as labels of metrics with characters other than letters, digits and
+// underscores replaced with underscores.
+func NewOwnership(labels []string, annotations []string) (*Ownership, error) {
+	ownership := &Ownership{
+		labels:      labels,
+		annotations: annotations,
+	}
+	names := make(map[string]string)
+	for _, name := range ownerReservedLabels {
+		names[name] = name
+	}
+	for _, key := range append(append([]string{}, labels...), annotations...) {
+		if key == "" {
+			return nil, xerrors.New("owner key must not be empty")
+		}
+		name := ownerLabelName(key)
+		if conflict, ok := names[name]; ok {
+			return nil, xerrors.Errorf("owner key %s conflicts with %s as label %s", key, conflict, name)
+		}
+		names[name] = key
+		ownership.labelNames = append(ownership.labelNames, name)
+	}
+	return ownership, nil
+}
+
+func ownerLabelName(key string) string {
+	name := []rune(key)
+	for i, r := range name {
+		if !(r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' && i > 0) {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
+
+// LabelNames returns names of labels of metrics in the order of keys.
+func (o *Ownership) LabelNames() []string {
+	return o.labelNames
+}
+
+// LabelValues returns values of labels of metrics of the owner, which are empty for missing keys.
+func (o *Ownership) LabelValues(owner map[string]string) []string {
+	values := make([]string, 0, len(o.labelNames))
+	for _, key := range append(append([]string{}, o.labels...), o.annotations...) {
+		values = append(values, owner[key])
+	}
+	return values
+}
+
+// Owners returns owners of the namespaces, or nothing without keys.
+func (o *Ownership) Owners(namespaces []client.Namespace) []NamespaceOwner {
+	if len(o.labelNames) == 0 {
+		return nil
+	}
+	owners := make([]NamespaceOwner, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		owner := make(map[string]string)
+		for _, key := range o.labels {
+			if value, ok := namespace.Labels[key]; ok {
+				owner[key] = value
+			}
+		}
+		for _, key := range o.annotations {
+			if value, ok := namespace.Annotations[key]; ok {
+				owner[key] = value
+			}
+		}
+		owners = append(owners, NamespaceOwner{
+			Cluster:   namespace.Cluster,
+			Namespace: namespace.Name,
+			Owner:     owner,
+		})
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		if owners[i].Cluster != owners[j].Cluster {
+			return owners[i].Cluster < owners[j].Cluster
+		}
+		return owners[i].Namespace < owners[j].Namespace
+	})
+	return owners
+}
+
+// setNamespaces sets labels of the namespaces to workloads in them, and owners of the namespaces if ownership is not
+// nil, so that all consumers see the namespaces fetched once on the scan.
+func (s *Snapshot) setNamespaces(namespaces []client.Namespace, ownership *Ownership) {
+	labels := make(map[string]map[string]string, len(namespaces))
+	for _, namespace := range namespaces {
+		labels[namespace.Cluster+" "+namespace.Name] = namespace.Labels
+	}
+	owners := make(map[string]map[string]string)
+	if ownership != nil {
+		s.Owners = ownership.Owners(namespaces)
+		for _, owner := range s.Owners {
+			owners[owner.Cluster+" "+owner.Namespace] = owner.Owner
+		}
+	}
+	for i := range s.Workloads {
+		key := s.Workloads[i].Cluster + " " + s.Workloads[i].Namespace
+		s.Workloads[i].NamespaceLabels = labels[key]
+		s.Workloads[i].Owner = owners[key]
+	}
+}
+
+// OwnerString formats the owner as key=value pairs sorted by keys.
+func OwnerString(owner map[string]string) string {
+	keys := make([]string, 0, len(owner))
+	for key := range owner {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+owner[key])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// OwnershipCollector exports owners of namespaces, and images of workloads with owners of them, so that other metrics
+// can be joined with owners.
+type OwnershipCollector struct {
+	ownership *Ownership
+	mutex     sync.Mutex
+	owners    *prometheus.GaugeVec
+	workloads *prometheus.GaugeVec
+}
+
+func NewOwnershipCollector(ownership *Ownership) *OwnershipCollector {
+	return &OwnershipCollector{
+		ownership: ownership,
+		owners: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "namespace_owner_info",
+			Help:      "Owner of the namespace told by its labels and annotations",
+		}, append([]string{"cluster", "namespace"}, ownership.LabelNames()...)),
+		workloads: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "workload_info",
+			Help:      "Image of the workload with the owner of its namespace",
+		}, append([]string{"cluster", "namespace", "kind", "name", "image"}, ownership.LabelNames()...)),
+	}
+}
+
+func (c *OwnershipCollector) Publish(ctx context.Context, snapshot *Snapshot) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.owners.Reset()
+	for _, owner := range snapshot.Owners {
+		labels := append([]string{owner.Cluster, owner.Namespace}, c.ownership.LabelValues(owner.Owner)...)
+		c.owners.WithLabelValues(labels...).Set(1)
+	}
+	c.workloads.Reset()
+	for _, workload := range snapshot.Workloads {
+		for _, image := range workload.Images() {
+			if !snapshot.InScope(image) {
+				continue
+			}
+			labels := append(
+				[]string{workload.Cluster, workload.Namespace, workload.Kind, workload.Name, image},
+				c.ownership.LabelValues(workload.Owner)...,
+			)
+			c.workloads.WithLabelValues(labels...).Set(1)
+		}
+	}
+	return nil
+}
+
+// Reset drops owners and workloads, e.g. when the lease is lost and the new leader exports its own.
+func (c *OwnershipCollector) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.owners.Reset()
+	c.workloads.Reset()
+}
+
+func (c *OwnershipCollector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		c.owners,
+		c.workloads,
+	}
+}
+
+func (c *OwnershipCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, collector := range c.collectors() {
+		collector.Describe(ch)
+	}
+}
+
+func (c *OwnershipCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, collector := range c.collectors() {
+		collector.Collect(ch)
+	}
+}
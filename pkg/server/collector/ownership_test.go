@@ -0,0 +1,358 @@
+package collector_test
+
+import (
+	"context"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestNewOwnership(t *testing.T) {
+	type want struct {
+		labelNames  []string
+		errorString string
+	}
+
+	tests := []struct {
+		name        string
+		labels      []string
+		annotations []string
+		want        want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]string{"team", "slack-channel"},
+			[]string{"example.com/owner"},
+			want{
+				[]string{"team", "slack_channel", "example_com_owner"},
+				"",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]string{"1team"},
+			nil,
+			want{
+				[]string{"_team"},
+				"",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]string{"slack-channel"},
+			[]string{"slack.channel"},
+			want{
+				nil,
+				"owner key slack.channel conflicts with slack-channel as label slack_channel",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]string{"namespace"},
+			nil,
+			want{
+				nil,
+				"owner key namespace conflicts with namespace as label namespace",
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		labels := tt.labels
+		annotations := tt.annotations
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ownership, err := collector.NewOwnership(labels, annotations)
+			gotErrorString := ""
+			if err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(want.errorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(want.labelNames, ownership.LabelNames()); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOwnershipOwners(t *testing.T) {
+	namespaces := []client.Namespace{
+		{
+			Cluster:     "remote",
+			Name:        "payments",
+			Labels:      map[string]string{"team": "payments", "environment": "production"},
+			Annotations: map[string]string{"slack-channel": "#payments"},
+		},
+		{
+			Name:   "default",
+			Labels: map[string]string{"kubernetes.io/metadata.name": "default"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		labels      []string
+		annotations []string
+		want        []collector.NamespaceOwner
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]string{"team"},
+			[]string{"slack-channel"},
+			[]collector.NamespaceOwner{
+				{Namespace: "default", Owner: map[string]string{}},
+				{Cluster: "remote", Namespace: "payments", Owner: map[string]string{"team": "payments", "slack-channel": "#payments"}},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			nil,
+			nil,
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		labels := tt.labels
+		annotations := tt.annotations
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ownership, err := collector.NewOwnership(labels, annotations)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want, ownership.Owners(namespaces)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDockleCollectorScanOwners(t *testing.T) {
+	c := collector.NewDockleCollector(
+		&loggerMock{},
+		&kubernetesClientMock{
+			fakeWorkloads: func() ([]client.Workload, error) {
+				return []client.Workload{
+					{
+						Cluster:   "remote",
+						Kind:      "Deployment",
+						Namespace: "payments",
+						Name:      "api",
+						PodSpec: v1.PodSpec{
+							Containers: []v1.Container{{Image: "fake"}},
+						},
+					},
+				}, nil
+			},
+			wantFakeWorkloadsCalled: 1,
+		},
+		&dockleClientMock{
+			fakeDo: func(ctx context.Context, image string) ([]byte, error) {
+				return []byte(`{"Details":[]}`), nil
+			},
+			wantFakeDoCalled: 1,
+		},
+		1,
+	)
+	c.SetNamespaces(&namespaceClientMock{
+		namespaces: []client.Namespace{
+			{Cluster: "remote", Name: "payments", Labels: map[string]string{"team": "payments"}},
+			{Name: "payments", Labels: map[string]string{"team": "other"}},
+		},
+	})
+	ownership, err := collector.NewOwnership([]string{"team"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetOwnership(ownership)
+	if err := c.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	c.Logger.(*loggerMock).assert(t)
+	c.KubernetesClient.(*kubernetesClientMock).assert(t)
+	c.DockleClient.(*dockleClientMock).assert(t)
+
+	if diff := cmp.Diff(map[string]string{"team": "payments"}, c.Snapshot().Workloads[0].Owner); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"team": "payments"}, c.Snapshot().Workloads[0].NamespaceLabels); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestDockleCollectorScanNamespacesFailure(t *testing.T) {
+	tests := []struct {
+		name             string
+		severities       []collector.SeverityConfig
+		wantErrorfCalled int
+		wantErrorString  string
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]collector.SeverityConfig{
+				{Code: "CIS-DI-0001", Namespaces: []string{"payments"}, Level: "FATAL"},
+			},
+			1,
+			"",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]collector.SeverityConfig{
+				{Code: "CIS-DI-0001", NamespaceLabels: map[string]string{"team": "payments"}, Level: "FATAL"},
+			},
+			0,
+			"failed to get namespaces: fake",
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		severityConfigs := tt.severities
+		wantErrorfCalled := tt.wantErrorfCalled
+		wantErrorString := tt.wantErrorString
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			c := collector.NewDockleCollector(
+				&loggerMock{
+					fakeErrorf:           func(format string, v ...interface{}) {},
+					wantFakeErrorfCalled: wantErrorfCalled,
+				},
+				&kubernetesClientMock{
+					fakeWorkloads: func() ([]client.Workload, error) {
+						return []client.Workload{
+							{
+								Kind:      "Deployment",
+								Namespace: "payments",
+								Name:      "api",
+								PodSpec: v1.PodSpec{
+									Containers: []v1.Container{{Image: "fake"}},
+								},
+							},
+						}, nil
+					},
+					wantFakeWorkloadsCalled: 1,
+				},
+				&dockleClientMock{
+					fakeDo: func(ctx context.Context, image string) ([]byte, error) {
+						return []byte(`{"Details":[]}`), nil
+					},
+				},
+				1,
+			)
+			c.SetNamespaces(&namespaceClientMock{err: xerrors.New("fake")})
+			severities, err := collector.NewSeverities(severityConfigs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.SetSeverities(severities)
+			// Namespaces failing entirely fail the scan only if severities match labels of them.
+			gotErrorString := ""
+			if err := c.Scan(context.Background()); err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(wantErrorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			c.Logger.(*loggerMock).assert(t)
+		})
+	}
+}
+
+func TestDockleCollectorResetOwnership(t *testing.T) {
+	c := collector.NewDockleCollector(
+		&loggerMock{},
+		&kubernetesClientMock{
+			fakeWorkloads: func() ([]client.Workload, error) {
+				return []client.Workload{
+					{
+						Kind:      "Deployment",
+						Namespace: "payments",
+						Name:      "api",
+						PodSpec: v1.PodSpec{
+							Containers: []v1.Container{{Image: "fake"}},
+						},
+					},
+				}, nil
+			},
+			wantFakeWorkloadsCalled: 1,
+		},
+		&dockleClientMock{
+			fakeDo: func(ctx context.Context, image string) ([]byte, error) {
+				return []byte(`{"Details":[]}`), nil
+			},
+			wantFakeDoCalled: 1,
+		},
+		1,
+	)
+	c.SetNamespaces(&namespaceClientMock{
+		namespaces: []client.Namespace{
+			{Name: "payments", Labels: map[string]string{"team": "payments"}},
+		},
+	})
+	ownership, err := collector.NewOwnership([]string{"team"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetOwnership(ownership)
+	ownershipCollector := collector.NewOwnershipCollector(ownership)
+	c.AddPublisher(ownershipCollector)
+	if err := c.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(2, collectedMetrics(ownershipCollector)); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+
+	c.Reset()
+	if diff := cmp.Diff(0, collectedMetrics(ownershipCollector)); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func collectedMetrics(c prometheus.Collector) int {
+	ch := make(chan prometheus.Metric, 32)
+	c.Collect(ch)
+	close(ch)
+	return len(ch)
+}
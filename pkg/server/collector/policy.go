@@ -0,0 +1,164 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/loader"
+	"github.com/open-policy-agent/opa/rego"
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+)
+
+// policyRules are names of rules whose values are violations, following conftest.
+// nolint:gochecknoglobals
+var policyRules = []string{"deny", "violation"}
+
+// PolicyInput is the input document of policies, given for each image of each workload.
+type PolicyInput struct {
+	Workload WorkloadRef           `json:"workload"`
+	PodSpec  v1.PodSpec            `json:"podSpec"`
+	Image    string                `json:"image"`
+	Response client.DockleResponse `json:"response"`
+}
+
+type PolicyViolation struct {
+	Policy   string      `json:"policy"`
+	Workload WorkloadRef `json:"workload"`
+	Image    string      `json:"image"`
+	Message  string      `json:"message"`
+}
+
+func (v *PolicyViolation) Key() string {
+	return v.Policy + " " + v.Workload.key() + " " + v.Message
+}
+
+type policyQuery struct {
+	policy string
+	query  rego.PreparedEvalQuery
+}
+
+// Policies evaluates Rego modules over results of scans.
+type Policies struct {
+	queries []policyQuery
+}
+
+// LoadPolicies loads Rego modules from files and directories, skipping tests.
+func LoadPolicies(ctx context.Context, paths []string) (*Policies, error) {
+	result, err := loader.NewFileLoader().Filtered(paths, func(abspath string, info os.FileInfo, depth int) bool {
+		return !info.IsDir() && (!strings.HasSuffix(info.Name(), ".rego") || strings.HasSuffix(info.Name(), "_test.rego"))
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("could not load policies: %w", err)
+	}
+	return NewPolicies(ctx, result.ParsedModules())
+}
+
+// NewPolicies compiles modules, and queries deny and violation rules of each package.
+func NewPolicies(ctx context.Context, modules map[string]*ast.Module) (*Policies, error) {
+	compiler := ast.NewCompiler()
+	if compiler.Compile(modules); compiler.Failed() {
+		return nil, xerrors.Errorf("could not compile policies: %w", compiler.Errors)
+	}
+
+	queries := make(map[string]bool)
+	for _, module := range modules {
+		for _, rule := range module.Rules {
+			for _, name := range policyRules {
+				if rule.Head.Name.String() == name {
+					queries[module.Package.Path.String()+"."+name] = true
+				}
+			}
+		}
+	}
+	names := make([]string, 0, len(queries))
+	for query := range queries {
+		names = append(names, query)
+	}
+	sort.Strings(names)
+
+	policies := &Policies{}
+	for _, name := range names {
+		query, err := rego.New(
+			rego.Query(name),
+			rego.Compiler(compiler),
+		).PrepareForEval(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("could not prepare %s: %w", name, err)
+		}
+		policies.queries = append(policies.queries, policyQuery{
+			policy: strings.TrimPrefix(name[:strings.LastIndex(name, ".")], "data."),
+			query:  query,
+		})
+	}
+	return policies, nil
+}
+
+// Evaluate evaluates policies for each scanned image of each workload.
+func (p *Policies) Evaluate(ctx context.Context, snapshot *Snapshot) ([]PolicyViolation, error) {
+	keys := make(map[string]bool)
+	var violations []PolicyViolation
+	for _, workload := range snapshot.Workloads {
+		for _, image := range workload.Images() {
+			response, ok := snapshot.Responses[image]
+			if !ok {
+				continue
+			}
+			input := &PolicyInput{
+				Workload: refOf(&workload),
+				PodSpec:  workload.PodSpec,
+				Image:    image,
+				Response: response,
+			}
+			for _, q := range p.queries {
+				results, err := q.query.Eval(ctx, rego.EvalInput(input))
+				if err != nil {
+					return nil, xerrors.Errorf("could not evaluate %s: %w", q.policy, err)
+				}
+				for _, result := range results {
+					for _, expression := range result.Expressions {
+						values, ok := expression.Value.([]interface{})
+						if !ok {
+							return nil, xerrors.Errorf("%s must be a set, but got %T", q.policy, expression.Value)
+						}
+						for _, value := range values {
+							violation := PolicyViolation{
+								Policy:   q.policy,
+								Workload: input.Workload,
+								Image:    image,
+								Message:  policyMessage(value),
+							}
+							if keys[violation.Key()] {
+								continue
+							}
+							keys[violation.Key()] = true
+							violations = append(violations, violation)
+						}
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].Key() < violations[j].Key()
+	})
+	return violations, nil
+}
+
+// policyMessage returns a string value or msg of an object value, as conftest does.
+func policyMessage(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		if msg, ok := v["msg"].(string); ok {
+			return msg
+		}
+	}
+	return fmt.Sprint(value)
+}
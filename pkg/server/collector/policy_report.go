@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"context"
+	"kube-dockle-exporter/pkg/client"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	PolicyReportScopeNamespace = "namespace"
+	PolicyReportScopeCluster   = "cluster"
+
+	policyReportName   = "kube-dockle-exporter"
+	policyReportSource = "dockle"
+)
+
+type PolicyReportPublisher struct {
+	client IPolicyReportClient
+	scope  string
+}
+
+func NewPolicyReportPublisher(policyReportClient IPolicyReportClient, scope string) (*PolicyReportPublisher, error) {
+	switch scope {
+	case PolicyReportScopeNamespace, PolicyReportScopeCluster:
+	default:
+		return nil, xerrors.Errorf("unknown policy report scope %q", scope)
+	}
+	return &PolicyReportPublisher{
+		client: policyReportClient,
+		scope:  scope,
+	}, nil
+}
+
+// policyReportResult maps dockle levels onto the result and severity of wgpolicyk8s.io.
+func policyReportResult(level string) (string, string) {
+	switch level {
+	case client.LevelFatal:
+		return "fail", "high"
+	case client.LevelWarn:
+		return "warn", "medium"
+	case client.LevelInfo:
+		return "warn", "info"
+	case client.LevelSkip:
+		return "skip", "info"
+	default:
+		return "pass", "info"
+	}
+}
+
+func countPolicyReportResult(s *client.PolicyReportSummary, result string) {
+	switch result {
+	case "pass":
+		s.Pass++
+	case "fail":
+		s.Fail++
+	case "warn":
+		s.Warn++
+	case "error":
+		s.Error++
+	case "skip":
+		s.Skip++
+	}
+}
+
+func (p *PolicyReportPublisher) Reports(snapshot *Snapshot) []*client.PolicyReport {
+	timestamp := metaV1.Timestamp{
+		Seconds: snapshot.ScannedAt.Unix(),
+		Nanos:   int32(snapshot.ScannedAt.Nanosecond()),
+	}
+
+	reports := make(map[string]*client.PolicyReport)
+	for _, workload := range snapshot.Workloads {
+		// Reports are applied to the cluster of the exporter, so workloads of the others have no place.
+		if workload.Remote {
+			continue
+		}
+		key := workload.Namespace
+		if p.scope == PolicyReportScopeCluster {
+			key = ""
+		}
+		report, ok := reports[key]
+		if !ok {
+			report = p.newReport(key)
+			reports[key] = report
+		}
+
+		for _, image := range workload.Images() {
+			response, ok := snapshot.Responses[image]
+			if !ok {
+				continue
+			}
+			for _, detail := range response.Details {
+				result, severity := policyReportResult(detail.Level)
+				message := detail.Title
+				if len(detail.Alerts) > 0 {
+					message += ": " + strings.Join(detail.Alerts, ", ")
+				}
+				report.Results = append(report.Results, client.PolicyReportResult{
+					Source:    policyReportSource,
+					Policy:    detail.Code,
+					Category:  "CIS Benchmarks",
+					Severity:  severity,
+					Timestamp: timestamp,
+					Result:    result,
+					Scored:    true,
+					Message:   message,
+					Resources: []v1.ObjectReference{
+						workload.ObjectReference(),
+					},
+					Properties: map[string]string{
+						"image": image,
+						"level": detail.Level,
+					},
+				})
+				countPolicyReportResult(&report.Summary, result)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(reports))
+	for key := range reports {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	sorted := make([]*client.PolicyReport, 0, len(keys))
+	for _, key := range keys {
+		sorted = append(sorted, reports[key])
+	}
+	return sorted
+}
+
+func (p *PolicyReportPublisher) newReport(namespace string) *client.PolicyReport {
+	kind := client.PolicyReportKind
+	if p.scope == PolicyReportScopeCluster {
+		kind = client.ClusterPolicyReportKind
+	}
+	return &client.PolicyReport{
+		TypeMeta: metaV1.TypeMeta{
+			APIVersion: client.PolicyReportAPIVersion,
+			Kind:       kind,
+		},
+		ObjectMeta: metaV1.ObjectMeta{
+			Name:      policyReportName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				client.PolicyReportManagedByLabel: client.PolicyReportManagedBy,
+			},
+		},
+	}
+}
+
+func (p *PolicyReportPublisher) Publish(ctx context.Context, snapshot *Snapshot) error {
+	reports := p.Reports(snapshot)
+
+	applied := make(map[string]bool)
+	for _, report := range reports {
+		if err := p.client.Apply(ctx, report); err != nil {
+			return xerrors.Errorf("failed to apply policy report: %w", err)
+		}
+		applied[report.Kind+"/"+report.Namespace+"/"+report.Name] = true
+	}
+
+	existing, err := p.client.List(ctx)
+	if err != nil {
+		return xerrors.Errorf("failed to list policy reports: %w", err)
+	}
+	for i := range existing {
+		report := &existing[i]
+		if applied[report.Kind+"/"+report.Namespace+"/"+report.Name] {
+			continue
+		}
+		if err := p.client.Delete(ctx, report); err != nil {
+			return xerrors.Errorf("failed to delete stale policy report: %w", err)
+		}
+	}
+	return nil
+}
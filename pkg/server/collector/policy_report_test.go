@@ -0,0 +1,276 @@
+package collector_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func fakeSnapshot() *collector.Snapshot {
+	return &collector.Snapshot{
+		Workloads: []client.Workload{
+			{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Namespace:  "fake",
+				Name:       "fake",
+				PodSpec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Image: "fake",
+						},
+					},
+				},
+			},
+		},
+		Responses: map[string]client.DockleResponse{
+			"fake": {
+				Target: "fake",
+				Details: []client.DockleDetail{
+					{
+						Code:   "CIS-DI-0001",
+						Title:  "Create a user for the container",
+						Level:  "WARN",
+						Alerts: []string{"Last user should not be root"},
+					},
+				},
+			},
+		},
+		ScannedAt: time.Unix(1, 0),
+	}
+}
+
+func TestPolicyReportPublisherReports(t *testing.T) {
+	type in struct {
+		first *collector.Snapshot
+	}
+
+	type want struct {
+		first []*client.PolicyReport
+	}
+
+	tests := []struct {
+		name     string
+		receiver *collector.PolicyReportPublisher
+		in       in
+		want     want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func() *collector.PolicyReportPublisher {
+				publisher, err := collector.NewPolicyReportPublisher(&policyReportClientMock{}, collector.PolicyReportScopeNamespace)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return publisher
+			}(),
+			in{
+				fakeSnapshot(),
+			},
+			want{
+				[]*client.PolicyReport{
+					{
+						TypeMeta: metaV1.TypeMeta{
+							APIVersion: "wgpolicyk8s.io/v1alpha2",
+							Kind:       "PolicyReport",
+						},
+						ObjectMeta: metaV1.ObjectMeta{
+							Name:      "kube-dockle-exporter",
+							Namespace: "fake",
+							Labels: map[string]string{
+								"app.kubernetes.io/managed-by": "kube-dockle-exporter",
+							},
+						},
+						Summary: client.PolicyReportSummary{
+							Warn: 1,
+						},
+						Results: []client.PolicyReportResult{
+							{
+								Source:    "dockle",
+								Policy:    "CIS-DI-0001",
+								Category:  "CIS Benchmarks",
+								Severity:  "medium",
+								Timestamp: metaV1.Timestamp{Seconds: 1},
+								Result:    "warn",
+								Scored:    true,
+								Message:   "Create a user for the container: Last user should not be root",
+								Resources: []v1.ObjectReference{
+									{
+										APIVersion: "apps/v1",
+										Kind:       "Deployment",
+										Namespace:  "fake",
+										Name:       "fake",
+									},
+								},
+								Properties: map[string]string{
+									"image": "fake",
+									"level": "WARN",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func() *collector.PolicyReportPublisher {
+				publisher, err := collector.NewPolicyReportPublisher(&policyReportClientMock{}, collector.PolicyReportScopeCluster)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return publisher
+			}(),
+			in{
+				&collector.Snapshot{
+					Workloads: []client.Workload{
+						{
+							Namespace: "fake",
+						},
+					},
+				},
+			},
+			want{
+				[]*client.PolicyReport{
+					{
+						TypeMeta: metaV1.TypeMeta{
+							APIVersion: "wgpolicyk8s.io/v1alpha2",
+							Kind:       "ClusterPolicyReport",
+						},
+						ObjectMeta: metaV1.ObjectMeta{
+							Name: "kube-dockle-exporter",
+							Labels: map[string]string{
+								"app.kubernetes.io/managed-by": "kube-dockle-exporter",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		receiver := tt.receiver
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := receiver.Reports(in.first)
+			if diff := cmp.Diff(want.first, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPolicyReportPublisherPublish(t *testing.T) {
+	type in struct {
+		first  context.Context
+		second *collector.Snapshot
+	}
+
+	tests := []struct {
+		name            string
+		receiver        *policyReportClientMock
+		in              in
+		wantErrorString string
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&policyReportClientMock{
+				fakeApply: func(ctx context.Context, report *client.PolicyReport) error {
+					return nil
+				},
+				wantFakeApplyCalled: 1,
+				fakeList: func(ctx context.Context) ([]client.PolicyReport, error) {
+					return []client.PolicyReport{
+						{
+							TypeMeta:   metaV1.TypeMeta{Kind: "PolicyReport"},
+							ObjectMeta: metaV1.ObjectMeta{Namespace: "fake", Name: "kube-dockle-exporter"},
+						},
+						{
+							TypeMeta:   metaV1.TypeMeta{Kind: "PolicyReport"},
+							ObjectMeta: metaV1.ObjectMeta{Namespace: "stale", Name: "kube-dockle-exporter"},
+						},
+					}, nil
+				},
+				wantFakeListCalled: 1,
+				fakeDelete: func(ctx context.Context, report *client.PolicyReport) error {
+					if diff := cmp.Diff("stale", report.Namespace); diff != "" {
+						t.Errorf("(-want +got):\n%s", diff)
+					}
+					return nil
+				},
+				wantFakeDeleteCalled: 1,
+			},
+			in{
+				context.Background(),
+				fakeSnapshot(),
+			},
+			"",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&policyReportClientMock{
+				fakeApply: func(ctx context.Context, report *client.PolicyReport) error {
+					return errors.New("fake")
+				},
+				wantFakeApplyCalled: 1,
+			},
+			in{
+				context.Background(),
+				fakeSnapshot(),
+			},
+			"failed to apply policy report: fake",
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		receiver := tt.receiver
+		in := tt.in
+		wantErrorString := tt.wantErrorString
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			publisher, err := collector.NewPolicyReportPublisher(receiver, collector.PolicyReportScopeNamespace)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = publisher.Publish(in.first, in.second)
+			receiver.assert(t)
+
+			if err == nil {
+				if diff := cmp.Diff(wantErrorString, ""); diff != "" {
+					t.Errorf("(-want +got):\n%s", diff)
+				}
+			} else {
+				gotErrorString := err.Error()
+				if diff := cmp.Diff(wantErrorString, gotErrorString); diff != "" {
+					t.Errorf("(-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
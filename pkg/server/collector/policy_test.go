@@ -0,0 +1,153 @@
+package collector_test
+
+import (
+	"context"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/open-policy-agent/opa/ast"
+	v1 "k8s.io/api/core/v1"
+)
+
+func fakePolicySnapshot() *collector.Snapshot {
+	return &collector.Snapshot{
+		Workloads: []client.Workload{
+			{
+				Kind:      "Deployment",
+				Namespace: "production",
+				Name:      "app",
+				PodSpec: v1.PodSpec{
+					Containers: []v1.Container{{Image: "app:latest"}},
+				},
+			},
+			{
+				Kind:      "DaemonSet",
+				Namespace: "kube-system",
+				Name:      "agent",
+				PodSpec: v1.PodSpec{
+					HostNetwork: true,
+					Containers:  []v1.Container{{Image: "agent:1"}},
+				},
+			},
+		},
+		Responses: map[string]client.DockleResponse{
+			"app:latest": {
+				Target:  "app:latest",
+				Summary: client.DockleSummary{Fatal: 1},
+				Details: []client.DockleDetail{
+					{Code: "CIS-DI-0010", Level: "FATAL"},
+				},
+			},
+			"agent:1": {
+				Target: "agent:1",
+			},
+		},
+	}
+}
+
+func TestPoliciesEvaluate(t *testing.T) {
+	type want struct {
+		violations  []string
+		errorString string
+	}
+
+	tests := []struct {
+		name    string
+		modules map[string]string
+		want    want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			map[string]string{
+				"fatal.rego": `package dockle.fatal
+
+deny[msg] {
+	input.workload.namespace == "production"
+	detail := input.response.details[_]
+	detail.level == "FATAL"
+	msg := sprintf("%s is FATAL", [detail.code])
+}`,
+				"host.rego": `package dockle.host
+
+violation[{"msg": msg}] {
+	input.podSpec.hostNetwork
+	msg := sprintf("%s uses host network", [input.image])
+}`,
+			},
+			want{
+				[]string{
+					"dockle.fatal Deployment/production/app app:latest CIS-DI-0010 is FATAL",
+					"dockle.host DaemonSet/kube-system/agent agent:1 agent:1 uses host network",
+				},
+				"",
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			map[string]string{
+				"invalid.rego": `package dockle.invalid
+
+deny = true`,
+			},
+			want{
+				nil,
+				"dockle.invalid must be a set, but got bool",
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		modules := tt.modules
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			parsed := make(map[string]*ast.Module, len(modules))
+			for filename, module := range modules {
+				m, err := ast.ParseModule(filename, module)
+				if err != nil {
+					t.Fatal(err)
+				}
+				parsed[filename] = m
+			}
+			policies, err := collector.NewPolicies(context.Background(), parsed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			violations, err := policies.Evaluate(context.Background(), fakePolicySnapshot())
+
+			var got []string
+			for _, violation := range violations {
+				got = append(got, fmt.Sprintf(
+					"%s %s/%s/%s %s %s",
+					violation.Policy,
+					violation.Workload.Kind,
+					violation.Workload.Namespace,
+					violation.Workload.Name,
+					violation.Image,
+					violation.Message,
+				))
+			}
+			if diff := cmp.Diff(want.violations, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			gotErrorString := ""
+			if err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(want.errorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
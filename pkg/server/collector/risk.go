@@ -0,0 +1,301 @@
+package collector
+
+import (
+	"kube-dockle-exporter/pkg/client"
+	"sort"
+
+	"golang.org/x/xerrors"
+	v1 "k8s.io/api/core/v1"
+)
+
+// RiskConfig weighs findings into risk scores.
+// Levels and Codes give weights of findings, and the others multiply scores of workloads.
+type RiskConfig struct {
+	Levels    map[string]float64 `json:"levels,omitempty"`
+	Codes     map[string]float64 `json:"codes,omitempty"`
+	Mitigated *float64           `json:"mitigated,omitempty"`
+	Replicas  bool               `json:"replicas,omitempty"`
+	Exposure  ExposureConfig     `json:"exposure,omitempty"`
+}
+
+// ExposureConfig holds multipliers of workloads whose pods are exposed, and unset ones are 1.
+type ExposureConfig struct {
+	HostNetwork float64 `json:"hostNetwork,omitempty"`
+	HostPort    float64 `json:"hostPort,omitempty"`
+	Privileged  float64 `json:"privileged,omitempty"`
+}
+
+// DefaultRiskLevels returns weights of levels which are not configured.
+func DefaultRiskLevels() map[string]float64 {
+	return map[string]float64{
+		client.LevelFatal: 10,
+		client.LevelWarn:  5,
+		client.LevelInfo:  1,
+		client.LevelSkip:  0,
+		client.LevelPass:  0,
+	}
+}
+
+type RiskFinding struct {
+	Code   string  `json:"code"`
+	Level  string  `json:"level"`
+	Weight float64 `json:"weight"`
+}
+
+// ImageRisk is the score of an image in a cluster, which is the same among clusters running the image.
+type ImageRisk struct {
+	Cluster  string        `json:"cluster,omitempty"`
+	Image    string        `json:"image"`
+	Score    float64       `json:"score"`
+	Findings []RiskFinding `json:"findings"`
+}
+
+type WorkloadRiskFinding struct {
+	Image string `json:"image"`
+	RiskFinding
+	Mitigated bool    `json:"mitigated,omitempty"`
+	Score     float64 `json:"score"`
+}
+
+// WorkloadRisk is the sum of scores of findings multiplied by exposure, and by replicas if configured.
+type WorkloadRisk struct {
+	Workload  WorkloadRef           `json:"workload"`
+	Score     float64               `json:"score"`
+	Findings  []WorkloadRiskFinding `json:"findings"`
+	Replicas  int32                 `json:"replicas"`
+	Exposure  float64               `json:"exposure"`
+	ExposedBy []string              `json:"exposedBy,omitempty"`
+}
+
+type NamespaceWorkloadRisk struct {
+	Kind  string  `json:"kind"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+// NamespaceRisk is the sum of scores of workloads in the namespace.
+type NamespaceRisk struct {
+	Cluster   string                  `json:"cluster,omitempty"`
+	Namespace string                  `json:"namespace"`
+	Score     float64                 `json:"score"`
+	Workloads []NamespaceWorkloadRisk `json:"workloads"`
+}
+
+type RiskScores struct {
+	Images     []ImageRisk     `json:"images"`
+	Workloads  []WorkloadRisk  `json:"workloads"`
+	Namespaces []NamespaceRisk `json:"namespaces"`
+}
+
+type Risk struct {
+	levels    map[string]float64
+	codes     map[string]float64
+	mitigated float64
+	replicas  bool
+	exposure  ExposureConfig
+}
+
+// NewRisk validates the config, and uses default weights if it is nil.
+func NewRisk(config *RiskConfig) (*Risk, error) {
+	if config == nil {
+		config = &RiskConfig{}
+	}
+	risk := &Risk{
+		levels:    DefaultRiskLevels(),
+		codes:     make(map[string]float64, len(config.Codes)),
+		mitigated: 1,
+		replicas:  config.Replicas,
+		exposure:  config.Exposure,
+	}
+	for level, weight := range config.Levels {
+		if _, ok := risk.levels[level]; !ok {
+			return nil, xerrors.Errorf("risk.levels: unknown level: %s", level)
+		}
+		if weight < 0 {
+			return nil, xerrors.Errorf("risk.levels.%s: weight must not be negative", level)
+		}
+		risk.levels[level] = weight
+	}
+	for code, weight := range config.Codes {
+		if weight < 0 {
+			return nil, xerrors.Errorf("risk.codes.%s: weight must not be negative", code)
+		}
+		risk.codes[code] = weight
+	}
+	if config.Mitigated != nil {
+		if *config.Mitigated < 0 {
+			return nil, xerrors.New("risk.mitigated: multiplier must not be negative")
+		}
+		risk.mitigated = *config.Mitigated
+	}
+	for name, multiplier := range map[string]float64{
+		"hostNetwork": config.Exposure.HostNetwork,
+		"hostPort":    config.Exposure.HostPort,
+		"privileged":  config.Exposure.Privileged,
+	} {
+		if multiplier < 0 {
+			return nil, xerrors.Errorf("risk.exposure.%s: multiplier must not be negative", name)
+		}
+	}
+	return risk, nil
+}
+
+func (r *Risk) weight(code string, level string) float64 {
+	if weight, ok := r.codes[code]; ok {
+		return weight
+	}
+	return r.levels[level]
+}
+
+// exposureOf returns the product of multipliers which apply to the pod, and their names.
+func (r *Risk) exposureOf(podSpec *v1.PodSpec) (float64, []string) {
+	hostPort := false
+	privileged := false
+	for _, container := range append(append([]v1.Container{}, podSpec.InitContainers...), podSpec.Containers...) {
+		for _, port := range container.Ports {
+			if port.HostPort != 0 {
+				hostPort = true
+			}
+		}
+		if container.SecurityContext != nil && container.SecurityContext.Privileged != nil && *container.SecurityContext.Privileged {
+			privileged = true
+		}
+	}
+
+	exposure := 1.0
+	var exposedBy []string
+	for _, condition := range []struct {
+		name       string
+		exposed    bool
+		multiplier float64
+	}{
+		{"hostNetwork", podSpec.HostNetwork, r.exposure.HostNetwork},
+		{"hostPort", hostPort, r.exposure.HostPort},
+		{"privileged", privileged, r.exposure.Privileged},
+	} {
+		if !condition.exposed || condition.multiplier == 0 {
+			continue
+		}
+		exposure *= condition.multiplier
+		exposedBy = append(exposedBy, condition.name)
+	}
+	return exposure, exposedBy
+}
+
+// Score computes scores of images, workloads and namespaces, sorted from the highest.
+func (r *Risk) Score(snapshot *Snapshot) *RiskScores {
+	scores := &RiskScores{
+		Images:     []ImageRisk{},
+		Workloads:  []WorkloadRisk{},
+		Namespaces: []NamespaceRisk{},
+	}
+
+	imageClusters := snapshot.ImageClusters()
+	imageFindings := make(map[string][]RiskFinding, len(snapshot.Responses))
+	for image, response := range snapshot.Responses {
+		findings := []RiskFinding{}
+		score := 0.0
+		for _, detail := range response.Details {
+			finding := RiskFinding{
+				Code:   detail.Code,
+				Level:  detail.Level,
+				Weight: r.weight(detail.Code, detail.Level),
+			}
+			score += finding.Weight
+			findings = append(findings, finding)
+		}
+		imageFindings[response.ExtractImage()] = findings
+		clusters := imageClusters[image]
+		// Images of no workload still have a score.
+		if len(clusters) == 0 {
+			clusters = []string{""}
+		}
+		for _, cluster := range clusters {
+			scores.Images = append(scores.Images, ImageRisk{
+				Cluster:  cluster,
+				Image:    response.ExtractImage(),
+				Score:    score,
+				Findings: findings,
+			})
+		}
+	}
+
+	mitigated := make(map[string]bool, len(snapshot.Correlated))
+	for _, finding := range snapshot.Correlated {
+		mitigated[finding.Key()] = finding.Status == StatusMitigated
+	}
+	namespaces := make(map[string]*NamespaceRisk)
+	for i := range snapshot.Workloads {
+		workload := &snapshot.Workloads[i]
+		risk := WorkloadRisk{
+			Workload: refOf(workload),
+			Findings: []WorkloadRiskFinding{},
+			Replicas: workload.Replicas,
+		}
+		risk.Exposure, risk.ExposedBy = r.exposureOf(&workload.PodSpec)
+		for _, image := range workload.Images() {
+			for _, finding := range imageFindings[image] {
+				workloadFinding := WorkloadRiskFinding{
+					Image:       image,
+					RiskFinding: finding,
+					Mitigated:   mitigated[risk.Workload.key()+" "+image+" "+finding.Code],
+					Score:       finding.Weight,
+				}
+				if workloadFinding.Mitigated {
+					workloadFinding.Score *= r.mitigated
+				}
+				risk.Score += workloadFinding.Score
+				risk.Findings = append(risk.Findings, workloadFinding)
+			}
+		}
+		risk.Score *= risk.Exposure
+		// Replicas of workloads in manifests are unknown, and they count as a single pod.
+		if r.replicas && workload.Replicas > 1 {
+			risk.Score *= float64(workload.Replicas)
+		}
+		scores.Workloads = append(scores.Workloads, risk)
+
+		key := workload.Cluster + " " + workload.Namespace
+		namespace, ok := namespaces[key]
+		if !ok {
+			namespace = &NamespaceRisk{
+				Cluster:   workload.Cluster,
+				Namespace: workload.Namespace,
+				Workloads: []NamespaceWorkloadRisk{},
+			}
+			namespaces[key] = namespace
+		}
+		namespace.Score += risk.Score
+		namespace.Workloads = append(namespace.Workloads, NamespaceWorkloadRisk{
+			Kind:  workload.Kind,
+			Name:  workload.Name,
+			Score: risk.Score,
+		})
+	}
+	for _, namespace := range namespaces {
+		sort.SliceStable(namespace.Workloads, func(i, j int) bool {
+			return namespace.Workloads[i].Score > namespace.Workloads[j].Score
+		})
+		scores.Namespaces = append(scores.Namespaces, *namespace)
+	}
+
+	sort.Slice(scores.Images, func(i, j int) bool {
+		if scores.Images[i].Score != scores.Images[j].Score {
+			return scores.Images[i].Score > scores.Images[j].Score
+		}
+		return scores.Images[i].Cluster+" "+scores.Images[i].Image < scores.Images[j].Cluster+" "+scores.Images[j].Image
+	})
+	sort.SliceStable(scores.Workloads, func(i, j int) bool {
+		if scores.Workloads[i].Score != scores.Workloads[j].Score {
+			return scores.Workloads[i].Score > scores.Workloads[j].Score
+		}
+		return scores.Workloads[i].Workload.key() < scores.Workloads[j].Workload.key()
+	})
+	sort.Slice(scores.Namespaces, func(i, j int) bool {
+		if scores.Namespaces[i].Score != scores.Namespaces[j].Score {
+			return scores.Namespaces[i].Score > scores.Namespaces[j].Score
+		}
+		return scores.Namespaces[i].Cluster+" "+scores.Namespaces[i].Namespace < scores.Namespaces[j].Cluster+" "+scores.Namespaces[j].Namespace
+	})
+	return scores
+}
@@ -0,0 +1,229 @@
+package collector_test
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+)
+
+func fakeRiskSnapshot() *collector.Snapshot {
+	return &collector.Snapshot{
+		Workloads: []client.Workload{
+			{
+				Kind:      "Deployment",
+				Namespace: "payments",
+				Name:      "api",
+				Replicas:  3,
+				PodSpec: v1.PodSpec{
+					Containers: []v1.Container{{Image: "api:1"}},
+				},
+			},
+			{
+				Kind:      "DaemonSet",
+				Namespace: "payments",
+				Name:      "agent",
+				Replicas:  2,
+				PodSpec: v1.PodSpec{
+					HostNetwork: true,
+					Containers:  []v1.Container{{Image: "agent:1"}},
+				},
+			},
+			{
+				Kind:      "Deployment",
+				Namespace: "search",
+				Name:      "web",
+				PodSpec: v1.PodSpec{
+					Containers: []v1.Container{{Image: "api:1"}},
+				},
+			},
+		},
+		Responses: map[string]client.DockleResponse{
+			"api:1": {
+				Target: "api:1",
+				Details: []client.DockleDetail{
+					{Code: "CIS-DI-0001", Level: "WARN"},
+					{Code: "DKL-DI-0006", Level: "WARN"},
+				},
+			},
+			"agent:1": {
+				Target: "agent:1",
+				Details: []client.DockleDetail{
+					{Code: "CIS-DI-0005", Level: "INFO"},
+				},
+			},
+		},
+		Correlated: []collector.CorrelatedFinding{
+			{
+				Workload: collector.WorkloadRef{Kind: "Deployment", Namespace: "payments", Name: "api"},
+				Finding:  collector.Finding{Image: "api:1", Code: "CIS-DI-0001", Level: "WARN"},
+				Status:   collector.StatusMitigated,
+			},
+		},
+	}
+}
+
+func TestRiskScore(t *testing.T) {
+	type want struct {
+		images     map[string]float64
+		workloads  map[string]float64
+		namespaces map[string]float64
+	}
+
+	mitigated := 0.5
+
+	tests := []struct {
+		name   string
+		config *collector.RiskConfig
+		want   want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			nil,
+			want{
+				map[string]float64{"api:1": 10, "agent:1": 1},
+				map[string]float64{"payments/api": 10, "payments/agent": 1, "search/web": 10},
+				map[string]float64{"payments": 11, "search": 10},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&collector.RiskConfig{
+				Levels:    map[string]float64{"INFO": 2},
+				Codes:     map[string]float64{"DKL-DI-0006": 8},
+				Mitigated: &mitigated,
+				Replicas:  true,
+				Exposure: collector.ExposureConfig{
+					HostNetwork: 3,
+				},
+			},
+			want{
+				map[string]float64{"api:1": 13, "agent:1": 2},
+				// (5 * 0.5 + 8) * 3 replicas, 2 * 3 for host network * 2 replicas, and 5 + 8.
+				map[string]float64{"payments/api": 31.5, "payments/agent": 12, "search/web": 13},
+				map[string]float64{"payments": 43.5, "search": 13},
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		config := tt.config
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			risk, err := collector.NewRisk(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			scores := risk.Score(fakeRiskSnapshot())
+
+			images := make(map[string]float64)
+			for _, image := range scores.Images {
+				images[image.Image] = image.Score
+			}
+			if diff := cmp.Diff(want.images, images); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			workloads := make(map[string]float64)
+			for _, workload := range scores.Workloads {
+				workloads[workload.Workload.Namespace+"/"+workload.Workload.Name] = workload.Score
+			}
+			if diff := cmp.Diff(want.workloads, workloads); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			namespaces := make(map[string]float64)
+			for _, namespace := range scores.Namespaces {
+				namespaces[namespace.Namespace] = namespace.Score
+			}
+			if diff := cmp.Diff(want.namespaces, namespaces); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRiskScoreImageClusters(t *testing.T) {
+	snapshot := &collector.Snapshot{
+		Workloads: []client.Workload{
+			{Cluster: "b", Kind: "Deployment", Namespace: "fake", Name: "fake", PodSpec: v1.PodSpec{Containers: []v1.Container{{Image: "fake"}}}},
+			{Cluster: "a", Kind: "Deployment", Namespace: "fake", Name: "fake", PodSpec: v1.PodSpec{Containers: []v1.Container{{Image: "fake"}}}},
+		},
+		Responses: map[string]client.DockleResponse{
+			"fake": {Target: "fake", Details: []client.DockleDetail{{Code: "CIS-DI-0001", Level: "WARN"}}},
+		},
+	}
+	risk, err := collector.NewRisk(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, image := range risk.Score(snapshot).Images {
+		got = append(got, fmt.Sprintf("%s %s %g", image.Cluster, image.Image, image.Score))
+	}
+	if diff := cmp.Diff([]string{"a fake 5", "b fake 5"}, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestNewRisk(t *testing.T) {
+	negative := -1.0
+
+	tests := []struct {
+		name            string
+		config          *collector.RiskConfig
+		wantErrorString string
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&collector.RiskConfig{Levels: map[string]float64{"CRITICAL": 20}},
+			"risk.levels: unknown level: CRITICAL",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&collector.RiskConfig{Codes: map[string]float64{"CIS-DI-0001": -1}},
+			"risk.codes.CIS-DI-0001: weight must not be negative",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&collector.RiskConfig{Mitigated: &negative},
+			"risk.mitigated: multiplier must not be negative",
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		config := tt.config
+		wantErrorString := tt.wantErrorString
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			gotErrorString := ""
+			if _, err := collector.NewRisk(config); err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(wantErrorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
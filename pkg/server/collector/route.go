@@ -0,0 +1,520 @@
+package collector
+
+import (
+	"context"
+	"io/ioutil"
+	"kube-dockle-exporter/pkg/client"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	GroupByCluster   = "cluster"
+	GroupByNamespace = "namespace"
+	GroupByWorkload  = "workload"
+	GroupByImage     = "image"
+	GroupByRegistry  = "registry"
+	GroupByCode      = "code"
+	GroupByLevel     = "level"
+)
+
+// nolint:gochecknoglobals
+var defaultGroupBy = []string{GroupByCluster, GroupByNamespace, GroupByWorkload, GroupByImage, GroupByCode}
+
+type RoutingConfig struct {
+	Sinks  []SinkConfig  `json:"sinks"`
+	Routes []RouteConfig `json:"routes"`
+}
+
+type SinkConfig struct {
+	Name    string             `json:"name"`
+	Slack   *SlackSinkConfig   `json:"slack,omitempty"`
+	Webhook *WebhookSinkConfig `json:"webhook,omitempty"`
+}
+
+type SlackSinkConfig struct {
+	WebhookURL string  `json:"webhookURL"`
+	RateLimit  float64 `json:"rateLimit,omitempty"`
+	Retries    int     `json:"retries,omitempty"`
+}
+
+type WebhookSinkConfig struct {
+	URL        string `json:"url"`
+	Mode       string `json:"mode,omitempty"`
+	SecretFile string `json:"secretFile,omitempty"`
+	Source     string `json:"source,omitempty"`
+	Retries    int    `json:"retries,omitempty"`
+}
+
+type RouteConfig struct {
+	Name           string             `json:"name"`
+	Match          RouteMatch         `json:"match"`
+	Sinks          []string           `json:"sinks"`
+	GroupBy        []string           `json:"groupBy,omitempty"`
+	RepeatInterval metaV1.Duration    `json:"repeatInterval,omitempty"`
+	QuietHours     []QuietHoursConfig `json:"quietHours,omitempty"`
+	Continue       bool               `json:"continue,omitempty"`
+}
+
+// RouteMatch matches findings which satisfy all of the given conditions, and each list is satisfied by any of its items.
+type RouteMatch struct {
+	Clusters        []string          `json:"clusters,omitempty"`
+	Namespaces      []string          `json:"namespaces,omitempty"`
+	NamespaceLabels map[string]string `json:"namespaceLabels,omitempty"`
+	Owners          map[string]string `json:"owners,omitempty"`
+	Registries      []string          `json:"registries,omitempty"`
+	Codes           []string          `json:"codes,omitempty"`
+	Levels          []string          `json:"levels,omitempty"`
+	MinLevel        string            `json:"minLevel,omitempty"`
+}
+
+type QuietHoursConfig struct {
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	TimeZone string   `json:"timeZone,omitempty"`
+	Weekdays []string `json:"weekdays,omitempty"`
+}
+
+func LoadRoutingConfig(path string) (*RoutingConfig, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("could not read %s: %w", path, err)
+	}
+	var config RoutingConfig
+	if err := yaml.UnmarshalStrict(content, &config); err != nil {
+		return nil, xerrors.Errorf("could not parse %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// ValidateRoutingConfig validates the config without setting up sinks.
+func ValidateRoutingConfig(config *RoutingConfig) error {
+	sinks := make(map[string]INotifier, len(config.Sinks))
+	for _, sink := range config.Sinks {
+		if _, ok := sinks[sink.Name]; ok {
+			return xerrors.Errorf("duplicate sink: %s", sink.Name)
+		}
+		switch {
+		case sink.Slack != nil:
+			if sink.Slack.WebhookURL == "" {
+				return xerrors.Errorf("sink %s has no webhookURL", sink.Name)
+			}
+		case sink.Webhook != nil:
+			if sink.Webhook.URL == "" {
+				return xerrors.Errorf("sink %s has no url", sink.Name)
+			}
+			switch sink.Webhook.Mode {
+			case "", client.WebhookModeStructured, client.WebhookModeBinary:
+			default:
+				return xerrors.Errorf("unknown mode %s of sink %s", sink.Webhook.Mode, sink.Name)
+			}
+		default:
+			return xerrors.Errorf("sink %s has no destination", sink.Name)
+		}
+		sinks[sink.Name] = nil
+	}
+	_, err := NewRouter(config, sinks, nil)
+	return err
+}
+
+func (m *RouteMatch) validate() error {
+	owners := make([]string, 0, len(m.Owners))
+	for _, pattern := range m.Owners {
+		owners = append(owners, pattern)
+	}
+	for _, patterns := range [][]string{m.Clusters, m.Namespaces, owners, m.Registries, m.Codes} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return xerrors.Errorf("invalid pattern %s: %w", pattern, err)
+			}
+		}
+	}
+	for _, level := range append(m.Levels, m.MinLevel) {
+		if level != "" && client.LevelSeverity(level) == 0 && level != client.LevelPass {
+			return xerrors.Errorf("unknown level: %s", level)
+		}
+	}
+	return nil
+}
+
+func matchAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *RouteMatch) matches(finding *WorkloadFinding) bool {
+	if !matchAny(m.Clusters, finding.Workload.Cluster) {
+		return false
+	}
+	if !matchAny(m.Namespaces, finding.Workload.Namespace) {
+		return false
+	}
+	for key, value := range m.NamespaceLabels {
+		if actual, ok := finding.Workload.NamespaceLabels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	// Owners are known for namespaces of all clusters, and keys which are not allowed never match.
+	for key, pattern := range m.Owners {
+		actual, ok := finding.Workload.Owner[key]
+		if !ok {
+			return false
+		}
+		if matched, _ := path.Match(pattern, actual); !matched {
+			return false
+		}
+	}
+	reference := client.ParseImageReference(finding.Image)
+	if !matchAny(m.Registries, reference.Registry) {
+		return false
+	}
+	if !matchAny(m.Codes, finding.Code) {
+		return false
+	}
+	if len(m.Levels) > 0 {
+		matched := false
+		for _, level := range m.Levels {
+			if finding.Level == level {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if m.MinLevel != "" && !finding.AtLeast(m.MinLevel) {
+		return false
+	}
+	return true
+}
+
+type QuietHours struct {
+	start    time.Duration
+	end      time.Duration
+	location *time.Location
+	weekdays map[time.Weekday]bool
+}
+
+func parseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, xerrors.Errorf("invalid time %s: %w", clock, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func NewQuietHours(config QuietHoursConfig) (*QuietHours, error) {
+	start, err := parseClock(config.Start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClock(config.End)
+	if err != nil {
+		return nil, err
+	}
+	location := time.UTC
+	if config.TimeZone != "" {
+		location, err = time.LoadLocation(config.TimeZone)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid time zone %s: %w", config.TimeZone, err)
+		}
+	}
+	var weekdays map[time.Weekday]bool
+	if len(config.Weekdays) > 0 {
+		weekdays = make(map[time.Weekday]bool)
+		for _, name := range config.Weekdays {
+			matched := false
+			for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+				if strings.EqualFold(name, weekday.String()) || strings.EqualFold(name, weekday.String()[:3]) {
+					weekdays[weekday] = true
+					matched = true
+				}
+			}
+			if !matched {
+				return nil, xerrors.Errorf("invalid weekday: %s", name)
+			}
+		}
+	}
+	return &QuietHours{
+		start:    start,
+		end:      end,
+		location: location,
+		weekdays: weekdays,
+	}, nil
+}
+
+// Contains reports whether t is in the window, which spans midnight if it ends before it starts.
+// Weekdays are those on which windows start.
+func (q *QuietHours) Contains(t time.Time) bool {
+	t = t.In(q.location)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, q.location)
+	clock := t.Sub(midnight)
+	startDay := t.Weekday()
+	switch {
+	case q.start <= q.end:
+		if clock < q.start || clock >= q.end {
+			return false
+		}
+	case clock >= q.start:
+	case clock < q.end:
+		startDay = (startDay + 6) % 7
+	default:
+		return false
+	}
+	return q.weekdays == nil || q.weekdays[startDay]
+}
+
+type route struct {
+	name           string
+	match          RouteMatch
+	sinks          []INotifier
+	sinkNames      []string
+	groupBy        []string
+	repeatInterval time.Duration
+	quietHours     []*QuietHours
+	continued      bool
+}
+
+func (r *route) quiet(t time.Time) bool {
+	for _, q := range r.quietHours {
+		if q.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *route) groupKey(finding *WorkloadFinding) string {
+	values := []string{r.name}
+	for _, label := range r.groupBy {
+		switch label {
+		case GroupByCluster:
+			values = append(values, finding.Workload.Cluster)
+		case GroupByNamespace:
+			values = append(values, finding.Workload.Namespace)
+		case GroupByWorkload:
+			values = append(values, finding.Workload.Kind+"/"+finding.Workload.Name)
+		case GroupByImage:
+			values = append(values, finding.Image)
+		case GroupByRegistry:
+			reference := client.ParseImageReference(finding.Image)
+			values = append(values, reference.Registry)
+		case GroupByCode:
+			values = append(values, finding.Code)
+		case GroupByLevel:
+			values = append(values, finding.Level)
+		}
+	}
+	return strings.Join(values, " ")
+}
+
+// Router sends findings to sinks of the first matching route, and of following routes as long as they continue.
+// Findings are not sent again in the same group within the repeat interval, and findings in quiet hours are deferred
+// until the end. Findings which a sink failed to receive are sent to it again on the next call, and not to the others.
+type Router struct {
+	routes    []*route
+	store     INotificationStore
+	notified  map[string]time.Time
+	loaded    bool
+	deferred  map[*route][]WorkloadFinding
+	failed    map[string][]WorkloadFinding
+	maxRepeat time.Duration
+	now       func() time.Time
+}
+
+func NewRouter(config *RoutingConfig, sinks map[string]INotifier, store INotificationStore) (*Router, error) {
+	router := &Router{
+		store:    store,
+		notified: make(map[string]time.Time),
+		deferred: make(map[*route][]WorkloadFinding),
+		failed:   make(map[string][]WorkloadFinding),
+		now:      time.Now,
+	}
+	for i, config := range config.Routes {
+		r := &route{
+			name:           config.Name,
+			match:          config.Match,
+			groupBy:        config.GroupBy,
+			repeatInterval: config.RepeatInterval.Duration,
+			continued:      config.Continue,
+		}
+		if r.name == "" {
+			r.name = strconv.Itoa(i)
+		}
+		if err := r.match.validate(); err != nil {
+			return nil, xerrors.Errorf("invalid match of route %s: %w", r.name, err)
+		}
+		for _, name := range config.Sinks {
+			sink, ok := sinks[name]
+			if !ok {
+				return nil, xerrors.Errorf("unknown sink %s in route %s", name, r.name)
+			}
+			r.sinks = append(r.sinks, sink)
+			r.sinkNames = append(r.sinkNames, name)
+		}
+		if len(r.groupBy) == 0 {
+			r.groupBy = defaultGroupBy
+		}
+		for _, label := range r.groupBy {
+			switch label {
+			case GroupByCluster, GroupByNamespace, GroupByWorkload, GroupByImage, GroupByRegistry, GroupByCode, GroupByLevel:
+			default:
+				return nil, xerrors.Errorf("unknown label %s to group by in route %s", label, r.name)
+			}
+		}
+		for _, quietHoursConfig := range config.QuietHours {
+			q, err := NewQuietHours(quietHoursConfig)
+			if err != nil {
+				return nil, xerrors.Errorf("invalid quiet hours of route %s: %w", r.name, err)
+			}
+			r.quietHours = append(r.quietHours, q)
+		}
+		if r.repeatInterval > router.maxRepeat {
+			router.maxRepeat = r.repeatInterval
+		}
+		router.routes = append(router.routes, r)
+	}
+	return router, nil
+}
+
+// Inherit takes over notification times, and findings deferred by routes or failed to be sent to sinks of the same
+// names, from the router which this one replaces.
+func (r *Router) Inherit(previous *Router) {
+	r.notified = previous.notified
+	r.loaded = previous.loaded
+	for _, route := range r.routes {
+		for previousRoute, findings := range previous.deferred {
+			if previousRoute.name == route.name {
+				r.deferred[route] = findings
+			}
+		}
+		for _, name := range route.sinkNames {
+			if findings, ok := previous.failed[route.name+" "+name]; ok {
+				r.failed[route.name+" "+name] = findings
+			}
+		}
+	}
+}
+
+// matchingRoutes returns the first matching route and following ones as long as they continue.
+func (r *Router) matchingRoutes(finding *WorkloadFinding) []*route {
+	var routes []*route
+	for _, route := range r.routes {
+		if !route.match.matches(finding) {
+			continue
+		}
+		routes = append(routes, route)
+		if !route.continued {
+			break
+		}
+	}
+	return routes
+}
+
+func (r *Router) Notify(ctx context.Context, findings []WorkloadFinding) error {
+	var errs []error
+	if !r.loaded && r.store != nil {
+		notified, err := r.store.NotificationTimes()
+		if err != nil {
+			return xerrors.Errorf("failed to load notification times: %w", err)
+		}
+		r.notified = notified
+	}
+	r.loaded = true
+
+	routed := make(map[*route][]WorkloadFinding)
+	for _, finding := range findings {
+		for _, route := range r.matchingRoutes(&finding) {
+			routed[route] = append(routed[route], finding)
+		}
+	}
+
+	now := r.now()
+	for _, route := range r.routes {
+		pending := append(r.deferred[route], routed[route]...)
+		retrying := false
+		for _, name := range route.sinkNames {
+			if len(r.failed[route.name+" "+name]) > 0 {
+				retrying = true
+			}
+		}
+		if len(route.sinks) == 0 || len(pending) == 0 && !retrying {
+			delete(r.deferred, route)
+			continue
+		}
+		if route.quiet(now) {
+			r.deferred[route] = pending
+			continue
+		}
+		delete(r.deferred, route)
+
+		// Each finding is sent once in its group within the repeat interval, so that groups which gain findings are
+		// sent again with the new ones.
+		var fresh []WorkloadFinding
+		for _, finding := range pending {
+			key := route.groupKey(&finding) + " " + finding.Key()
+			if last, ok := r.notified[key]; ok && route.repeatInterval > 0 && now.Sub(last) < route.repeatInterval {
+				continue
+			}
+			fresh = append(fresh, finding)
+			if route.repeatInterval > 0 {
+				r.notified[key] = now
+			}
+		}
+		for i, sink := range route.sinks {
+			failedKey := route.name + " " + route.sinkNames[i]
+			var keys []string
+			groups := make(map[string][]WorkloadFinding)
+			for _, finding := range append(append([]WorkloadFinding{}, r.failed[failedKey]...), fresh...) {
+				key := route.groupKey(&finding)
+				if _, ok := groups[key]; !ok {
+					keys = append(keys, key)
+				}
+				groups[key] = append(groups[key], finding)
+			}
+			var failed []WorkloadFinding
+			for _, key := range keys {
+				if err := sink.Notify(ctx, groups[key]); err != nil {
+					errs = append(errs, xerrors.Errorf("failed to notify sink %s of route %s: %w", route.sinkNames[i], route.name, err))
+					failed = append(failed, groups[key]...)
+				}
+			}
+			if len(failed) > 0 {
+				r.failed[failedKey] = failed
+			} else {
+				delete(r.failed, failedKey)
+			}
+		}
+	}
+
+	for key, last := range r.notified {
+		if now.Sub(last) >= r.maxRepeat {
+			delete(r.notified, key)
+		}
+	}
+	if r.store != nil {
+		if err := r.store.SetNotificationTimes(r.notified); err != nil {
+			errs = append(errs, xerrors.Errorf("failed to save notification times: %w", err))
+		}
+	}
+
+	// Findings failed to be sent are kept by the router, and must not be given again.
+	if len(errs) > 0 {
+		return &DeliveryError{Err: xerrors.Errorf("failed to route %d notifications: %w", len(errs), errs[0])}
+	}
+	return nil
+}
@@ -0,0 +1,621 @@
+package collector_test
+
+import (
+	"context"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/xerrors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func fakeWorkloadFinding(namespace string, image string, code string, level string) collector.WorkloadFinding {
+	return collector.WorkloadFinding{
+		Workload: client.Workload{
+			Kind:      "Deployment",
+			Namespace: namespace,
+			Name:      "fake",
+		},
+		Finding: collector.Finding{
+			Image: image,
+			Code:  code,
+			Level: level,
+		},
+	}
+}
+
+func TestRouterNotify(t *testing.T) {
+	payments := fakeWorkloadFinding("payments-api", "fake", "CIS-DI-0001", "WARN")
+	fatal := fakeWorkloadFinding("default", "gcr.io/fake", "CIS-DI-0005", "FATAL")
+	info := fakeWorkloadFinding("payments-api", "fake", "DKL-LI-0003", "INFO")
+	labelled := fakeWorkloadFinding("labelled", "fake", "CIS-DI-0001", "WARN")
+	labelled.Workload.Cluster = "remote"
+	labelled.Workload.Remote = true
+	labelled.Workload.NamespaceLabels = map[string]string{"team": "payments"}
+	owned := fakeWorkloadFinding("owned", "fake", "CIS-DI-0001", "WARN")
+	owned.Workload.Cluster = "remote"
+	owned.Workload.Remote = true
+	owned.Workload.Owner = map[string]string{"team": "payments", "slack-channel": "#payments"}
+
+	type want struct {
+		team   [][]string
+		oncall [][]string
+	}
+
+	tests := []struct {
+		name   string
+		routes []collector.RouteConfig
+		in     [][]collector.WorkloadFinding
+		want   want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]collector.RouteConfig{
+				{
+					Match: collector.RouteMatch{Levels: []string{"INFO"}},
+				},
+				{
+					Match:    collector.RouteMatch{MinLevel: "FATAL"},
+					Sinks:    []string{"oncall"},
+					Continue: true,
+				},
+				{
+					Match: collector.RouteMatch{Namespaces: []string{"payments-*"}},
+					Sinks: []string{"team"},
+				},
+				{
+					Match: collector.RouteMatch{NamespaceLabels: map[string]string{"team": "payments"}},
+					Sinks: []string{"team"},
+				},
+			},
+			[][]collector.WorkloadFinding{
+				{payments, fatal, info, labelled},
+			},
+			want{
+				[][]string{
+					{payments.Key()},
+					{labelled.Key()},
+				},
+				[][]string{
+					{fatal.Key()},
+				},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]collector.RouteConfig{
+				{
+					Match: collector.RouteMatch{Owners: map[string]string{"team": "payments", "slack-channel": "#*"}},
+					Sinks: []string{"team"},
+				},
+				{
+					Match: collector.RouteMatch{Owners: map[string]string{"owner": "*"}},
+					Sinks: []string{"team"},
+				},
+				{
+					Match: collector.RouteMatch{},
+					Sinks: []string{"oncall"},
+				},
+			},
+			[][]collector.WorkloadFinding{
+				{owned, payments},
+			},
+			want{
+				[][]string{
+					{owned.Key()},
+				},
+				[][]string{
+					{payments.Key()},
+				},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]collector.RouteConfig{
+				{
+					Match: collector.RouteMatch{Registries: []string{"docker.io"}},
+					Sinks: []string{"team"},
+				},
+				{
+					Match: collector.RouteMatch{Codes: []string{"CIS-*"}},
+					Sinks: []string{"oncall"},
+				},
+			},
+			[][]collector.WorkloadFinding{
+				{payments, fatal},
+			},
+			want{
+				[][]string{
+					{payments.Key()},
+				},
+				[][]string{
+					{fatal.Key()},
+				},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]collector.RouteConfig{
+				{
+					Match:          collector.RouteMatch{},
+					Sinks:          []string{"team"},
+					GroupBy:        []string{"namespace"},
+					RepeatInterval: metaV1.Duration{Duration: time.Hour},
+				},
+			},
+			[][]collector.WorkloadFinding{
+				{payments, info},
+				{info},
+				{fatal},
+			},
+			want{
+				[][]string{
+					{payments.Key(), info.Key()},
+					{fatal.Key()},
+				},
+				nil,
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]collector.RouteConfig{
+				{
+					Match: collector.RouteMatch{},
+					Sinks: []string{"team"},
+					// Together the windows cover whole days.
+					QuietHours: []collector.QuietHoursConfig{
+						{Start: "00:00", End: "12:00"},
+						{Start: "12:00", End: "00:00"},
+					},
+				},
+			},
+			[][]collector.WorkloadFinding{
+				{payments},
+				{},
+			},
+			want{
+				nil,
+				nil,
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		routes := tt.routes
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			team := &notifierMock{}
+			oncall := &notifierMock{}
+			router, err := collector.NewRouter(
+				&collector.RoutingConfig{
+					Routes: routes,
+				},
+				map[string]collector.INotifier{
+					"team":   team,
+					"oncall": oncall,
+				},
+				&notificationStoreMock{},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, findings := range in {
+				if err := router.Notify(context.Background(), findings); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := cmp.Diff(want.team, team.notified); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(want.oncall, oncall.notified); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRouterNotifyPersistsRepeatInterval(t *testing.T) {
+	finding := fakeWorkloadFinding("default", "fake", "CIS-DI-0001", "WARN")
+	config := &collector.RoutingConfig{
+		Routes: []collector.RouteConfig{
+			{
+				Name:           "default",
+				Sinks:          []string{"team"},
+				RepeatInterval: metaV1.Duration{Duration: time.Hour},
+			},
+		},
+	}
+	store := &notificationStoreMock{}
+	team := &notifierMock{}
+	for i := 0; i < 2; i++ {
+		// Routers are recreated as if the exporter restarted.
+		router, err := collector.NewRouter(config, map[string]collector.INotifier{"team": team}, store)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := router.Notify(context.Background(), []collector.WorkloadFinding{finding}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if diff := cmp.Diff([][]string{{finding.Key()}}, team.notified); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestRouterNotifyRepeatInterval(t *testing.T) {
+	finding := fakeWorkloadFinding("default", "fake", "CIS-DI-0001", "WARN")
+	added := fakeWorkloadFinding("default", "fake", "CIS-DI-0005", "WARN")
+	team := &notifierMock{}
+	router, err := collector.NewRouter(&collector.RoutingConfig{
+		Routes: []collector.RouteConfig{
+			{
+				Name:           "default",
+				Sinks:          []string{"team"},
+				GroupBy:        []string{collector.GroupByNamespace},
+				RepeatInterval: metaV1.Duration{Duration: time.Hour},
+			},
+		},
+	}, map[string]collector.INotifier{"team": team}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, findings := range [][]collector.WorkloadFinding{{finding}, {added}, {finding, added}} {
+		if err := router.Notify(context.Background(), findings); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The group which gains a finding within the repeat interval is sent again with the new one only.
+	if diff := cmp.Diff([][]string{{finding.Key()}, {added.Key()}}, team.notified); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestRouterNotifyFailedSink(t *testing.T) {
+	team := &notifierMock{err: xerrors.New("fake")}
+	oncall := &notifierMock{}
+	router, err := collector.NewRouter(&collector.RoutingConfig{
+		Routes: []collector.RouteConfig{
+			{Name: "default", Sinks: []string{"team", "oncall"}},
+		},
+	}, map[string]collector.INotifier{"team": team, "oncall": oncall}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publisher := collector.NewNotificationPublisher("")
+	publisher.AddNotifier(router)
+	if err := publisher.Publish(context.Background(), fakeSnapshot()); err != nil {
+		t.Fatal(err)
+	}
+	withFinding := fakeSnapshot()
+	response := withFinding.Responses["fake"]
+	response.Details = append(response.Details, client.DockleDetail{Code: "CIS-DI-0005", Level: "WARN"})
+	withFinding.Responses["fake"] = response
+	err = publisher.Publish(context.Background(), withFinding)
+	var deliveryErr *collector.DeliveryError
+	if !xerrors.As(err, &deliveryErr) {
+		t.Errorf("want DeliveryError, but got %v", err)
+	}
+
+	// Only the sink which failed receives the finding again.
+	team.err = nil
+	if err := publisher.Publish(context.Background(), withFinding); err != nil {
+		t.Fatal(err)
+	}
+	if err := publisher.Publish(context.Background(), withFinding); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"Deployment/fake/fake fake CIS-DI-0005"}}
+	if diff := cmp.Diff(want, team.notified); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want, oncall.notified); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestRouterInherit(t *testing.T) {
+	finding := fakeWorkloadFinding("default", "fake", "CIS-DI-0001", "WARN")
+	config := &collector.RoutingConfig{
+		Routes: []collector.RouteConfig{
+			{
+				Name:           "default",
+				Sinks:          []string{"team"},
+				RepeatInterval: metaV1.Duration{Duration: time.Hour},
+			},
+		},
+	}
+	team := &notifierMock{}
+	sinks := map[string]collector.INotifier{"team": team}
+	router, err := collector.NewRouter(config, sinks, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier := collector.NewReloadableNotifier(router)
+	if err := notifier.Notify(context.Background(), []collector.WorkloadFinding{finding}); err != nil {
+		t.Fatal(err)
+	}
+	// The reloaded router does not send the group again within the repeat interval.
+	reloaded, err := collector.NewRouter(config, sinks, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier.Replace(func(current collector.INotifier) collector.INotifier {
+		reloaded.Inherit(current.(*collector.Router))
+		return reloaded
+	})
+	if err := notifier.Notify(context.Background(), []collector.WorkloadFinding{finding}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([][]string{{finding.Key()}}, team.notified); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestReloadableNotifierDisabled(t *testing.T) {
+	finding := fakeWorkloadFinding("default", "fake", "CIS-DI-0001", "WARN")
+	notifier := collector.NewReloadableNotifier(nil)
+	if err := notifier.Notify(context.Background(), []collector.WorkloadFinding{finding}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Routing enabled by reload receives findings from then on.
+	team := &notifierMock{}
+	router, err := collector.NewRouter(&collector.RoutingConfig{
+		Routes: []collector.RouteConfig{{Name: "default", Sinks: []string{"team"}}},
+	}, map[string]collector.INotifier{"team": team}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier.Replace(func(current collector.INotifier) collector.INotifier {
+		return router
+	})
+	if err := notifier.Notify(context.Background(), []collector.WorkloadFinding{finding}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([][]string{{finding.Key()}}, team.notified); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestValidateRoutingConfig(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          *collector.RoutingConfig
+		wantErrorString string
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&collector.RoutingConfig{
+				Sinks: []collector.SinkConfig{
+					{Name: "team", Slack: &collector.SlackSinkConfig{WebhookURL: "https://example.com"}},
+				},
+				Routes: []collector.RouteConfig{
+					{Sinks: []string{"team"}, Match: collector.RouteMatch{NamespaceLabels: map[string]string{"team": "fake"}}},
+				},
+			},
+			"",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&collector.RoutingConfig{
+				Sinks: []collector.SinkConfig{
+					{Name: "team", Webhook: &collector.WebhookSinkConfig{URL: "https://example.com", Mode: "fake"}},
+				},
+			},
+			"unknown mode fake of sink team",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&collector.RoutingConfig{
+				Sinks: []collector.SinkConfig{
+					{Name: "team"},
+				},
+			},
+			"sink team has no destination",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			&collector.RoutingConfig{
+				Routes: []collector.RouteConfig{
+					{Sinks: []string{"team"}},
+				},
+			},
+			"unknown sink team in route 0",
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		config := tt.config
+		wantErrorString := tt.wantErrorString
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			gotErrorString := ""
+			if err := collector.ValidateRoutingConfig(config); err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(wantErrorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewRouter(t *testing.T) {
+	tests := []struct {
+		name  string
+		route collector.RouteConfig
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.RouteConfig{Sinks: []string{"unknown"}},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.RouteConfig{GroupBy: []string{"unknown"}},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.RouteConfig{Match: collector.RouteMatch{Namespaces: []string{"["}}},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.RouteConfig{Match: collector.RouteMatch{MinLevel: "CRITICAL"}},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.RouteConfig{QuietHours: []collector.QuietHoursConfig{{Start: "25:00", End: "07:00"}}},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		route := tt.route
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := collector.NewRouter(&collector.RoutingConfig{
+				Routes: []collector.RouteConfig{route},
+			}, map[string]collector.INotifier{}, nil); err == nil {
+				t.Error("want error, but got nil")
+			}
+		})
+	}
+}
+
+func TestQuietHoursContains(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	tests := []struct {
+		name   string
+		config collector.QuietHoursConfig
+		in     time.Time
+		want   bool
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.QuietHoursConfig{Start: "22:00", End: "07:00"},
+			time.Date(2020, 1, 1, 23, 0, 0, 0, time.UTC),
+			true,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.QuietHoursConfig{Start: "22:00", End: "07:00"},
+			time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+			false,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.QuietHoursConfig{Start: "22:00", End: "07:00", TimeZone: "Asia/Tokyo"},
+			time.Date(2020, 1, 1, 23, 0, 0, 0, tokyo),
+			true,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.QuietHoursConfig{Start: "22:00", End: "07:00", TimeZone: "Asia/Tokyo"},
+			time.Date(2020, 1, 1, 23, 0, 0, 0, time.UTC),
+			false,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			// 2020-01-04 is Saturday, and the window started on Friday.
+			collector.QuietHoursConfig{Start: "22:00", End: "07:00", Weekdays: []string{"Fri"}},
+			time.Date(2020, 1, 4, 6, 0, 0, 0, time.UTC),
+			true,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.QuietHoursConfig{Start: "09:00", End: "18:00", Weekdays: []string{"Saturday", "Sunday"}},
+			time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC),
+			false,
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		config := tt.config
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			quietHours, err := collector.NewQuietHours(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want, quietHours.Contains(in)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package collector
+
+import (
+	"kube-dockle-exporter/pkg/client"
+	"path"
+
+	"golang.org/x/xerrors"
+)
+
+// SeverityConfig remaps the level of findings whose code matches Code in namespaces matching Namespaces and NamespaceLabels.
+// Findings of all namespaces are remapped if neither is given.
+type SeverityConfig struct {
+	Code            string            `json:"code"`
+	Namespaces      []string          `json:"namespaces,omitempty"`
+	NamespaceLabels map[string]string `json:"namespaceLabels,omitempty"`
+	Level           string            `json:"level"`
+}
+
+func (s *SeverityConfig) matches(workload *client.Workload, code string) bool {
+	if ok, _ := path.Match(s.Code, code); !ok {
+		return false
+	}
+	if !matchAny(s.Namespaces, workload.Namespace) {
+		return false
+	}
+	for key, value := range s.NamespaceLabels {
+		if actual, ok := workload.NamespaceLabels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Severities remaps levels of findings by the first matching config in order.
+type Severities struct {
+	configs    []SeverityConfig
+	usesLabels bool
+}
+
+// NewSeverities validates configs, which match labels of namespaces which workloads hold.
+func NewSeverities(configs []SeverityConfig) (*Severities, error) {
+	severities := &Severities{
+		configs: configs,
+	}
+	for i, config := range configs {
+		if config.Code == "" {
+			return nil, xerrors.Errorf("severities[%d]: code is required", i)
+		}
+		if client.LevelSeverity(config.Level) == 0 && config.Level != client.LevelPass {
+			return nil, xerrors.Errorf("severities[%d]: unknown level: %s", i, config.Level)
+		}
+		for _, pattern := range append([]string{config.Code}, config.Namespaces...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, xerrors.Errorf("severities[%d]: invalid pattern %s: %w", i, pattern, err)
+			}
+		}
+		if len(config.NamespaceLabels) > 0 {
+			severities.usesLabels = true
+		}
+	}
+	return severities, nil
+}
+
+// level returns the level of the code on the workload, or the given one if no config matches.
+func (s *Severities) level(workload *client.Workload, code string, level string) string {
+	for _, config := range s.configs {
+		if config.matches(workload, code) {
+			return config.Level
+		}
+	}
+	return level
+}
+
+// Apply remaps levels of details keeping levels given by dockle, and updates summaries.
+// Images running in several namespaces get the most severe of the levels remapped in each of them.
+func (s *Severities) Apply(snapshot *Snapshot) {
+	if len(s.configs) == 0 {
+		return
+	}
+	for image, response := range snapshot.Responses {
+		workloads := snapshot.WorkloadsOf(image)
+		if len(workloads) == 0 {
+			continue
+		}
+		details := make([]client.DockleDetail, 0, len(response.Details))
+		for _, detail := range response.Details {
+			original := detail.DockleLevel()
+			level := ""
+			for i := range workloads {
+				remapped := s.level(&workloads[i], detail.Code, original)
+				if level == "" || client.LevelSeverity(remapped) > client.LevelSeverity(level) {
+					level = remapped
+				}
+			}
+			if level != detail.Level {
+				uncount(&response.Summary, detail.Level)
+				count(&response.Summary, level, 1)
+				detail.Level = level
+			}
+			if level != original {
+				detail.OriginalLevel = original
+			} else {
+				detail.OriginalLevel = ""
+			}
+			details = append(details, detail)
+		}
+		response.Details = details
+		snapshot.Responses[image] = response
+	}
+}
@@ -0,0 +1,194 @@
+package collector_test
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+)
+
+func fakeSeveritySnapshot() *collector.Snapshot {
+	workload := func(namespace string, labels map[string]string, image string) client.Workload {
+		return client.Workload{
+			Kind:      "Deployment",
+			Namespace: namespace,
+			Name:      "fake",
+			PodSpec: v1.PodSpec{
+				Containers: []v1.Container{{Image: image}},
+			},
+			NamespaceLabels: labels,
+		}
+	}
+	staging := map[string]string{"environment": "staging"}
+	return &collector.Snapshot{
+		Workloads: []client.Workload{
+			workload("production", nil, "app:latest"),
+			workload("staging", staging, "app:latest"),
+			workload("staging", staging, "tool:latest"),
+		},
+		Responses: map[string]client.DockleResponse{
+			"app:latest": {
+				Target:  "app:latest",
+				Summary: client.DockleSummary{Warn: 1, Info: 1},
+				Details: []client.DockleDetail{
+					{Code: "DKL-DI-0006", Level: "WARN"},
+					{Code: "CIS-DI-0005", Level: "INFO"},
+				},
+			},
+			"tool:latest": {
+				Target:  "tool:latest",
+				Summary: client.DockleSummary{Warn: 1},
+				Details: []client.DockleDetail{
+					{Code: "DKL-DI-0006", Level: "WARN"},
+				},
+			},
+		},
+		ScannedAt: time.Date(2020, 9, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestSeveritiesApply(t *testing.T) {
+	type want struct {
+		details   map[string][]client.DockleDetail
+		summaries map[string]client.DockleSummary
+	}
+
+	tests := []struct {
+		name    string
+		configs []collector.SeverityConfig
+		want    want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]collector.SeverityConfig{
+				{Code: "DKL-DI-0006", Namespaces: []string{"prod*"}, Level: "FATAL"},
+				{Code: "DKL-DI-*", Level: "INFO"},
+			},
+			want{
+				map[string][]client.DockleDetail{
+					// app:latest also runs in production, where the most severe level applies.
+					"app:latest": {
+						{Code: "DKL-DI-0006", Level: "FATAL", OriginalLevel: "WARN"},
+						{Code: "CIS-DI-0005", Level: "INFO"},
+					},
+					"tool:latest": {
+						{Code: "DKL-DI-0006", Level: "INFO", OriginalLevel: "WARN"},
+					},
+				},
+				map[string]client.DockleSummary{
+					"app:latest":  {Fatal: 1, Info: 1},
+					"tool:latest": {Info: 1},
+				},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			[]collector.SeverityConfig{
+				{Code: "DKL-DI-0006", NamespaceLabels: map[string]string{"environment": "staging"}, Level: "SKIP"},
+			},
+			want{
+				map[string][]client.DockleDetail{
+					"app:latest": {
+						{Code: "DKL-DI-0006", Level: "WARN"},
+						{Code: "CIS-DI-0005", Level: "INFO"},
+					},
+					"tool:latest": {
+						{Code: "DKL-DI-0006", Level: "SKIP", OriginalLevel: "WARN"},
+					},
+				},
+				map[string]client.DockleSummary{
+					"app:latest":  {Warn: 1, Info: 1},
+					"tool:latest": {Skip: 1},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		configs := tt.configs
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			severities, err := collector.NewSeverities(configs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			snapshot := fakeSeveritySnapshot()
+			severities.Apply(snapshot)
+
+			details := make(map[string][]client.DockleDetail)
+			summaries := make(map[string]client.DockleSummary)
+			for image, response := range snapshot.Responses {
+				details[image] = response.Details
+				summaries[image] = response.Summary
+			}
+			if diff := cmp.Diff(want.details, details); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(want.summaries, summaries); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewSeverities(t *testing.T) {
+	tests := []struct {
+		name            string
+		config          collector.SeverityConfig
+		wantErrorString string
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.SeverityConfig{Level: "FATAL"},
+			"severities[0]: code is required",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.SeverityConfig{Code: "DKL-DI-0006", Level: "CRITICAL"},
+			"severities[0]: unknown level: CRITICAL",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			collector.SeverityConfig{Code: "DKL-DI-0006", Namespaces: []string{"["}, Level: "FATAL"},
+			"severities[0]: invalid pattern [: syntax error in pattern",
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		config := tt.config
+		wantErrorString := tt.wantErrorString
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			gotErrorString := ""
+			if _, err := collector.NewSeverities([]collector.SeverityConfig{config}); err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(wantErrorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// ParseOrdinal extracts the ordinal of a StatefulSet pod from its hostname like kube-dockle-exporter-2.
+func ParseOrdinal(hostname string) (int, error) {
+	i := strings.LastIndex(hostname, "-")
+	if i < 0 {
+		return 0, xerrors.Errorf("no ordinal in %s", hostname)
+	}
+	ordinal, err := strconv.Atoi(hostname[i+1:])
+	if err != nil || ordinal < 0 {
+		return 0, xerrors.Errorf("no ordinal in %s", hostname)
+	}
+	return ordinal, nil
+}
+
+// RendezvousOwner returns which of shards owns the key by rendezvous hashing,
+// so that only keys of added or removed shards move when the number of shards changes.
+func RendezvousOwner(key string, shards int) int {
+	owner := 0
+	var highest uint64
+	for shard := 0; shard < shards; shard++ {
+		hash := fnv.New64a()
+		_, _ = hash.Write([]byte(key + "/" + strconv.Itoa(shard)))
+		if score := hash.Sum64(); shard == 0 || score > highest {
+			owner = shard
+			highest = score
+		}
+	}
+	return owner
+}
+
+// ShardFilter keeps images owned by the shard of the index among replicas of the StatefulSet.
+// Replicas are looked up every scan, so that shards rebalance when the StatefulSet scales.
+type ShardFilter struct {
+	client    IStatefulSetClient
+	namespace string
+	name      string
+	index     int
+}
+
+func NewShardFilter(statefulSetClient IStatefulSetClient, namespace string, name string, index int) *ShardFilter {
+	return &ShardFilter{
+		client:    statefulSetClient,
+		namespace: namespace,
+		name:      name,
+		index:     index,
+	}
+}
+
+func (f *ShardFilter) Filter(images []string) ([]string, error) {
+	replicas, err := f.client.StatefulSetReplicas(f.namespace, f.name)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get replicas: %w", err)
+	}
+	owned := make([]string, 0, len(images))
+	for _, image := range images {
+		if RendezvousOwner(image, replicas) == f.index {
+			owned = append(owned, image)
+		}
+	}
+	return owned, nil
+}
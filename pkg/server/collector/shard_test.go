@@ -0,0 +1,212 @@
+package collector_test
+
+import (
+	"context"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestParseOrdinal(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			"kube-dockle-exporter-2",
+			2,
+			false,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			"kube-dockle-exporter",
+			0,
+			true,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			"localhost",
+			0,
+			true,
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		in := tt.in
+		want := tt.want
+		wantErr := tt.wantErr
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := collector.ParseOrdinal(in)
+			if diff := cmp.Diff(wantErr, err != nil); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRendezvousOwner(t *testing.T) {
+	owners := make(map[int]int)
+	for i := 0; i < 1000; i++ {
+		image := fmt.Sprintf("fake:%d", i)
+		before := collector.RendezvousOwner(image, 3)
+		after := collector.RendezvousOwner(image, 4)
+		// Only images moving to the added shard change their owners.
+		if before != after && after != 3 {
+			t.Errorf("%s moved from %d to %d", image, before, after)
+		}
+		owners[after]++
+	}
+	for shard := 0; shard < 4; shard++ {
+		if owners[shard] < 150 {
+			t.Errorf("shard %d owns only %d images", shard, owners[shard])
+		}
+	}
+}
+
+func TestShardFilterFilter(t *testing.T) {
+	var images []string
+	for i := 0; i < 100; i++ {
+		images = append(images, fmt.Sprintf("fake:%d", i))
+	}
+
+	tests := []struct {
+		name     string
+		replicas int
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			1,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			3,
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		replicas := tt.replicas
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Shards together own every image exactly once.
+			var got []string
+			for index := 0; index < replicas; index++ {
+				filter := collector.NewShardFilter(&statefulSetClientMock{replicas: replicas}, "fake", "fake", index)
+				owned, err := filter.Filter(images)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, owned...)
+			}
+			sort.Strings(got)
+			want := append([]string{}, images...)
+			sort.Strings(want)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDockleCollectorScanShard(t *testing.T) {
+	// Each shard of two replicas owns one of the images.
+	images := make([]string, 2)
+	for i := 0; images[0] == "" || images[1] == ""; i++ {
+		image := fmt.Sprintf("fake:%d", i)
+		images[collector.RendezvousOwner(image, 2)] = image
+	}
+
+	c := collector.NewDockleCollector(
+		&loggerMock{},
+		&kubernetesClientMock{
+			fakeWorkloads: func() ([]client.Workload, error) {
+				return []client.Workload{
+					{
+						Kind:      "Deployment",
+						Namespace: "owned",
+						Name:      "owned",
+						PodSpec: v1.PodSpec{
+							Containers: []v1.Container{{Image: images[0]}},
+						},
+					},
+					{
+						Kind:      "Deployment",
+						Namespace: "other",
+						Name:      "other",
+						PodSpec: v1.PodSpec{
+							Containers: []v1.Container{{Image: images[1]}},
+						},
+					},
+				}, nil
+			},
+			wantFakeWorkloadsCalled: 1,
+		},
+		&dockleClientMock{
+			fakeDo: func(ctx context.Context, image string) ([]byte, error) {
+				return []byte(`{"Details":[{"code":"CIS-DI-0001","level":"WARN"}]}`), nil
+			},
+			wantFakeDoCalled: 1,
+		},
+		1,
+	)
+	c.SetImageFilter(collector.NewShardFilter(&statefulSetClientMock{replicas: 2}, "fake", "fake", 0))
+	risk, err := collector.NewRisk(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetRisk(risk)
+	if err := c.Scan(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	c.Logger.(*loggerMock).assert(t)
+	c.KubernetesClient.(*kubernetesClientMock).assert(t)
+	c.DockleClient.(*dockleClientMock).assert(t)
+
+	snapshot := c.Snapshot()
+	var workloads []string
+	for _, workload := range snapshot.Workloads {
+		workloads = append(workloads, workload.Name)
+	}
+	if diff := cmp.Diff([]string{"owned"}, workloads); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	var risks []string
+	for _, workload := range snapshot.Risk.Workloads {
+		risks = append(risks, workload.Workload.Name)
+	}
+	if diff := cmp.Diff([]string{"owned"}, risks); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]bool{true, false}, []bool{snapshot.InScope(images[0]), snapshot.InScope(images[1])}); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,169 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"net/url"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// Slack accepts at most 50 blocks per message, and 3000 characters per text of section.
+	slackWorkloadsPerMessage = 40
+	slackMaxTextLength       = 3000
+)
+
+type SlackNotifier struct {
+	client ISlackClient
+	apiURL string
+}
+
+// NewSlackNotifier creates a notifier which links findings to the API served at apiURL, or omits links if it is empty.
+func NewSlackNotifier(slackClient ISlackClient, apiURL string) *SlackNotifier {
+	return &SlackNotifier{
+		client: slackClient,
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+	}
+}
+
+// Notify posts all messages even if some of them fail, and returns the first error.
+func (n *SlackNotifier) Notify(ctx context.Context, findings []WorkloadFinding) error {
+	messages := n.Messages(findings)
+	var errs []error
+	for i := range messages {
+		if err := n.client.Post(ctx, &messages[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return xerrors.Errorf("failed to post %d of %d messages to slack: %w", len(errs), len(messages), errs[0])
+	}
+	return nil
+}
+
+type slackWorkloadGroup struct {
+	workload client.Workload
+	images   []string
+	findings map[string][]Finding
+}
+
+// Messages groups findings per workload and splits them into messages within the limits of Block Kit.
+func (n *SlackNotifier) Messages(findings []WorkloadFinding) []client.SlackMessage {
+	var groups []*slackWorkloadGroup
+	indices := make(map[string]int)
+	for _, finding := range findings {
+		key := finding.Workload.Cluster + " " + finding.Workload.Kind + "/" + finding.Workload.Namespace + "/" + finding.Workload.Name
+		index, ok := indices[key]
+		if !ok {
+			index = len(groups)
+			indices[key] = index
+			groups = append(groups, &slackWorkloadGroup{
+				workload: finding.Workload,
+				findings: make(map[string][]Finding),
+			})
+		}
+		group := groups[index]
+		if _, ok := group.findings[finding.Image]; !ok {
+			group.images = append(group.images, finding.Image)
+		}
+		group.findings[finding.Image] = append(group.findings[finding.Image], finding.Finding)
+	}
+
+	var messages []client.SlackMessage // nolint:prealloc
+	for start := 0; start < len(groups); start += slackWorkloadsPerMessage {
+		end := start + slackWorkloadsPerMessage
+		if end > len(groups) {
+			end = len(groups)
+		}
+		summary := fmt.Sprintf("New Dockle findings in %d workloads", len(groups))
+		if len(groups) == 1 {
+			summary = "New Dockle findings in 1 workload"
+		}
+		message := client.SlackMessage{
+			Text: summary,
+			Blocks: []client.SlackBlock{
+				{
+					Type: "header",
+					Text: &client.SlackText{
+						Type: "plain_text",
+						Text: summary,
+					},
+				},
+			},
+		}
+		for _, group := range groups[start:end] {
+			message.Blocks = append(message.Blocks, client.SlackBlock{
+				Type: "section",
+				Text: &client.SlackText{
+					Type: "mrkdwn",
+					Text: n.sectionText(group),
+				},
+			})
+		}
+		if n.apiURL != "" {
+			message.Blocks = append(message.Blocks, client.SlackBlock{
+				Type: "context",
+				Elements: []client.SlackText{
+					{
+						Type: "mrkdwn",
+						Text: fmt.Sprintf("<%s/|Dashboard> · <%s/api/v1/images|API>", n.apiURL, n.apiURL),
+					},
+				},
+			})
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func (n *SlackNotifier) sectionText(group *slackWorkloadGroup) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "*%s* `%s/%s`", group.workload.Kind, group.workload.Namespace, group.workload.Name)
+	if group.workload.Cluster != "" {
+		fmt.Fprintf(&builder, " in %s", slackEscape(group.workload.Cluster))
+	}
+	if len(group.workload.Owner) > 0 {
+		fmt.Fprintf(&builder, " owned by %s", slackEscape(OwnerString(group.workload.Owner)))
+	}
+	for _, image := range group.images {
+		builder.WriteString("\n")
+		if n.apiURL != "" {
+			path := imagePath(image)
+			fmt.Fprintf(
+				&builder,
+				"<%s|%s> (<%s|json>)",
+				slackEscape(n.apiURL+"/ui/images/"+path),
+				slackEscape(image),
+				slackEscape(n.apiURL+"/api/v1/images/"+path),
+			)
+		} else {
+			fmt.Fprintf(&builder, "`%s`", slackEscape(image))
+		}
+		for _, finding := range group.findings[image] {
+			fmt.Fprintf(&builder, "\n• *%s* %s %s", finding.Level, finding.Code, slackEscape(finding.Title))
+		}
+	}
+	// Slack counts characters, and cutting bytes may split a multibyte one.
+	text := []rune(builder.String())
+	if len(text) > slackMaxTextLength {
+		text = append(text[:slackMaxTextLength-3], []rune("...")...)
+	}
+	return string(text)
+}
+
+// imagePath escapes each segment of the image, so that links end neither at | nor at other characters of references.
+func imagePath(image string) string {
+	segments := strings.Split(image, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return strings.Join(segments, "/")
+}
+
+// slackEscape escapes control characters of Slack's mrkdwn.
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
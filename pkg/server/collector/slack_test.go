@@ -0,0 +1,229 @@
+package collector_test
+
+import (
+	"context"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/xerrors"
+)
+
+func TestSlackNotifierNotify(t *testing.T) {
+	withFinding := func(level string) *collector.Snapshot {
+		snapshot := fakeSnapshot()
+		response := snapshot.Responses["fake"]
+		response.Details = append(response.Details, client.DockleDetail{
+			Code:  "CIS-DI-0005",
+			Title: "Enable Content trust for Docker",
+			Level: level,
+		})
+		snapshot.Responses["fake"] = response
+		return snapshot
+	}
+
+	tests := []struct {
+		name   string
+		level  string
+		apiURL string
+		in     []*collector.Snapshot
+		want   []client.SlackMessage
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			"FATAL",
+			"http://exporter.example/",
+			[]*collector.Snapshot{
+				fakeSnapshot(),
+				withFinding("FATAL"),
+				withFinding("FATAL"),
+			},
+			[]client.SlackMessage{
+				{
+					Text: "New Dockle findings in 1 workload",
+					Blocks: []client.SlackBlock{
+						{
+							Type: "header",
+							Text: &client.SlackText{
+								Type: "plain_text",
+								Text: "New Dockle findings in 1 workload",
+							},
+						},
+						{
+							Type: "section",
+							Text: &client.SlackText{
+								Type: "mrkdwn",
+								Text: "*Deployment* `fake/fake`\n<http://exporter.example/ui/images/fake|fake> (<http://exporter.example/api/v1/images/fake|json>)\n• *FATAL* CIS-DI-0005 Enable Content trust for Docker",
+							},
+						},
+						{
+							Type: "context",
+							Elements: []client.SlackText{
+								{
+									Type: "mrkdwn",
+									Text: "<http://exporter.example/|Dashboard> · <http://exporter.example/api/v1/images|API>",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			"WARN",
+			"",
+			[]*collector.Snapshot{
+				fakeSnapshot(),
+				func() *collector.Snapshot {
+					snapshot := withFinding("WARN")
+					snapshot.Workloads[0].Owner = map[string]string{"team": "fake", "slack-channel": "#fake"}
+					return snapshot
+				}(),
+			},
+			[]client.SlackMessage{
+				{
+					Text: "New Dockle findings in 1 workload",
+					Blocks: []client.SlackBlock{
+						{
+							Type: "header",
+							Text: &client.SlackText{
+								Type: "plain_text",
+								Text: "New Dockle findings in 1 workload",
+							},
+						},
+						{
+							Type: "section",
+							Text: &client.SlackText{
+								Type: "mrkdwn",
+								Text: "*Deployment* `fake/fake` owned by slack-channel=#fake, team=fake\n`fake`\n• *WARN* CIS-DI-0005 Enable Content trust for Docker",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			"FATAL",
+			"",
+			[]*collector.Snapshot{
+				fakeSnapshot(),
+				withFinding("WARN"),
+			},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		level := tt.level
+		apiURL := tt.apiURL
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			slackClient := &slackClientMock{}
+			publisher := collector.NewNotificationPublisher(level)
+			publisher.AddNotifier(collector.NewSlackNotifier(slackClient, apiURL))
+			for _, snapshot := range in {
+				if err := publisher.Publish(context.Background(), snapshot); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if diff := cmp.Diff(want, slackClient.messages); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSlackNotifierNotifyAgainAfterFailure(t *testing.T) {
+	withFinding := fakeSnapshot()
+	response := withFinding.Responses["fake"]
+	response.Details = append(response.Details, client.DockleDetail{Code: "CIS-DI-0005", Level: "FATAL"})
+	withFinding.Responses["fake"] = response
+
+	failed := true
+	slackClient := &slackClientMock{
+		fakePost: func(context.Context, *client.SlackMessage) error {
+			if failed {
+				return xerrors.New("fake")
+			}
+			return nil
+		},
+	}
+	publisher := collector.NewNotificationPublisher("FATAL")
+	publisher.AddNotifier(collector.NewSlackNotifier(slackClient, ""))
+	if err := publisher.Publish(context.Background(), fakeSnapshot()); err != nil {
+		t.Fatal(err)
+	}
+	err := publisher.Publish(context.Background(), withFinding)
+	gotErrorString := ""
+	if err != nil {
+		gotErrorString = err.Error()
+	}
+	if diff := cmp.Diff("failed to notify 1 of 1 notifiers: failed to post 1 of 1 messages to slack: fake", gotErrorString); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+
+	// The finding is introduced again, since it was not delivered.
+	failed = false
+	if err := publisher.Publish(context.Background(), withFinding); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(1, len(slackClient.messages)); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if err := publisher.Publish(context.Background(), withFinding); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(1, len(slackClient.messages)); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestSlackNotifierMessages(t *testing.T) {
+	workload := fakeSnapshot().Workloads[0]
+	findings := []collector.WorkloadFinding{
+		{
+			Workload: workload,
+			Finding:  collector.Finding{Image: "registry.example/app|x&y:1", Code: "CIS-DI-0001", Level: "WARN"},
+		},
+	}
+	for i := 0; i < 200; i++ {
+		findings = append(findings, collector.WorkloadFinding{
+			Workload: workload,
+			Finding:  collector.Finding{Image: "fake", Code: fmt.Sprintf("CUSTOM-%04d", i), Level: "WARN", Title: "日本語のタイトル"},
+		})
+	}
+
+	messages := collector.NewSlackNotifier(&slackClientMock{}, "http://exporter.example").Messages(findings)
+	text := messages[0].Blocks[1].Text.Text
+	if diff := cmp.Diff(
+		"*Deployment* `fake/fake`\n<http://exporter.example/ui/images/registry.example/app%7Cx&amp;y:1|registry.example/app|x&amp;y:1> (<http://exporter.example/api/v1/images/registry.example/app%7Cx&amp;y:1|json>)",
+		strings.SplitN(text, "\n", 3)[0]+"\n"+strings.SplitN(text, "\n", 3)[1],
+	); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if !utf8.ValidString(text) {
+		t.Errorf("text is not valid UTF-8: %q", text)
+	}
+	if diff := cmp.Diff(3000, utf8.RuneCountInString(text)); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
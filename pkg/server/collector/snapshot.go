@@ -0,0 +1,135 @@
+package collector
+
+import (
+	"kube-dockle-exporter/pkg/client"
+	"sort"
+	"time"
+)
+
+type Snapshot struct {
+	Workloads []client.Workload
+	Responses map[string]client.DockleResponse
+	// Suppressed holds findings which exceptions moved out of Responses.
+	Suppressed []SuppressedFinding
+	// Correlated holds findings of each workload correlated with its spec.
+	Correlated []CorrelatedFinding
+	// Violations holds violations of policies evaluated over the results.
+	Violations []PolicyViolation
+	// Risk holds risk scores computed from the results.
+	Risk *RiskScores
+	// Owners holds owners of namespaces, which workloads also hold.
+	Owners []NamespaceOwner
+	// Failed holds images which failed to be scanned, whose findings of the last scan publishers keep.
+	Failed    map[string]bool
+	ScannedAt time.Time
+	// scope holds images which the image filter selected, or is nil if images of all workloads are scanned.
+	scope map[string]bool
+}
+
+// InScope tells whether the image is scanned by this replica, which is false for images of other shards.
+func (s *Snapshot) InScope(image string) bool {
+	return s.scope == nil || s.scope[image]
+}
+
+// scopeTo restricts the snapshot to the images, keeping only workloads which run any of them and owners of their
+// namespaces, so that outputs of workloads do not overlap with those of other shards.
+func (s *Snapshot) scopeTo(images []string) {
+	s.scope = make(map[string]bool, len(images))
+	for _, image := range images {
+		s.scope[image] = true
+	}
+	var workloads []client.Workload
+	namespaces := make(map[string]bool)
+	for _, workload := range s.Workloads {
+		for _, image := range workload.Images() {
+			if s.scope[image] {
+				workloads = append(workloads, workload)
+				namespaces[workload.Cluster+" "+workload.Namespace] = true
+				break
+			}
+		}
+	}
+	s.Workloads = workloads
+	var owners []NamespaceOwner
+	for _, owner := range s.Owners {
+		if namespaces[owner.Cluster+" "+owner.Namespace] {
+			owners = append(owners, owner)
+		}
+	}
+	s.Owners = owners
+}
+
+func (s *Snapshot) Images() []string {
+	keys := make(map[string]bool)
+	var images []string
+	for _, workload := range s.Workloads {
+		for _, image := range workload.Images() {
+			if _, value := keys[image]; !value {
+				keys[image] = true
+				images = append(images, image)
+			}
+		}
+	}
+	return images
+}
+
+func (s *Snapshot) WorkloadsOf(image string) []client.Workload {
+	var workloads []client.Workload
+	for _, workload := range s.Workloads {
+		for _, i := range workload.Images() {
+			if i == image {
+				workloads = append(workloads, workload)
+				break
+			}
+		}
+	}
+	return workloads
+}
+
+// ImageClusters returns names of clusters running each image.
+func (s *Snapshot) ImageClusters() map[string][]string {
+	clusters := make(map[string][]string)
+	keys := make(map[string]bool)
+	for _, workload := range s.Workloads {
+		for _, image := range workload.Images() {
+			key := workload.Cluster + " " + image
+			if keys[key] {
+				continue
+			}
+			keys[key] = true
+			clusters[image] = append(clusters[image], workload.Cluster)
+		}
+	}
+	for _, names := range clusters {
+		sort.Strings(names)
+	}
+	return clusters
+}
+
+// unscanned returns keys of clusters and images which are deployed but failed to be scanned, so that findings of the
+// last scan are kept until the next successful scan instead of being resolved and introduced again.
+func (s *Snapshot) unscanned() map[string]bool {
+	keys := make(map[string]bool)
+	for image, clusters := range s.ImageClusters() {
+		if !s.Failed[image] {
+			continue
+		}
+		for _, cluster := range clusters {
+			keys[cluster+" "+image] = true
+		}
+	}
+	return keys
+}
+
+func (s *Snapshot) SortedResponses() []client.DockleResponse {
+	images := make([]string, 0, len(s.Responses))
+	for image := range s.Responses {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	responses := make([]client.DockleResponse, 0, len(images))
+	for _, image := range images {
+		responses = append(responses, s.Responses[image])
+	}
+	return responses
+}
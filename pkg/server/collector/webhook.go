@@ -0,0 +1,270 @@
+package collector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"kube-dockle-exporter/pkg/client"
+	"strconv"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	WebhookEventScanCompleted     = "scan.completed"
+	WebhookEventScanFailed        = "scan.failed"
+	WebhookEventFindingIntroduced = "finding.introduced"
+	WebhookEventFindingResolved   = "finding.resolved"
+)
+
+type ScanCompletedData struct {
+	ScannedAt time.Time      `json:"scannedAt"`
+	Workloads int            `json:"workloads"`
+	Images    int            `json:"images"`
+	Findings  map[string]int `json:"findings"`
+}
+
+type ScanFailedData struct {
+	Error string `json:"error"`
+}
+
+type FindingData struct {
+	Cluster string `json:"cluster,omitempty"`
+	Finding
+	Workloads []client.Workload `json:"workloads"`
+}
+
+// clusterFinding is a finding of an image in a cluster, so that the same image in several clusters is compared
+// separately across scans.
+type clusterFinding struct {
+	Cluster string
+	Finding
+}
+
+func (f *clusterFinding) key() string {
+	if f.Cluster == "" {
+		return f.Finding.Key()
+	}
+	return f.Cluster + ":" + f.Finding.Key()
+}
+
+// clusterFindingsOf expands findings to clusters running their images, or to the unnamed cluster if unknown.
+func clusterFindingsOf(findings []Finding, imageClusters map[string][]string) []clusterFinding {
+	var result []clusterFinding
+	for _, finding := range findings {
+		clusters := imageClusters[finding.Image]
+		if len(clusters) == 0 {
+			clusters = []string{""}
+		}
+		for _, cluster := range clusters {
+			result = append(result, clusterFinding{
+				Cluster: cluster,
+				Finding: finding,
+			})
+		}
+	}
+	return result
+}
+
+// WebhookPublisher emits CloudEvents of scans and of findings which a scan introduced or resolved.
+// Events go through the outbox if it is not nil, so that undelivered events are sent again on the next scan.
+// Findings are compared with the baseline loaded from history across restarts.
+type WebhookPublisher struct {
+	client   IWebhookClient
+	outbox   IOutbox
+	source   string
+	previous []clusterFinding
+	scanned  bool
+	now      func() time.Time
+}
+
+func NewWebhookPublisher(webhookClient IWebhookClient, outbox IOutbox, source string) *WebhookPublisher {
+	return &WebhookPublisher{
+		client: webhookClient,
+		outbox: outbox,
+		source: source,
+		now:    time.Now,
+	}
+}
+
+// LoadBaseline takes findings of the latest scan in history at or before t as the baseline, so that the first scan
+// after a restart emits changes since the last one instead of only becoming the baseline.
+func (p *WebhookPublisher) LoadBaseline(history IHistoryReader, t time.Time) error {
+	entry, err := history.At(t)
+	if err != nil {
+		return xerrors.Errorf("failed to load baseline: %w", err)
+	}
+	if entry == nil {
+		return nil
+	}
+	p.previous = clusterFindingsOf(FindingsOf(entry.Responses), entry.Clusters)
+	p.scanned = true
+	return nil
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, snapshot *Snapshot) error {
+	findings := snapshot.Findings()
+	counts := make(map[string]int)
+	for _, finding := range findings {
+		counts[finding.Level]++
+	}
+	current := clusterFindingsOf(findings, snapshot.ImageClusters())
+	// Findings of images which failed to be scanned are kept, instead of being resolved and introduced again.
+	unscanned := snapshot.unscanned()
+	for _, finding := range p.previous {
+		if unscanned[finding.Cluster+" "+finding.Image] {
+			current = append(current, finding)
+		}
+	}
+
+	var events []client.CloudEvent
+	event, err := p.event(WebhookEventScanCompleted, "", snapshot.ScannedAt, &ScanCompletedData{
+		ScannedAt: snapshot.ScannedAt,
+		Workloads: len(snapshot.Workloads),
+		Images:    len(snapshot.Responses),
+		Findings:  counts,
+	})
+	if err != nil {
+		return err
+	}
+	events = append(events, *event)
+
+	// The first scan has nothing to compare with, so it only becomes the baseline.
+	if p.scanned {
+		keysOf := func(findings []clusterFinding) []string {
+			keys := make([]string, 0, len(findings))
+			for _, finding := range findings {
+				keys = append(keys, finding.key())
+			}
+			return keys
+		}
+		introduced, resolved, _ := diffKeys(keysOf(p.previous), keysOf(current))
+		for _, change := range []struct {
+			eventType string
+			findings  []clusterFinding
+			indices   []int
+		}{
+			{WebhookEventFindingIntroduced, current, introduced},
+			{WebhookEventFindingResolved, p.previous, resolved},
+		} {
+			for _, i := range change.indices {
+				finding := change.findings[i]
+				var workloads []client.Workload
+				for _, workload := range snapshot.WorkloadsOf(finding.Image) {
+					if workload.Cluster == finding.Cluster {
+						workloads = append(workloads, workload)
+					}
+				}
+				event, err := p.event(change.eventType, finding.key(), snapshot.ScannedAt, &FindingData{
+					Cluster:   finding.Cluster,
+					Finding:   finding.Finding,
+					Workloads: workloads,
+				})
+				if err != nil {
+					return err
+				}
+				events = append(events, *event)
+			}
+		}
+	}
+	p.previous = current
+	p.scanned = true
+
+	return p.deliver(ctx, events)
+}
+
+func (p *WebhookPublisher) PublishFailure(ctx context.Context, scanErr error) error {
+	event, err := p.event(WebhookEventScanFailed, "", p.now(), &ScanFailedData{
+		Error: scanErr.Error(),
+	})
+	if err != nil {
+		return err
+	}
+	return p.deliver(ctx, []client.CloudEvent{*event})
+}
+
+// Notify emits finding.introduced of each workload, so that the publisher also works as a sink of routing.
+func (p *WebhookPublisher) Notify(ctx context.Context, findings []WorkloadFinding) error {
+	now := p.now()
+	events := make([]client.CloudEvent, 0, len(findings))
+	for _, finding := range findings {
+		event, err := p.event(WebhookEventFindingIntroduced, finding.Image+" "+finding.Code, now, &FindingData{
+			Cluster:   finding.Workload.Cluster,
+			Finding:   finding.Finding,
+			Workloads: []client.Workload{finding.Workload},
+		})
+		if err != nil {
+			return err
+		}
+		events = append(events, *event)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	return p.deliver(ctx, events)
+}
+
+func (p *WebhookPublisher) event(eventType string, subject string, t time.Time, data interface{}) (*client.CloudEvent, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to marshal %s: %w", eventType, err)
+	}
+	// IDs are derived from the contents, so that receivers can deduplicate events sent again.
+	hash := sha256.Sum256([]byte(eventType + "\n" + subject + "\n" + strconv.FormatInt(t.UnixNano(), 10) + "\n" + string(body)))
+	return &client.CloudEvent{
+		SpecVersion:     client.CloudEventsSpecVersion,
+		ID:              hex.EncodeToString(hash[:16]),
+		Source:          p.source,
+		Type:            eventType,
+		Subject:         subject,
+		Time:            t.UTC(),
+		DataContentType: "application/json",
+		Data:            body,
+	}, nil
+}
+
+func (p *WebhookPublisher) deliver(ctx context.Context, events []client.CloudEvent) error {
+	if p.outbox == nil {
+		var errs []error
+		for i := range events {
+			if err := p.client.Send(ctx, &events[i]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if len(errs) > 0 {
+			return xerrors.Errorf("failed to send %d of %d events: %w", len(errs), len(events), errs[0])
+		}
+		return nil
+	}
+
+	if err := p.outbox.Enqueue(events); err != nil {
+		return xerrors.Errorf("failed to enqueue events: %w", err)
+	}
+	return p.Flush(ctx)
+}
+
+// Flush sends events in the outbox in order, and stops at the first event which may be delivered later.
+func (p *WebhookPublisher) Flush(ctx context.Context) error {
+	entries, err := p.outbox.Pending()
+	if err != nil {
+		return xerrors.Errorf("failed to get pending events: %w", err)
+	}
+	var rejected []error
+	for i := range entries {
+		if err := p.client.Send(ctx, &entries[i].Event); err != nil {
+			if !xerrors.Is(err, client.ErrWebhookRejected) {
+				return xerrors.Errorf("failed to send events, %d events are pending: %w", len(entries)-i, err)
+			}
+			rejected = append(rejected, err)
+		}
+		if err := p.outbox.Remove(entries[i].Sequence); err != nil {
+			return xerrors.Errorf("failed to remove event from outbox: %w", err)
+		}
+	}
+	if len(rejected) > 0 {
+		return xerrors.Errorf("%d events are dropped: %w", len(rejected), rejected[0])
+	}
+	return nil
+}
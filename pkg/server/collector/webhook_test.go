@@ -0,0 +1,295 @@
+package collector_test
+
+import (
+	"context"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/xerrors"
+)
+
+func TestWebhookPublisherPublish(t *testing.T) {
+	withFinding := func() *collector.Snapshot {
+		snapshot := fakeSnapshot()
+		response := snapshot.Responses["fake"]
+		response.Details = append(response.Details, client.DockleDetail{
+			Code:  "CIS-DI-0005",
+			Title: "Enable Content trust for Docker",
+			Level: "INFO",
+		})
+		snapshot.Responses["fake"] = response
+		return snapshot
+	}
+	failed := func() *collector.Snapshot {
+		snapshot := fakeSnapshot()
+		snapshot.Responses = map[string]client.DockleResponse{}
+		snapshot.Failed = map[string]bool{"fake": true}
+		return snapshot
+	}
+	inClusters := func() *collector.Snapshot {
+		snapshot := withFinding()
+		snapshot.Workloads[0].Cluster = "a"
+		workload := snapshot.Workloads[0]
+		workload.Cluster = "b"
+		snapshot.Workloads = append(snapshot.Workloads, workload)
+		return snapshot
+	}
+
+	type want struct {
+		types    []string
+		subjects []string
+		pending  int
+	}
+
+	tests := []struct {
+		name     string
+		fakeSend func(context.Context, *client.CloudEvent) error
+		outbox   *outboxMock
+		in       []*collector.Snapshot
+		want     want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			nil,
+			nil,
+			[]*collector.Snapshot{
+				fakeSnapshot(),
+				withFinding(),
+				fakeSnapshot(),
+			},
+			want{
+				[]string{
+					collector.WebhookEventScanCompleted,
+					collector.WebhookEventScanCompleted,
+					collector.WebhookEventFindingIntroduced,
+					collector.WebhookEventScanCompleted,
+					collector.WebhookEventFindingResolved,
+				},
+				[]string{"", "", "fake CIS-DI-0005", "", "fake CIS-DI-0005"},
+				0,
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			nil,
+			nil,
+			[]*collector.Snapshot{
+				withFinding(),
+				failed(),
+				withFinding(),
+			},
+			want{
+				[]string{
+					collector.WebhookEventScanCompleted,
+					collector.WebhookEventScanCompleted,
+					collector.WebhookEventScanCompleted,
+				},
+				[]string{"", "", ""},
+				0,
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			nil,
+			nil,
+			[]*collector.Snapshot{
+				fakeSnapshot(),
+				inClusters(),
+			},
+			want{
+				[]string{
+					collector.WebhookEventScanCompleted,
+					collector.WebhookEventScanCompleted,
+					collector.WebhookEventFindingIntroduced,
+					collector.WebhookEventFindingIntroduced,
+					collector.WebhookEventFindingIntroduced,
+					collector.WebhookEventFindingIntroduced,
+					collector.WebhookEventFindingResolved,
+				},
+				[]string{"", "", "a:fake CIS-DI-0001", "b:fake CIS-DI-0001", "a:fake CIS-DI-0005", "b:fake CIS-DI-0005", "fake CIS-DI-0001"},
+				0,
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func() func(context.Context, *client.CloudEvent) error {
+				called := 0
+				return func(context.Context, *client.CloudEvent) error {
+					called++
+					if called == 2 {
+						return xerrors.New("fake")
+					}
+					return nil
+				}
+			}(),
+			&outboxMock{},
+			[]*collector.Snapshot{
+				fakeSnapshot(),
+				withFinding(),
+				withFinding(),
+			},
+			want{
+				[]string{
+					collector.WebhookEventScanCompleted,
+					collector.WebhookEventScanCompleted,
+					collector.WebhookEventFindingIntroduced,
+					collector.WebhookEventScanCompleted,
+				},
+				[]string{"", "", "fake CIS-DI-0005", ""},
+				0,
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func(context.Context, *client.CloudEvent) error {
+				return xerrors.New("fake")
+			},
+			&outboxMock{},
+			[]*collector.Snapshot{
+				fakeSnapshot(),
+				withFinding(),
+			},
+			want{
+				nil,
+				nil,
+				3,
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func(context.Context, *client.CloudEvent) error {
+				return xerrors.Errorf("fake: %w", client.ErrWebhookRejected)
+			},
+			&outboxMock{},
+			[]*collector.Snapshot{
+				fakeSnapshot(),
+			},
+			want{
+				nil,
+				nil,
+				0,
+			},
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		fakeSend := tt.fakeSend
+		outbox := tt.outbox
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			webhookClient := &webhookClientMock{
+				fakeSend: fakeSend,
+			}
+			// A nil *outboxMock must not be passed as a non-nil IOutbox.
+			publisher := collector.NewWebhookPublisher(webhookClient, nil, "fake")
+			if outbox != nil {
+				publisher = collector.NewWebhookPublisher(webhookClient, outbox, "fake")
+			}
+			for _, snapshot := range in {
+				_ = publisher.Publish(context.Background(), snapshot)
+			}
+
+			var types []string
+			var subjects []string
+			for _, event := range webhookClient.events {
+				types = append(types, event.Type)
+				subjects = append(subjects, event.Subject)
+			}
+			if diff := cmp.Diff(want.types, types); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(want.subjects, subjects); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			pending := 0
+			if outbox != nil {
+				pending = len(outbox.entries)
+			}
+			if diff := cmp.Diff(want.pending, pending); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWebhookPublisherPublishFailure(t *testing.T) {
+	webhookClient := &webhookClientMock{}
+	publisher := collector.NewWebhookPublisher(webhookClient, nil, "fake")
+	if err := publisher.PublishFailure(context.Background(), xerrors.New("fake")); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(1, len(webhookClient.events)); diff != "" {
+		t.Fatalf("(-want +got):\n%s", diff)
+	}
+	event := webhookClient.events[0]
+	if diff := cmp.Diff(collector.WebhookEventScanFailed, event.Type); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(`{"error":"fake"}`, string(event.Data)); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+type historyReaderMock struct {
+	collector.IHistoryReader
+	entry *client.HistoryEntry
+}
+
+func (m *historyReaderMock) At(time.Time) (*client.HistoryEntry, error) {
+	return m.entry, nil
+}
+
+func TestWebhookPublisherLoadBaseline(t *testing.T) {
+	withFinding := fakeSnapshot()
+	response := withFinding.Responses["fake"]
+	response.Details = append(response.Details, client.DockleDetail{Code: "CIS-DI-0005", Level: "INFO"})
+	withFinding.Responses["fake"] = response
+
+	webhookClient := &webhookClientMock{}
+	publisher := collector.NewWebhookPublisher(webhookClient, nil, "fake")
+	if err := publisher.LoadBaseline(&historyReaderMock{
+		entry: &client.HistoryEntry{
+			ScannedAt: time.Unix(0, 0),
+			Responses: fakeSnapshot().SortedResponses(),
+		},
+	}, time.Unix(1, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := publisher.Publish(context.Background(), withFinding); err != nil {
+		t.Fatal(err)
+	}
+
+	var types []string
+	for _, event := range webhookClient.events {
+		types = append(types, event.Type)
+	}
+	if diff := cmp.Diff([]string{collector.WebhookEventScanCompleted, collector.WebhookEventFindingIntroduced}, types); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
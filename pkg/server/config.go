@@ -0,0 +1,215 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/xerrors"
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the file given by --config.
+// Server holds values of flags of the server keyed by their names, and the others can be reloaded without restart.
+type Config struct {
+	Server       map[string]interface{}      `json:"server,omitempty"`
+	Ignore       IgnoreConfig                `json:"ignore,omitempty"`
+	Registries   []RegistryConfig            `json:"registries,omitempty"`
+	Exceptions   []collector.ExceptionConfig `json:"exceptions,omitempty"`
+	Severities   []collector.SeverityConfig  `json:"severities,omitempty"`
+	Risk         *collector.RiskConfig       `json:"risk,omitempty"`
+	Notification *collector.RoutingConfig    `json:"notification,omitempty"`
+}
+
+type IgnoreConfig struct {
+	Codes  []string `json:"codes,omitempty"`
+	Images []string `json:"images,omitempty"`
+}
+
+type RegistryConfig struct {
+	Host         string `json:"host"`
+	Insecure     bool   `json:"insecure,omitempty"`
+	Username     string `json:"username,omitempty"`
+	PasswordFile string `json:"passwordFile,omitempty"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, xerrors.Errorf("could not read %s: %w", path, err)
+	}
+	return ParseConfig(content)
+}
+
+func ParseConfig(content []byte) (*Config, error) {
+	var config Config
+	if err := yaml.UnmarshalStrict(content, &config); err != nil {
+		return nil, xerrors.Errorf("could not parse config: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, xerrors.Errorf("invalid config: %w", err)
+	}
+	return &config, nil
+}
+
+// Validate validates sections other than server, which ApplyServerConfig validates against flags.
+func (c *Config) Validate() error {
+	if _, err := collector.NewIgnore(c.Ignore.Codes, c.Ignore.Images); err != nil {
+		return xerrors.Errorf("ignore: %w", err)
+	}
+	hosts := make(map[string]bool, len(c.Registries))
+	for i, registry := range c.Registries {
+		if registry.Host == "" {
+			return xerrors.Errorf("registries[%d]: host is required", i)
+		}
+		if hosts[registry.Host] {
+			return xerrors.Errorf("registries[%d]: duplicate host %s", i, registry.Host)
+		}
+		hosts[registry.Host] = true
+		if registry.Username != "" && registry.PasswordFile == "" {
+			return xerrors.Errorf("registries[%d]: passwordFile is required with username", i)
+		}
+	}
+	if _, err := collector.NewExceptions(c.Exceptions); err != nil {
+		return err
+	}
+	if _, err := collector.NewSeverities(c.Severities); err != nil {
+		return err
+	}
+	if _, err := collector.NewRisk(c.Risk); err != nil {
+		return err
+	}
+	if c.Notification != nil {
+		if err := collector.ValidateRoutingConfig(c.Notification); err != nil {
+			return xerrors.Errorf("notification: %w", err)
+		}
+	}
+	return nil
+}
+
+// ResolveRegistries reads passwords of registries from their files.
+func (c *Config) ResolveRegistries() ([]client.Registry, error) {
+	registries := make([]client.Registry, 0, len(c.Registries))
+	for _, registry := range c.Registries {
+		var password string
+		if registry.PasswordFile != "" {
+			content, err := ioutil.ReadFile(registry.PasswordFile)
+			if err != nil {
+				return nil, xerrors.Errorf("could not read password of registry %s: %w", registry.Host, err)
+			}
+			password = strings.TrimSpace(string(content))
+		}
+		registries = append(registries, client.Registry{
+			Host:     registry.Host,
+			Insecure: registry.Insecure,
+			Username: registry.Username,
+			Password: password,
+		})
+	}
+	return registries, nil
+}
+
+// ApplyServerConfig sets values of the server section to flags which are not given on the command line.
+func ApplyServerConfig(flags *pflag.FlagSet, values map[string]interface{}) error {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		flag := flags.Lookup(name)
+		if flag == nil || name == "config" {
+			return xerrors.Errorf("server.%s: unknown setting", name)
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := flag.Value.Set(configValue(values[name])); err != nil {
+			return xerrors.Errorf("server.%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func configValue(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, configValue(item))
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// watchConfig reloads the config on SIGHUP, or when its content changes from the loaded one, e.g. in a mounted
+// ConfigMap. Invalid configs are logged and ignored.
+func watchConfig(
+	ctx context.Context,
+	path string,
+	loaded []byte,
+	interval time.Duration,
+	logger ILogger,
+	reload func(*Config) error,
+) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	var server map[string]interface{}
+	if config, err := ParseConfig(loaded); err == nil {
+		server = config.Server
+	}
+	for {
+		force := false
+		select {
+		case <-hangup:
+			force = true
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			logger.Errorf("Failed to read config: %s\n", err.Error())
+			continue
+		}
+		if !force && bytes.Equal(content, loaded) {
+			continue
+		}
+		loaded = content
+		config, err := ParseConfig(content)
+		if err != nil {
+			logger.Errorf("Failed to reload config: %s\n", err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(config.Server, server) {
+			logger.Errorf("Changes of the server section of config take effect after restart\n")
+		}
+		if err := reload(config); err != nil {
+			logger.Errorf("Failed to reload config: %s\n", err.Error())
+			continue
+		}
+		logger.Infof("Reloaded config\n")
+	}
+}
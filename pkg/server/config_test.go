@@ -0,0 +1,160 @@
+package server_test
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/server"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/pflag"
+)
+
+func TestParseConfig(t *testing.T) {
+	type in struct {
+		first string
+	}
+
+	tests := []struct {
+		name            string
+		in              in
+		want            *server.Config
+		wantErrorString string
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				`
+server:
+  dockle-concurrency: 5
+ignore:
+  codes: ["DKL-*"]
+registries:
+  - host: registry.example.com
+    insecure: true
+`,
+			},
+			&server.Config{
+				Server: map[string]interface{}{
+					"dockle-concurrency": float64(5),
+				},
+				Ignore: server.IgnoreConfig{
+					Codes: []string{"DKL-*"},
+				},
+				Registries: []server.RegistryConfig{
+					{Host: "registry.example.com", Insecure: true},
+				},
+			},
+			"",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				`ignores: {}`,
+			},
+			nil,
+			`could not parse config: error unmarshaling JSON: while decoding JSON: json: unknown field "ignores"`,
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				`ignore: {images: ["["]}`,
+			},
+			nil,
+			"invalid config: ignore: invalid pattern [: syntax error in pattern",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				`registries: [{host: registry.example.com, username: fake}]`,
+			},
+			nil,
+			"invalid config: registries[0]: passwordFile is required with username",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			in{
+				`notification: {routes: [{sinks: [fake]}]}`,
+			},
+			nil,
+			"invalid config: notification: unknown sink fake in route 0",
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		in := tt.in
+		want := tt.want
+		wantErrorString := tt.wantErrorString
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := server.ParseConfig([]byte(in.first))
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			gotErrorString := ""
+			if err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(wantErrorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApplyServerConfig(t *testing.T) {
+	args := server.DefaultArgs()
+	flags := pflag.NewFlagSet("fake", pflag.ContinueOnError)
+	flags.Int64Var(&args.DockleConcurrency, "dockle-concurrency", args.DockleConcurrency, "")
+	flags.Float64Var(&args.EventQPS, "event-qps", args.EventQPS, "")
+	flags.StringSliceVar(&args.IgnoreCodes, "ignore-codes", args.IgnoreCodes, "")
+	flags.StringVar(&args.EventLevel, "event-level", args.EventLevel, "")
+	if err := flags.Parse([]string{"--event-level=FATAL"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := server.ApplyServerConfig(flags, map[string]interface{}{
+		"dockle-concurrency": float64(5),
+		"event-qps":          0.5,
+		"ignore-codes":       []interface{}{"CIS-DI-0001", "DKL-*"},
+		"event-level":        "INFO",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(int64(5), args.DockleConcurrency); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(0.5, args.EventQPS); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"CIS-DI-0001", "DKL-*"}, args.IgnoreCodes); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	// Flags given on the command line take precedence.
+	if diff := cmp.Diff("FATAL", args.EventLevel); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+
+	err := server.ApplyServerConfig(flags, map[string]interface{}{
+		"fake": 1,
+	})
+	if diff := cmp.Diff("server.fake: unknown setting", err.Error()); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"html/template"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/report"
+	"kube-dockle-exporter/pkg/server/collector"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	uiImagePath     = "/ui/images/"
+	worstImageLimit = 10
+)
+
+type levelCount struct {
+	Level string
+	Count int
+}
+
+type imageSummary struct {
+	Image string
+	Link  string
+	Fatal int
+	Warn  int
+	Info  int
+}
+
+func (s *imageSummary) add(level string) {
+	switch level {
+	case client.LevelFatal:
+		s.Fatal++
+	case client.LevelWarn:
+		s.Warn++
+	case client.LevelInfo:
+		s.Info++
+	}
+}
+
+type namespaceSummary struct {
+	Cluster   string
+	Namespace string
+	Workloads int
+	Images    int
+	Fatal     int
+	Warn      int
+	Info      int
+}
+
+type dashboardView struct {
+	ScannedAt   time.Time
+	Images      int
+	Levels      []levelCount
+	WorstImages []imageSummary
+	Namespaces  []namespaceSummary
+}
+
+type imageView struct {
+	ScannedAt time.Time
+	Image     string
+	Workloads []client.Workload
+	Details   []client.DockleDetail
+}
+
+func newDashboardView(snapshot *collector.Snapshot) *dashboardView {
+	view := &dashboardView{
+		ScannedAt: snapshot.ScannedAt,
+		Images:    len(snapshot.Responses),
+	}
+
+	counts := make(map[string]int)
+	images := make(map[string]*imageSummary)
+	for _, finding := range snapshot.Findings() {
+		counts[finding.Level]++
+		summary, ok := images[finding.Image]
+		if !ok {
+			summary = &imageSummary{
+				Image: finding.Image,
+				Link:  uiImagePath + finding.Image,
+			}
+			images[finding.Image] = summary
+		}
+		summary.add(finding.Level)
+	}
+	for _, level := range []string{client.LevelFatal, client.LevelWarn, client.LevelInfo, client.LevelSkip} {
+		view.Levels = append(view.Levels, levelCount{
+			Level: level,
+			Count: counts[level],
+		})
+	}
+
+	for _, summary := range images {
+		view.WorstImages = append(view.WorstImages, *summary)
+	}
+	sort.Slice(view.WorstImages, func(i, j int) bool {
+		a, b := view.WorstImages[i], view.WorstImages[j]
+		if a.Fatal != b.Fatal {
+			return a.Fatal > b.Fatal
+		}
+		if a.Warn != b.Warn {
+			return a.Warn > b.Warn
+		}
+		if a.Info != b.Info {
+			return a.Info > b.Info
+		}
+		return a.Image < b.Image
+	})
+	if len(view.WorstImages) > worstImageLimit {
+		view.WorstImages = view.WorstImages[:worstImageLimit]
+	}
+
+	// Namespaces of the same name in different clusters are different namespaces.
+	namespaces := make(map[string]*namespaceSummary)
+	namespaceImages := make(map[string]map[string]bool)
+	for _, workload := range snapshot.Workloads {
+		key := workload.Cluster + " " + workload.Namespace
+		summary, ok := namespaces[key]
+		if !ok {
+			summary = &namespaceSummary{
+				Cluster:   workload.Cluster,
+				Namespace: workload.Namespace,
+			}
+			namespaces[key] = summary
+			namespaceImages[key] = make(map[string]bool)
+		}
+		summary.Workloads++
+		for _, image := range workload.Images() {
+			if namespaceImages[key][image] {
+				continue
+			}
+			namespaceImages[key][image] = true
+			summary.Images++
+			if counted, ok := images[image]; ok {
+				summary.Fatal += counted.Fatal
+				summary.Warn += counted.Warn
+				summary.Info += counted.Info
+			}
+		}
+	}
+	for _, summary := range namespaces {
+		view.Namespaces = append(view.Namespaces, *summary)
+	}
+	sort.Slice(view.Namespaces, func(i, j int) bool {
+		a, b := view.Namespaces[i], view.Namespaces[j]
+		if a.Cluster != b.Cluster {
+			return a.Cluster < b.Cluster
+		}
+		return a.Namespace < b.Namespace
+	})
+
+	return view
+}
+
+func mustParseTemplate(content string) *template.Template {
+	return template.Must(template.Must(template.New("layout").Funcs(template.FuncMap{
+		"checkpointURL": report.CheckpointURL,
+	}).Parse(layoutTemplate)).Parse(content))
+}
+
+type DashboardHandler struct {
+	collector ICollector
+	template  *template.Template
+}
+
+func NewDashboardHandler(collector ICollector) *DashboardHandler {
+	return &DashboardHandler{
+		collector: collector,
+		template:  mustParseTemplate(dashboardTemplate),
+	}
+}
+
+func (h *DashboardHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snapshot := h.collector.Snapshot()
+	if snapshot == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err := h.template.ExecuteTemplate(w, "layout", newDashboardView(snapshot)); err != nil {
+		client.GetRequestLogger(r.Context()).Errorf("Failed to render dashboard: %s\n", err.Error())
+	}
+}
+
+type ImagePageHandler struct {
+	collector ICollector
+	template  *template.Template
+}
+
+func NewImagePageHandler(collector ICollector) *ImagePageHandler {
+	return &ImagePageHandler{
+		collector: collector,
+		template:  mustParseTemplate(imageTemplate),
+	}
+}
+
+func (h *ImagePageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snapshot := h.collector.Snapshot()
+	if snapshot == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	image := mux.Vars(r)["ref"]
+	response, ok := snapshot.Responses[image]
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	details := make([]client.DockleDetail, len(response.Details))
+	copy(details, response.Details)
+	sort.SliceStable(details, func(i, j int) bool {
+		return client.LevelSeverity(details[i].Level) > client.LevelSeverity(details[j].Level)
+	})
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err := h.template.ExecuteTemplate(w, "layout", &imageView{
+		ScannedAt: snapshot.ScannedAt,
+		Image:     image,
+		Workloads: snapshot.WorkloadsOf(image),
+		Details:   details,
+	}); err != nil {
+		client.GetRequestLogger(r.Context()).Errorf("Failed to render image page: %s\n", err.Error())
+	}
+}
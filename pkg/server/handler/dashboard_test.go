@@ -0,0 +1,131 @@
+package handler_test
+
+import (
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"kube-dockle-exporter/pkg/server/handler"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestDashboardHandler(t *testing.T) {
+	dashboardCollector := &collectorMock{
+		fakeSnapshot: func() *collector.Snapshot {
+			snapshot := fakeCollector().Snapshot()
+			snapshot.Workloads = []client.Workload{
+				{
+					Kind:      "Deployment",
+					Namespace: "fake-namespace",
+					Name:      "fake-deployment",
+					PodSpec: v1.PodSpec{
+						Containers: []v1.Container{
+							{
+								Image: "docker.io/fake:latest",
+							},
+						},
+					},
+				},
+				{
+					Cluster:   "remote",
+					Kind:      "Deployment",
+					Namespace: "fake-namespace",
+					Name:      "fake-deployment",
+					PodSpec: v1.PodSpec{
+						Containers: []v1.Container{
+							{
+								Image: "docker.io/fake:latest",
+							},
+						},
+					},
+				},
+			}
+			return snapshot
+		},
+	}
+
+	type want struct {
+		code     int
+		contains []string
+	}
+
+	tests := []struct {
+		name     string
+		receiver http.Handler
+		in       *http.Request
+		want     want
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewDashboardHandler(dashboardCollector),
+			httptest.NewRequest("GET", "/", nil),
+			want{
+				http.StatusOK,
+				[]string{
+					`<tr><td>1</td><td>0</td><td>1</td><td>0</td><td>0</td></tr>`,
+					`<tr><td><a href="/ui/images/docker.io/fake:latest">docker.io/fake:latest</a></td><td>0</td><td>1</td><td>0</td></tr>`,
+					`<tr><td></td><td>fake-namespace</td><td>1</td><td>1</td><td>0</td><td>1</td><td>0</td></tr>`,
+					`<tr><td>remote</td><td>fake-namespace</td><td>1</td><td>1</td><td>0</td><td>1</td><td>0</td></tr>`,
+				},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewImagePageHandler(dashboardCollector),
+			mux.SetURLVars(httptest.NewRequest("GET", "/ui/images/docker.io/fake:latest", nil), map[string]string{"ref": "docker.io/fake:latest"}),
+			want{
+				http.StatusOK,
+				[]string{
+					`<tr><td>fake-namespace</td><td>Deployment</td><td>fake-deployment</td></tr>`,
+					`<td>Avoid latest tag</td><td><span class="level WARN">WARN</span></td>`,
+				},
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewImagePageHandler(dashboardCollector),
+			mux.SetURLVars(httptest.NewRequest("GET", "/ui/images/unknown", nil), map[string]string{"ref": "unknown"}),
+			want{
+				http.StatusNotFound,
+				nil,
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := httptest.NewRecorder()
+
+		name := tt.name
+		receiver := tt.receiver
+		in := tt.in
+		want := tt.want
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			receiver.ServeHTTP(got, in)
+			if diff := cmp.Diff(want.code, got.Code); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			for _, s := range want.contains {
+				if !strings.Contains(got.Body.String(), s) {
+					t.Errorf("%q is not contained in:\n%s", s, got.Body.String())
+				}
+			}
+		})
+	}
+}
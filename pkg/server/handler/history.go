@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/json"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+var nowFunc = time.Now // nolint:gochecknoglobals
+
+func parseTime(r *http.Request, key string, defaultValue time.Time) (time.Time, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		client.GetRequestLogger(r.Context()).Errorf("Failed to write response: %s\n", err.Error())
+	}
+}
+
+type imageHistoryEntry struct {
+	ScannedAt time.Time             `json:"scannedAt"`
+	Details   []client.DockleDetail `json:"details"`
+}
+
+type ImageHistoryHandler struct {
+	store IHistoryStore
+}
+
+func NewImageHistoryHandler(store IHistoryStore) *ImageHistoryHandler {
+	return &ImageHistoryHandler{
+		store: store,
+	}
+}
+
+func (h *ImageHistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	from, err := parseTime(r, "from", time.Unix(0, 0))
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	to, err := parseTime(r, "to", nowFunc())
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	image := mux.Vars(r)["ref"]
+	entries, err := h.store.ImageEntries(image, from, to)
+	if err != nil {
+		client.GetRequestLogger(r.Context()).Errorf("Failed to read history: %s\n", err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	history := make([]imageHistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		for _, response := range entry.Responses {
+			history = append(history, imageHistoryEntry{
+				ScannedAt: entry.ScannedAt,
+				Details:   response.Details,
+			})
+		}
+	}
+	if len(history) == 0 {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, r, history)
+}
+
+type diffResponse struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// Failed holds images which failed to be scanned in either entry, whose findings are not compared.
+	Failed []string `json:"failed,omitempty"`
+	*collector.FindingDiff
+}
+
+// scannedResponses returns responses of images which failed in neither entry.
+func scannedResponses(responses []client.DockleResponse, failed map[string]bool) []client.DockleResponse {
+	scanned := make([]client.DockleResponse, 0, len(responses))
+	for _, response := range responses {
+		if !failed[response.ExtractImage()] {
+			scanned = append(scanned, response)
+		}
+	}
+	return scanned
+}
+
+type DiffHandler struct {
+	store IHistoryStore
+}
+
+func NewDiffHandler(store IHistoryStore) *DiffHandler {
+	return &DiffHandler{
+		store: store,
+	}
+}
+
+func (h *DiffHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("from") == "" {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	from, err := parseTime(r, "from", time.Time{})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	to, err := parseTime(r, "to", nowFunc())
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	fromEntry, err := h.store.At(from)
+	if err != nil {
+		client.GetRequestLogger(r.Context()).Errorf("Failed to read history: %s\n", err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	toEntry, err := h.store.At(to)
+	if err != nil {
+		client.GetRequestLogger(r.Context()).Errorf("Failed to read history: %s\n", err.Error())
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if fromEntry == nil || toEntry == nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	// Findings of images which failed in either entry are unknown there, and are neither introduced nor resolved.
+	failed := make(map[string]bool)
+	var failedImages []string
+	for _, image := range append(append([]string{}, fromEntry.Failed...), toEntry.Failed...) {
+		if !failed[image] {
+			failed[image] = true
+			failedImages = append(failedImages, image)
+		}
+	}
+	sort.Strings(failedImages)
+	writeJSON(w, r, &diffResponse{
+		From:   fromEntry.ScannedAt,
+		To:     toEntry.ScannedAt,
+		Failed: failedImages,
+		FindingDiff: collector.DiffFindings(
+			collector.FindingsOf(scannedResponses(fromEntry.Responses, failed)),
+			collector.FindingsOf(scannedResponses(toEntry.Responses, failed)),
+		),
+	})
+}
@@ -0,0 +1,281 @@
+package handler_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/handler"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/gorilla/mux"
+)
+
+type historyStoreMock struct {
+	handler.IHistoryStore
+	entries []client.HistoryEntry
+}
+
+func (m *historyStoreMock) ImageEntries(image string, from time.Time, to time.Time) ([]client.HistoryEntry, error) {
+	var entries []client.HistoryEntry
+	for _, entry := range m.entries {
+		if entry.ScannedAt.Before(from) || entry.ScannedAt.After(to) {
+			continue
+		}
+		var responses []client.DockleResponse
+		for _, response := range entry.Responses {
+			if response.ExtractImage() == image {
+				responses = append(responses, response)
+			}
+		}
+		if len(responses) > 0 {
+			entries = append(entries, client.HistoryEntry{ScannedAt: entry.ScannedAt, Responses: responses})
+		}
+	}
+	return entries, nil
+}
+
+func (m *historyStoreMock) At(t time.Time) (*client.HistoryEntry, error) {
+	var found *client.HistoryEntry
+	for i := range m.entries {
+		if !m.entries[i].ScannedAt.After(t) {
+			found = &m.entries[i]
+		}
+	}
+	return found, nil
+}
+
+func fakeHistoryStore() *historyStoreMock {
+	return &historyStoreMock{
+		entries: []client.HistoryEntry{
+			{
+				ScannedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+				Responses: []client.DockleResponse{
+					{
+						Target: "fake",
+						Details: []client.DockleDetail{
+							{Code: "resolved", Level: "WARN"},
+							{Code: "unchanged", Level: "WARN"},
+						},
+					},
+				},
+			},
+			{
+				ScannedAt: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+				Responses: []client.DockleResponse{
+					{
+						Target: "fake",
+						Details: []client.DockleDetail{
+							{Code: "introduced", Level: "FATAL"},
+							{Code: "unchanged", Level: "WARN"},
+						},
+					},
+				},
+			},
+			{
+				ScannedAt: time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC),
+				Responses: []client.DockleResponse{
+					{
+						Target: "other",
+						Details: []client.DockleDetail{
+							{Code: "introduced", Level: "WARN"},
+						},
+					},
+				},
+				Failed: []string{"fake"},
+			},
+		},
+	}
+}
+
+func TestImageHistoryHandlerWithoutFrom(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := client.NewHistoryStore(filepath.Join(dir, "history.db"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	for _, entry := range fakeHistoryStore().entries {
+		entry := entry
+		if err := store.Put(&entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := httptest.NewRecorder()
+	handler.NewImageHistoryHandler(store).ServeHTTP(got, mux.SetURLVars(httptest.NewRequest("GET", "/api/v1/images/fake/history", nil), map[string]string{"ref": "fake"}))
+	want := &httptest.ResponseRecorder{
+		Code:      http.StatusOK,
+		HeaderMap: http.Header{"Content-Type": {"application/json"}},
+		Body:      bytes.NewBufferString(`[{"scannedAt":"2020-01-01T00:00:00Z","details":[{"code":"resolved","title":"","level":"WARN","alerts":null},{"code":"unchanged","title":"","level":"WARN","alerts":null}]},{"scannedAt":"2020-01-02T00:00:00Z","details":[{"code":"introduced","title":"","level":"FATAL","alerts":null},{"code":"unchanged","title":"","level":"WARN","alerts":null}]}]` + "\n"),
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(*got), cmp.AllowUnexported(*got.Body)); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func TestHistoryHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		receiver     http.Handler
+		in           *http.Request
+		want         *httptest.ResponseRecorder
+		optsFunction func(interface{}) cmp.Option
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewImageHistoryHandler(fakeHistoryStore()),
+			mux.SetURLVars(httptest.NewRequest("GET", "/api/v1/images/fake/history?from=2020-01-02T00:00:00Z&to=2020-01-03T00:00:00Z", nil), map[string]string{"ref": "fake"}),
+			&httptest.ResponseRecorder{
+				Code:      http.StatusOK,
+				HeaderMap: http.Header{"Content-Type": {"application/json"}},
+				Body:      bytes.NewBufferString(`[{"scannedAt":"2020-01-02T00:00:00Z","details":[{"code":"introduced","title":"","level":"FATAL","alerts":null},{"code":"unchanged","title":"","level":"WARN","alerts":null}]}]` + "\n"),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewDiffHandler(fakeHistoryStore()),
+			httptest.NewRequest("GET", "/api/v1/diff?from=2020-01-01T12:00:00Z&to=2020-01-02T12:00:00Z", nil),
+			&httptest.ResponseRecorder{
+				Code:      http.StatusOK,
+				HeaderMap: http.Header{"Content-Type": {"application/json"}},
+				Body:      bytes.NewBufferString(`{"from":"2020-01-01T00:00:00Z","to":"2020-01-02T00:00:00Z","introduced":[{"image":"fake","code":"introduced","title":"","level":"FATAL"}],"resolved":[{"image":"fake","code":"resolved","title":"","level":"WARN"}],"unchanged":[{"image":"fake","code":"unchanged","title":"","level":"WARN"}]}` + "\n"),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewDiffHandler(fakeHistoryStore()),
+			httptest.NewRequest("GET", "/api/v1/diff?from=2020-01-02T12:00:00Z&to=2020-01-03T12:00:00Z", nil),
+			&httptest.ResponseRecorder{
+				Code:      http.StatusOK,
+				HeaderMap: http.Header{"Content-Type": {"application/json"}},
+				Body:      bytes.NewBufferString(`{"from":"2020-01-02T00:00:00Z","to":"2020-01-03T00:00:00Z","failed":["fake"],"introduced":[{"image":"other","code":"introduced","title":"","level":"WARN"}],"resolved":null,"unchanged":null}` + "\n"),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewDiffHandler(fakeHistoryStore()),
+			httptest.NewRequest("GET", "/api/v1/diff", nil),
+			&httptest.ResponseRecorder{
+				Code: http.StatusBadRequest,
+				HeaderMap: http.Header{
+					"Content-Type":           {"text/plain; charset=utf-8"},
+					"X-Content-Type-Options": {"nosniff"},
+				},
+				Body: bytes.NewBufferString("Bad Request\n"),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewDiffHandler(fakeHistoryStore()),
+			httptest.NewRequest("GET", "/api/v1/diff?from=2019-01-01T00:00:00Z", nil),
+			&httptest.ResponseRecorder{
+				Code: http.StatusNotFound,
+				HeaderMap: http.Header{
+					"Content-Type":           {"text/plain; charset=utf-8"},
+					"X-Content-Type-Options": {"nosniff"},
+				},
+				Body: bytes.NewBufferString("Not Found\n"),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := httptest.NewRecorder()
+
+		name := tt.name
+		receiver := tt.receiver
+		in := tt.in
+		want := tt.want
+		optsFunction := tt.optsFunction
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			receiver.ServeHTTP(got, in)
+			if diff := cmp.Diff(want, got, optsFunction(got)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/report"
+	"kube-dockle-exporter/pkg/server/collector"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	formatJSON  = "json"
+	formatSARIF = "sarif"
+)
+
+func writeResponses(w http.ResponseWriter, r *http.Request, responses []client.DockleResponse, value interface{}) {
+	switch r.URL.Query().Get("format") {
+	case "", formatJSON:
+		writeJSON(w, r, value)
+	case formatSARIF:
+		w.Header().Set("Content-Type", "application/sarif+json")
+		w.WriteHeader(http.StatusOK)
+		if err := report.WriteSARIF(w, responses); err != nil {
+			client.GetRequestLogger(r.Context()).Errorf("Failed to write response: %s\n", err.Error())
+		}
+	default:
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+	}
+}
+
+type ImagesHandler struct {
+	collector ICollector
+}
+
+func NewImagesHandler(collector ICollector) *ImagesHandler {
+	return &ImagesHandler{
+		collector: collector,
+	}
+}
+
+func (h *ImagesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snapshot := h.collector.Snapshot()
+	if snapshot == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	responses := snapshot.SortedResponses()
+	writeResponses(w, r, responses, responses)
+}
+
+type ImageHandler struct {
+	collector ICollector
+}
+
+func NewImageHandler(collector ICollector) *ImageHandler {
+	return &ImageHandler{
+		collector: collector,
+	}
+}
+
+func (h *ImageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snapshot := h.collector.Snapshot()
+	if snapshot == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	response, ok := snapshot.Responses[mux.Vars(r)["ref"]]
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	writeResponses(w, r, []client.DockleResponse{response}, response)
+}
+
+type SuppressedHandler struct {
+	collector ICollector
+}
+
+func NewSuppressedHandler(collector ICollector) *SuppressedHandler {
+	return &SuppressedHandler{
+		collector: collector,
+	}
+}
+
+func (h *SuppressedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snapshot := h.collector.Snapshot()
+	if snapshot == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	suppressed := snapshot.Suppressed
+	if suppressed == nil {
+		suppressed = []collector.SuppressedFinding{}
+	}
+	writeJSON(w, r, suppressed)
+}
+
+type ViolationsHandler struct {
+	collector ICollector
+}
+
+func NewViolationsHandler(collector ICollector) *ViolationsHandler {
+	return &ViolationsHandler{
+		collector: collector,
+	}
+}
+
+func (h *ViolationsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snapshot := h.collector.Snapshot()
+	if snapshot == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	violations := snapshot.Violations
+	if violations == nil {
+		violations = []collector.PolicyViolation{}
+	}
+	writeJSON(w, r, violations)
+}
+
+type OwnersHandler struct {
+	collector ICollector
+}
+
+func NewOwnersHandler(collector ICollector) *OwnersHandler {
+	return &OwnersHandler{
+		collector: collector,
+	}
+}
+
+// ServeHTTP serves owners of namespaces, narrowed down by query parameters of keys of owners, e.g. ?team=payments.
+func (h *OwnersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snapshot := h.collector.Snapshot()
+	if snapshot == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	query := r.URL.Query()
+	owners := []collector.NamespaceOwner{}
+	for _, owner := range snapshot.Owners {
+		matched := true
+		for key := range query {
+			if value, ok := owner.Owner[key]; !ok || value != query.Get(key) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			owners = append(owners, owner)
+		}
+	}
+	writeJSON(w, r, owners)
+}
+
+type FindingsHandler struct {
+	collector ICollector
+}
+
+func NewFindingsHandler(collector ICollector) *FindingsHandler {
+	return &FindingsHandler{
+		collector: collector,
+	}
+}
+
+// ServeHTTP serves findings of each workload, filtered by ?status=effective or ?status=mitigated if given.
+func (h *FindingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snapshot := h.collector.Snapshot()
+	if snapshot == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	if status != "" && status != collector.StatusEffective && status != collector.StatusMitigated {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	findings := make([]collector.CorrelatedFinding, 0, len(snapshot.Correlated))
+	for _, finding := range snapshot.Correlated {
+		if status == "" || finding.Status == status {
+			findings = append(findings, finding)
+		}
+	}
+	writeJSON(w, r, findings)
+}
+
+type RiskHandler struct {
+	collector ICollector
+}
+
+func NewRiskHandler(collector ICollector) *RiskHandler {
+	return &RiskHandler{
+		collector: collector,
+	}
+}
+
+// ServeHTTP serves risk scores with their breakdowns, narrowed down to a namespace by ?namespace= if given.
+func (h *RiskHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	snapshot := h.collector.Snapshot()
+	if snapshot == nil || snapshot.Risk == nil {
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+		return
+	}
+	scores := snapshot.Risk
+	if namespace := r.URL.Query().Get("namespace"); namespace != "" {
+		scores = &collector.RiskScores{
+			Images:     []collector.ImageRisk{},
+			Workloads:  []collector.WorkloadRisk{},
+			Namespaces: []collector.NamespaceRisk{},
+		}
+		images := make(map[string]bool)
+		for _, workload := range snapshot.Risk.Workloads {
+			if workload.Workload.Namespace != namespace {
+				continue
+			}
+			scores.Workloads = append(scores.Workloads, workload)
+			for _, finding := range workload.Findings {
+				images[workload.Workload.Cluster+" "+finding.Image] = true
+			}
+		}
+		for _, image := range snapshot.Risk.Images {
+			if images[image.Cluster+" "+image.Image] {
+				scores.Images = append(scores.Images, image)
+			}
+		}
+		for _, risk := range snapshot.Risk.Namespaces {
+			if risk.Namespace == namespace {
+				scores.Namespaces = append(scores.Namespaces, risk)
+			}
+		}
+	}
+	writeJSON(w, r, scores)
+}
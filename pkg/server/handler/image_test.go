@@ -0,0 +1,500 @@
+package handler_test
+
+import (
+	"bytes"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"kube-dockle-exporter/pkg/server/handler"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/gorilla/mux"
+)
+
+type collectorMock struct {
+	handler.ICollector
+	fakeSnapshot func() *collector.Snapshot
+}
+
+func (m *collectorMock) Snapshot() *collector.Snapshot {
+	return m.fakeSnapshot()
+}
+
+func fakeCollector() *collectorMock {
+	return &collectorMock{
+		fakeSnapshot: func() *collector.Snapshot {
+			return &collector.Snapshot{
+				Responses: map[string]client.DockleResponse{
+					"docker.io/fake:latest": {
+						Target: "docker.io/fake:latest",
+						Details: []client.DockleDetail{
+							{
+								Code:  "DKL-DI-0006",
+								Title: "Avoid latest tag",
+								Level: "WARN",
+							},
+						},
+					},
+				},
+			}
+		},
+	}
+}
+
+func TestImageHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		receiver     http.Handler
+		in           *http.Request
+		want         *httptest.ResponseRecorder
+		optsFunction func(interface{}) cmp.Option
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewImagesHandler(fakeCollector()),
+			httptest.NewRequest("GET", "/api/v1/images", nil),
+			&httptest.ResponseRecorder{
+				Code:      http.StatusOK,
+				HeaderMap: http.Header{"Content-Type": {"application/json"}},
+				Body:      bytes.NewBufferString(`[{"Target":"docker.io/fake:latest","summary":{"fatal":0,"warn":0,"info":0,"skip":0,"pass":0},"details":[{"code":"DKL-DI-0006","title":"Avoid latest tag","level":"WARN","alerts":null}]}]` + "\n"),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewImagesHandler(&collectorMock{
+				fakeSnapshot: func() *collector.Snapshot {
+					return nil
+				},
+			}),
+			httptest.NewRequest("GET", "/api/v1/images", nil),
+			&httptest.ResponseRecorder{
+				Code: http.StatusServiceUnavailable,
+				HeaderMap: http.Header{
+					"Content-Type":           {"text/plain; charset=utf-8"},
+					"X-Content-Type-Options": {"nosniff"},
+				},
+				Body: bytes.NewBufferString("Service Unavailable\n"),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewSuppressedHandler(&collectorMock{
+				fakeSnapshot: func() *collector.Snapshot {
+					return &collector.Snapshot{
+						Suppressed: []collector.SuppressedFinding{
+							{
+								Finding: collector.Finding{
+									Image: "docker.io/fake:latest",
+									Code:  "CIS-DI-0005",
+									Title: "Enable Content trust for Docker",
+									Level: "INFO",
+								},
+								Owner:   "security",
+								Reason:  "legacy",
+								Expires: time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC),
+							},
+						},
+					}
+				},
+			}),
+			httptest.NewRequest("GET", "/api/v1/suppressed", nil),
+			&httptest.ResponseRecorder{
+				Code:      http.StatusOK,
+				HeaderMap: http.Header{"Content-Type": {"application/json"}},
+				Body:      bytes.NewBufferString(`[{"image":"docker.io/fake:latest","code":"CIS-DI-0005","title":"Enable Content trust for Docker","level":"INFO","owner":"security","reason":"legacy","expires":"2020-10-01T00:00:00Z"}]` + "\n"),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewViolationsHandler(&collectorMock{
+				fakeSnapshot: func() *collector.Snapshot {
+					return &collector.Snapshot{
+						Violations: []collector.PolicyViolation{
+							{
+								Policy: "dockle.latest",
+								Workload: collector.WorkloadRef{
+									Kind:      "Deployment",
+									Namespace: "fake",
+									Name:      "fake",
+								},
+								Image:   "docker.io/fake:latest",
+								Message: "latest tag is not allowed",
+							},
+						},
+					}
+				},
+			}),
+			httptest.NewRequest("GET", "/api/v1/violations", nil),
+			&httptest.ResponseRecorder{
+				Code:      http.StatusOK,
+				HeaderMap: http.Header{"Content-Type": {"application/json"}},
+				Body:      bytes.NewBufferString(`[{"policy":"dockle.latest","workload":{"kind":"Deployment","namespace":"fake","name":"fake"},"image":"docker.io/fake:latest","message":"latest tag is not allowed"}]` + "\n"),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewFindingsHandler(&collectorMock{
+				fakeSnapshot: func() *collector.Snapshot {
+					return &collector.Snapshot{
+						Correlated: []collector.CorrelatedFinding{
+							{
+								Workload: collector.WorkloadRef{
+									Kind:      "Deployment",
+									Namespace: "fake",
+									Name:      "fake",
+								},
+								Finding: collector.Finding{
+									Image: "docker.io/fake:latest",
+									Code:  "CIS-DI-0001",
+									Title: "Create a user for the container",
+									Level: "WARN",
+								},
+								Status: "mitigated",
+								Rule:   "non-root",
+							},
+							{
+								Workload: collector.WorkloadRef{
+									Kind:      "Deployment",
+									Namespace: "fake",
+									Name:      "fake",
+								},
+								Finding: collector.Finding{
+									Image: "docker.io/fake:latest",
+									Code:  "CIS-DI-0005",
+									Title: "Enable Content trust for Docker",
+									Level: "INFO",
+								},
+								Status: "effective",
+							},
+						},
+					}
+				},
+			}),
+			httptest.NewRequest("GET", "/api/v1/findings?status=mitigated", nil),
+			&httptest.ResponseRecorder{
+				Code:      http.StatusOK,
+				HeaderMap: http.Header{"Content-Type": {"application/json"}},
+				Body:      bytes.NewBufferString(`[{"workload":{"kind":"Deployment","namespace":"fake","name":"fake"},"image":"docker.io/fake:latest","code":"CIS-DI-0001","title":"Create a user for the container","level":"WARN","status":"mitigated","rule":"non-root"}]` + "\n"),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewOwnersHandler(&collectorMock{
+				fakeSnapshot: func() *collector.Snapshot {
+					return &collector.Snapshot{
+						Owners: []collector.NamespaceOwner{
+							{Namespace: "default", Owner: map[string]string{}},
+							{Namespace: "fake", Owner: map[string]string{"team": "fake", "slack-channel": "#fake"}},
+						},
+					}
+				},
+			}),
+			httptest.NewRequest("GET", "/api/v1/owners?team=fake", nil),
+			&httptest.ResponseRecorder{
+				Code:      http.StatusOK,
+				HeaderMap: http.Header{"Content-Type": {"application/json"}},
+				Body:      bytes.NewBufferString(`[{"namespace":"fake","owner":{"slack-channel":"#fake","team":"fake"}}]` + "\n"),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewRiskHandler(&collectorMock{
+				fakeSnapshot: func() *collector.Snapshot {
+					return &collector.Snapshot{
+						Risk: &collector.RiskScores{
+							Images: []collector.ImageRisk{
+								{Image: "docker.io/fake:latest", Score: 5, Findings: []collector.RiskFinding{}},
+								{Image: "docker.io/other:latest", Score: 1, Findings: []collector.RiskFinding{}},
+							},
+							Workloads: []collector.WorkloadRisk{
+								{
+									Workload: collector.WorkloadRef{Kind: "Deployment", Namespace: "fake", Name: "fake"},
+									Score:    5,
+									Findings: []collector.WorkloadRiskFinding{
+										{
+											Image:       "docker.io/fake:latest",
+											RiskFinding: collector.RiskFinding{Code: "CIS-DI-0001", Level: "WARN", Weight: 5},
+											Score:       5,
+										},
+									},
+									Replicas: 1,
+									Exposure: 1,
+								},
+								{
+									Workload: collector.WorkloadRef{Kind: "Deployment", Namespace: "other", Name: "other"},
+									Score:    1,
+									Findings: []collector.WorkloadRiskFinding{},
+									Exposure: 1,
+								},
+							},
+							Namespaces: []collector.NamespaceRisk{
+								{Namespace: "fake", Score: 5, Workloads: []collector.NamespaceWorkloadRisk{}},
+								{Namespace: "other", Score: 1, Workloads: []collector.NamespaceWorkloadRisk{}},
+							},
+						},
+					}
+				},
+			}),
+			httptest.NewRequest("GET", "/api/v1/risk?namespace=fake", nil),
+			&httptest.ResponseRecorder{
+				Code:      http.StatusOK,
+				HeaderMap: http.Header{"Content-Type": {"application/json"}},
+				Body:      bytes.NewBufferString(`{"images":[{"image":"docker.io/fake:latest","score":5,"findings":[]}],"workloads":[{"workload":{"kind":"Deployment","namespace":"fake","name":"fake"},"score":5,"findings":[{"image":"docker.io/fake:latest","code":"CIS-DI-0001","level":"WARN","weight":5,"score":5}],"replicas":1,"exposure":1}],"namespaces":[{"namespace":"fake","score":5,"workloads":[]}]}` + "\n"),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewImagesHandler(fakeCollector()),
+			httptest.NewRequest("GET", "/api/v1/images?format=fake", nil),
+			&httptest.ResponseRecorder{
+				Code: http.StatusBadRequest,
+				HeaderMap: http.Header{
+					"Content-Type":           {"text/plain; charset=utf-8"},
+					"X-Content-Type-Options": {"nosniff"},
+				},
+				Body: bytes.NewBufferString("Bad Request\n"),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewImageHandler(fakeCollector()),
+			mux.SetURLVars(httptest.NewRequest("GET", "/api/v1/images/docker.io/fake:latest?format=sarif", nil), map[string]string{"ref": "docker.io/fake:latest"}),
+			&httptest.ResponseRecorder{
+				Code:      http.StatusOK,
+				HeaderMap: http.Header{"Content-Type": {"application/sarif+json"}},
+				Body: bytes.NewBufferString(`{
+  "version": "2.1.0",
+  "$schema": "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "dockle",
+          "informationUri": "https://github.com/goodwithtech/dockle",
+          "rules": [
+            {
+              "id": "DKL-DI-0006",
+              "name": "DKL-DI-0006",
+              "shortDescription": {
+                "text": "Avoid latest tag"
+              },
+              "fullDescription": {
+                "text": "Pin images to a specific tag or digest instead of latest."
+              },
+              "help": {
+                "text": "Pin images to a specific tag or digest instead of latest. See https://github.com/goodwithtech/dockle/blob/master/CHECKPOINT.md#dkl-di-0006"
+              },
+              "helpUri": "https://github.com/goodwithtech/dockle/blob/master/CHECKPOINT.md#dkl-di-0006",
+              "defaultConfiguration": {
+                "level": "warning"
+              }
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "DKL-DI-0006",
+          "level": "warning",
+          "message": {
+            "text": "Avoid latest tag"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "docker.io/fake:latest"
+                }
+              }
+            }
+          ],
+          "properties": {
+            "image": "docker.io/fake:latest",
+            "level": "WARN"
+          }
+        }
+      ]
+    }
+  ]
+}
+`),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			handler.NewImageHandler(fakeCollector()),
+			mux.SetURLVars(httptest.NewRequest("GET", "/api/v1/images/unknown", nil), map[string]string{"ref": "unknown"}),
+			&httptest.ResponseRecorder{
+				Code: http.StatusNotFound,
+				HeaderMap: http.Header{
+					"Content-Type":           {"text/plain; charset=utf-8"},
+					"X-Content-Type-Options": {"nosniff"},
+				},
+				Body: bytes.NewBufferString("Not Found\n"),
+			},
+			func(got interface{}) cmp.Option {
+				switch v := got.(type) {
+				case *httptest.ResponseRecorder:
+					return cmp.Options{
+						cmpopts.IgnoreUnexported(*v),
+						cmp.AllowUnexported(*v.Body),
+					}
+				default:
+					return nil
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := httptest.NewRecorder()
+
+		name := tt.name
+		receiver := tt.receiver
+		in := tt.in
+		want := tt.want
+		optsFunction := tt.optsFunction
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			receiver.ServeHTTP(got, in)
+			if diff := cmp.Diff(want, got, optsFunction(got)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
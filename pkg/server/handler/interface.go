@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
+	"time"
+)
+
+type ICollector interface {
+	Snapshot() *collector.Snapshot
+}
+
+type IHistoryStore interface {
+	ImageEntries(image string, from time.Time, to time.Time) ([]client.HistoryEntry, error)
+	At(time.Time) (*client.HistoryEntry, error)
+}
@@ -0,0 +1,70 @@
+package handler
+
+// Templates are kept in Go source so that the binary serves the UI without any external asset.
+
+const layoutTemplate = `{{define "layout"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{template "title" .}} - KubeDockleExporter</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292e; }
+h1, h2 { font-weight: 600; }
+a { color: #0366d6; text-decoration: none; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #e1e4e8; padding: 4px 12px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+.FATAL { color: #fff; background: #d73a49; }
+.WARN { color: #24292e; background: #ffd33d; }
+.INFO { color: #fff; background: #0366d6; }
+.SKIP, .PASS { color: #fff; background: #6a737d; }
+.level { padding: 0 6px; border-radius: 3px; font-size: 90%; }
+.summary td { font-size: 150%; text-align: center; }
+</style>
+</head>
+<body>
+<p><a href="/">KubeDockleExporter</a>{{if not .ScannedAt.IsZero}} &middot; scanned at {{.ScannedAt.UTC.Format "2006-01-02 15:04:05 MST"}}{{end}}</p>
+{{template "content" .}}
+</body>
+</html>
+{{end}}`
+
+const dashboardTemplate = `{{define "title"}}Cluster summary{{end}}
+{{define "content"}}
+<h1>Cluster summary</h1>
+<table class="summary">
+<tr><th>Images</th>{{range .Levels}}<th><span class="level {{.Level}}">{{.Level}}</span></th>{{end}}</tr>
+<tr><td>{{.Images}}</td>{{range .Levels}}<td>{{.Count}}</td>{{end}}</tr>
+</table>
+
+<h2>Worst images</h2>
+<table>
+<tr><th>Image</th><th>FATAL</th><th>WARN</th><th>INFO</th></tr>
+{{range .WorstImages}}<tr><td><a href="{{.Link}}">{{.Image}}</a></td><td>{{.Fatal}}</td><td>{{.Warn}}</td><td>{{.Info}}</td></tr>
+{{else}}<tr><td colspan="4">No findings</td></tr>
+{{end}}</table>
+
+<h2>Namespaces</h2>
+<table>
+<tr><th>Cluster</th><th>Namespace</th><th>Workloads</th><th>Images</th><th>FATAL</th><th>WARN</th><th>INFO</th></tr>
+{{range .Namespaces}}<tr><td>{{.Cluster}}</td><td>{{.Namespace}}</td><td>{{.Workloads}}</td><td>{{.Images}}</td><td>{{.Fatal}}</td><td>{{.Warn}}</td><td>{{.Info}}</td></tr>
+{{end}}</table>
+{{end}}`
+
+const imageTemplate = `{{define "title"}}{{.Image}}{{end}}
+{{define "content"}}
+<h1>{{.Image}}</h1>
+
+<h2>Workloads</h2>
+<table>
+<tr><th>Namespace</th><th>Kind</th><th>Name</th></tr>
+{{range .Workloads}}<tr><td>{{.Namespace}}</td><td>{{.Kind}}</td><td>{{.Name}}</td></tr>
+{{end}}</table>
+
+<h2>Checks</h2>
+<table>
+<tr><th>Code</th><th>Title</th><th>Level</th><th>Alerts</th></tr>
+{{range .Details}}<tr><td><a href="{{checkpointURL .Code}}">{{.Code}}</a></td><td>{{.Title}}</td><td><span class="level {{.Level}}">{{.Level}}</span></td><td>{{range .Alerts}}{{.}}<br>{{end}}</td></tr>
+{{else}}<tr><td colspan="4">No findings</td></tr>
+{{end}}</table>
+{{end}}`
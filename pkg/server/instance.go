@@ -15,6 +15,7 @@ const (
 type Instance struct {
 	processors       []IProcessor
 	kubernetesClient IKubernetesClient
+	dynamicClient    IDynamicClient
 	logger           ILogger
 }
 
@@ -32,6 +33,14 @@ func (i *Instance) SetKubernetesClient(kubernetesClient IKubernetesClient) {
 	i.kubernetesClient = kubernetesClient
 }
 
+func (i *Instance) DynamicClient() IDynamicClient {
+	return i.dynamicClient
+}
+
+func (i *Instance) SetDynamicClient(dynamicClient IDynamicClient) {
+	i.dynamicClient = dynamicClient
+}
+
 func (i *Instance) Logger() ILogger {
 	return i.logger
 }
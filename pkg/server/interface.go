@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -15,6 +16,10 @@ type IKubernetesClient interface {
 	kubernetes.Interface
 }
 
+type IDynamicClient interface {
+	dynamic.Interface
+}
+
 type ILogger interface {
 	Errorf(format string, v ...interface{})
 	Infof(format string, v ...interface{})
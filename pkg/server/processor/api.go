@@ -2,6 +2,7 @@ package processor
 
 import (
 	"context"
+	"kube-dockle-exporter/pkg/client"
 	"kube-dockle-exporter/pkg/server/handler"
 	"kube-dockle-exporter/pkg/server/middleware"
 	"net"
@@ -22,6 +23,8 @@ type APISettings struct {
 	KeepAlived           bool
 	ReUsePort            bool
 	TCPKeepAliveInterval time.Duration
+	Collector            ICollector
+	HistoryStore         *client.HistoryStore
 	Logger               ILogger
 }
 
@@ -41,6 +44,52 @@ func NewAPI(settings APISettings) (*API, error) {
 		"/health",
 		handler.NewHealthHandler(),
 	).Methods("GET")
+	router.Handle(
+		"/api/v1/images",
+		handler.NewImagesHandler(settings.Collector),
+	).Methods("GET")
+	router.Handle(
+		"/api/v1/suppressed",
+		handler.NewSuppressedHandler(settings.Collector),
+	).Methods("GET")
+	router.Handle(
+		"/api/v1/violations",
+		handler.NewViolationsHandler(settings.Collector),
+	).Methods("GET")
+	router.Handle(
+		"/api/v1/findings",
+		handler.NewFindingsHandler(settings.Collector),
+	).Methods("GET")
+	router.Handle(
+		"/api/v1/risk",
+		handler.NewRiskHandler(settings.Collector),
+	).Methods("GET")
+	router.Handle(
+		"/api/v1/owners",
+		handler.NewOwnersHandler(settings.Collector),
+	).Methods("GET")
+	if settings.HistoryStore != nil {
+		router.Handle(
+			"/api/v1/images/{ref:.+}/history",
+			handler.NewImageHistoryHandler(settings.HistoryStore),
+		).Methods("GET")
+		router.Handle(
+			"/api/v1/diff",
+			handler.NewDiffHandler(settings.HistoryStore),
+		).Methods("GET")
+	}
+	router.Handle(
+		"/api/v1/images/{ref:.+}",
+		handler.NewImageHandler(settings.Collector),
+	).Methods("GET")
+	router.Handle(
+		"/",
+		handler.NewDashboardHandler(settings.Collector),
+	).Methods("GET")
+	router.Handle(
+		"/ui/images/{ref:.+}",
+		handler.NewImagePageHandler(settings.Collector),
+	).Methods("GET")
 
 	var listener net.Listener
 	var err error
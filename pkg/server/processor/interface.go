@@ -1,13 +1,26 @@
 package processor
 
-import "k8s.io/client-go/kubernetes"
+import (
+	"kube-dockle-exporter/pkg/server/collector"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
 
 type IKubernetesClient interface {
 	kubernetes.Interface
 }
 
+type IDynamicClient interface {
+	dynamic.Interface
+}
+
 type ILogger interface {
 	Errorf(format string, v ...interface{})
 	Infof(format string, v ...interface{})
 	Debugf(format string, v ...interface{})
 }
+
+type ICollector interface {
+	Snapshot() *collector.Snapshot
+}
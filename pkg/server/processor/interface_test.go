@@ -0,0 +1,15 @@
+package processor_test
+
+import (
+	"kube-dockle-exporter/pkg/server/processor"
+)
+
+type loggerMock struct {
+	processor.ILogger
+}
+
+func (m *loggerMock) Errorf(format string, v ...interface{}) {}
+
+func (m *loggerMock) Infof(format string, v ...interface{}) {}
+
+func (m *loggerMock) Debugf(format string, v ...interface{}) {}
@@ -0,0 +1,79 @@
+package processor
+
+import (
+	"context"
+	"kube-dockle-exporter/pkg/server/collector"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/xerrors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// runLeaderElection campaigns for the lease until ctx is done, and scans only while leading.
+// Replicas which lost the lease drop their results after the scan in flight, become standby and campaign again.
+// The returned channel is closed once the lease is released and the results are dropped after ctx is done.
+func runLeaderElection(
+	ctx context.Context,
+	settings MonitorSettings,
+	dockleCollector *collector.DockleCollector,
+	leader prometheus.Gauge,
+) (<-chan struct{}, error) {
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta: metaV1.ObjectMeta{
+				Namespace: settings.LeaderElectionNamespace,
+				Name:      settings.LeaderElectionName,
+			},
+			Client: settings.KubernetesClient.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{
+				Identity: settings.LeaderElectionIdentity,
+			},
+		},
+		LeaseDuration:   settings.LeaderElectionLeaseDuration,
+		RenewDeadline:   settings.LeaderElectionRenewDeadline,
+		RetryPeriod:     settings.LeaderElectionRetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            settings.LeaderElectionName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				settings.Logger.Infof("Started leading as %s\n", settings.LeaderElectionIdentity)
+				leader.Set(1)
+				if err := dockleCollector.Scan(ctx); err != nil {
+					settings.Logger.Errorf("Failed to scan: %s\n", err.Error())
+				}
+				dockleCollector.StartLoop(ctx, settings.CollectorLoopInterval)
+			},
+			OnStoppedLeading: func() {
+				settings.Logger.Infof("Stopped leading as %s\n", settings.LeaderElectionIdentity)
+				leader.Set(0)
+				// Results of the former leader would go stale, while the new leader exports its own.
+				// Reset waits for the scan in flight, which stops publishing once the context of the term is done.
+				// Publishers implementing IResetter, e.g. the ownership collector, are reset along with it.
+				dockleCollector.Reset()
+			},
+			OnNewLeader: func(identity string) {
+				settings.Logger.Infof("Leader is %s\n", identity)
+			},
+		},
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("could not create leader elector: %w", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			elector.Run(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(settings.LeaderElectionRetryPeriod):
+			}
+		}
+	}()
+	return done, nil
+}
@@ -2,12 +2,14 @@ package processor
 
 import (
 	"context"
+	"io/ioutil"
 	"kube-dockle-exporter/pkg/client"
 	"kube-dockle-exporter/pkg/server/collector"
 	"net"
 	"net/http"
 	"net/http/pprof"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
@@ -28,25 +30,79 @@ const (
 )
 
 type MonitorSettings struct {
-	Address               string
-	MaxConnections        int64
-	JaegerEndpoint        string
-	EnableProfiling       bool
-	EnableTracing         bool
-	TracingSampleRate     float64
-	KeepAlived            bool
-	ReUsePort             bool
-	TCPKeepAliveInterval  time.Duration
-	DockleConcurrency     int64
-	CollectorLoopInterval time.Duration
-	KubernetesClient      IKubernetesClient
-	Logger                ILogger
+	Address                     string
+	MaxConnections              int64
+	JaegerEndpoint              string
+	EnableProfiling             bool
+	EnableTracing               bool
+	TracingSampleRate           float64
+	KeepAlived                  bool
+	ReUsePort                   bool
+	TCPKeepAliveInterval        time.Duration
+	DockleConcurrency           int64
+	IgnoreCodes                 []string
+	IgnoreImages                []string
+	Registries                  []client.Registry
+	RoutingConfig               *collector.RoutingConfig
+	Exceptions                  []collector.ExceptionConfig
+	Severities                  []collector.SeverityConfig
+	Risk                        *collector.RiskConfig
+	PolicyPaths                 []string
+	DisableCorrelationRules     []string
+	NamespaceOwnerLabels        []string
+	NamespaceOwnerAnnotations   []string
+	CollectorLoopInterval       time.Duration
+	ClusterName                 string
+	RemoteClusters              []client.Cluster
+	LeaderElect                 bool
+	LeaderElectionNamespace     string
+	LeaderElectionName          string
+	LeaderElectionIdentity      string
+	LeaderElectionLeaseDuration time.Duration
+	LeaderElectionRenewDeadline time.Duration
+	LeaderElectionRetryPeriod   time.Duration
+	Shard                       bool
+	ShardNamespace              string
+	ShardStatefulSetName        string
+	ShardIndex                  int
+	EnablePolicyReport          bool
+	PolicyReportScope           string
+	EnableEvents                bool
+	EventLevel                  string
+	EventQPS                    float32
+	EventBurst                  int
+	HistoryStore                *client.HistoryStore
+	NotificationLevel           string
+	NotificationConfig          string
+	SlackWebhookURL             string
+	SlackRateLimit              float64
+	SlackRetries                int
+	ExternalURL                 string
+	WebhookURL                  string
+	WebhookMode                 string
+	WebhookSecret               string
+	WebhookSource               string
+	WebhookRetries              int
+	WebhookOutbox               *client.Outbox
+	AlertmanagerURL             string
+	AlertmanagerLevel           string
+	KubernetesClient            IKubernetesClient
+	DynamicClient               IDynamicClient
+	Logger                      ILogger
 }
 
 type Monitor struct {
 	maxConnections int64
 	listener       net.Listener
 	server         *http.Server
+	collector      *collector.DockleCollector
+	dockleClient   *client.DockleClient
+	notifier       *collector.ReloadableNotifier
+	settings       MonitorSettings
+	eventRecorder  *client.EventRecorder
+	cancel         context.CancelFunc
+	// elected is closed once leader election stops, or is nil without leader election.
+	elected <-chan struct{}
 }
 
 func NewMonitor(settings MonitorSettings) (*Monitor, error) {
@@ -55,21 +111,182 @@ func NewMonitor(settings MonitorSettings) (*Monitor, error) {
 	registry := prometheus.NewRegistry()
 	registry.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
 	registry.MustRegister(prometheus.NewGoCollector())
+	clusters := []client.Cluster{
+		{
+			Name: settings.ClusterName,
+			Client: &client.KubernetesClient{
+				Inner: settings.KubernetesClient,
+			},
+		},
+	}
+	names := map[string]bool{settings.ClusterName: true}
+	for _, cluster := range settings.RemoteClusters {
+		if names[cluster.Name] {
+			return nil, xerrors.Errorf("duplicate cluster: %s", cluster.Name)
+		}
+		names[cluster.Name] = true
+		clusters = append(clusters, cluster)
+	}
+	dockleClient := &client.DockleClient{}
+	dockleClient.SetRegistries(settings.Registries)
+	multiClusterClient := &client.MultiClusterClient{
+		Clusters: clusters,
+	}
 	dockleCollector := collector.NewDockleCollector(
 		settings.Logger,
-		&client.KubernetesClient{
-			Inner: settings.KubernetesClient,
-		},
-		&client.DockleClient{},
+		multiClusterClient,
+		dockleClient,
 		settings.DockleConcurrency,
 	)
 	registry.MustRegister(dockleCollector)
-	ctx := context.Background()
-	if err := dockleCollector.Scan(ctx); err != nil {
-		return nil, xerrors.Errorf("failed to scan of dockle collector: %w", err)
+	dockleCollector.SetNamespaces(multiClusterClient)
+	ownership, err := collector.NewOwnership(
+		settings.NamespaceOwnerLabels,
+		settings.NamespaceOwnerAnnotations,
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create ownership: %w", err)
 	}
-	dockleCollector.StartLoop(ctx, settings.CollectorLoopInterval)
-
+	dockleCollector.SetOwnership(ownership)
+	// Labels of owners are fixed on start, since metrics cannot change their labels.
+	ownershipCollector := collector.NewOwnershipCollector(ownership)
+	registry.MustRegister(ownershipCollector)
+	dockleCollector.AddPublisher(ownershipCollector)
+	ignore, err := collector.NewIgnore(settings.IgnoreCodes, settings.IgnoreImages)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create ignore: %w", err)
+	}
+	dockleCollector.SetIgnore(ignore)
+	exceptions, err := collector.NewExceptions(settings.Exceptions)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create exceptions: %w", err)
+	}
+	dockleCollector.SetExceptions(exceptions)
+	severities, err := collector.NewSeverities(settings.Severities)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create severities: %w", err)
+	}
+	dockleCollector.SetSeverities(severities)
+	risk, err := collector.NewRisk(settings.Risk)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create risk: %w", err)
+	}
+	dockleCollector.SetRisk(risk)
+	correlator, err := collector.NewCorrelator(collector.DefaultCorrelationRules(), settings.DisableCorrelationRules)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create correlator: %w", err)
+	}
+	dockleCollector.SetCorrelator(correlator)
+	if len(settings.PolicyPaths) > 0 {
+		policies, err := collector.LoadPolicies(context.Background(), settings.PolicyPaths)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to create policies: %w", err)
+		}
+		dockleCollector.SetPolicies(policies)
+	}
+	if settings.Shard {
+		// Replicas share neither the lease of the leader nor PolicyReports, which cover all images of namespaces.
+		if settings.LeaderElect {
+			return nil, xerrors.New("sharding cannot be combined with leader election")
+		}
+		if settings.EnablePolicyReport {
+			return nil, xerrors.New("sharding cannot be combined with PolicyReport")
+		}
+		dockleCollector.SetImageFilter(collector.NewShardFilter(
+			&client.KubernetesClient{
+				Inner: settings.KubernetesClient,
+			},
+			settings.ShardNamespace,
+			settings.ShardStatefulSetName,
+			settings.ShardIndex,
+		))
+	}
+	if settings.EnablePolicyReport {
+		policyReportPublisher, err := collector.NewPolicyReportPublisher(
+			&client.PolicyReportClient{
+				Inner: settings.DynamicClient,
+			},
+			settings.PolicyReportScope,
+		)
+		if err != nil {
+			return nil, xerrors.Errorf("could not set up policy report publisher: %w", err)
+		}
+		dockleCollector.AddPublisher(policyReportPublisher)
+	}
+	var eventRecorder *client.EventRecorder
+	if settings.EnableEvents {
+		eventRecorder = client.NewEventRecorder(settings.KubernetesClient, settings.EventQPS, settings.EventBurst)
+		dockleCollector.AddPublisher(collector.NewEventPublisher(eventRecorder, settings.EventLevel))
+	}
+	var notifier *collector.ReloadableNotifier
+	if settings.RoutingConfig == nil && settings.NotificationConfig != "" {
+		config, err := collector.LoadRoutingConfig(settings.NotificationConfig)
+		if err != nil {
+			return nil, xerrors.Errorf("could not load notification config: %w", err)
+		}
+		settings.RoutingConfig = config
+	}
+	if settings.RoutingConfig == nil && settings.SlackWebhookURL != "" {
+		notificationPublisher := collector.NewNotificationPublisher(settings.NotificationLevel)
+		notificationPublisher.AddNotifier(collector.NewSlackNotifier(
+			client.NewSlackClient(settings.SlackWebhookURL, settings.SlackRateLimit, settings.SlackRetries),
+			settings.ExternalURL,
+		))
+		dockleCollector.AddPublisher(notificationPublisher)
+	} else {
+		// Routing is set up even if disabled, so that reloading config can enable it.
+		notifier = collector.NewReloadableNotifier(nil)
+		if settings.RoutingConfig != nil {
+			router, err := newRouter(settings, settings.RoutingConfig)
+			if err != nil {
+				return nil, xerrors.Errorf("could not set up notification router: %w", err)
+			}
+			notifier = collector.NewReloadableNotifier(router)
+		}
+		// Routes filter levels by themselves.
+		notificationPublisher := collector.NewNotificationPublisher("")
+		notificationPublisher.AddNotifier(notifier)
+		dockleCollector.AddPublisher(notificationPublisher)
+	}
+	if settings.WebhookURL != "" {
+		webhookClient, err := client.NewWebhookClient(
+			settings.WebhookURL,
+			settings.WebhookSecret,
+			settings.WebhookMode,
+			settings.WebhookRetries,
+		)
+		if err != nil {
+			return nil, xerrors.Errorf("could not set up webhook client: %w", err)
+		}
+		var outbox collector.IOutbox
+		if settings.WebhookOutbox != nil {
+			outbox = settings.WebhookOutbox
+		}
+		webhookPublisher := collector.NewWebhookPublisher(webhookClient, outbox, settings.WebhookSource)
+		if settings.HistoryStore != nil {
+			if err := webhookPublisher.LoadBaseline(settings.HistoryStore, time.Now()); err != nil {
+				return nil, xerrors.Errorf("could not set up webhook publisher: %w", err)
+			}
+		}
+		dockleCollector.AddPublisher(webhookPublisher)
+	}
+	if settings.AlertmanagerURL != "" {
+		// Alerts outlive a few missed scans, but resolve by themselves if the exporter stops.
+		dockleCollector.AddPublisher(collector.NewAlertmanagerPublisher(
+			client.NewAlertmanagerClient(settings.AlertmanagerURL),
+			settings.AlertmanagerLevel,
+			3*settings.CollectorLoopInterval,
+			settings.ExternalURL,
+		))
+	}
+	var findingStore collector.IFindingStore
+	if settings.HistoryStore != nil {
+		dockleCollector.AddPublisher(collector.NewHistoryPublisher(settings.HistoryStore))
+		findingStore = settings.HistoryStore
+	}
+	lifecycleCollector := collector.NewLifecycleCollector(findingStore)
+	registry.MustRegister(lifecycleCollector)
+	dockleCollector.AddPublisher(lifecycleCollector)
 	prometheusExporter, err := ocprom.NewExporter(ocprom.Options{Registry: registry})
 	if err != nil {
 		return nil, xerrors.Errorf("could not set up prometheus exporter: %w", err)
@@ -137,17 +354,177 @@ func NewMonitor(settings MonitorSettings) (*Monitor, error) {
 		},
 	}
 	server.SetKeepAlivesEnabled(settings.KeepAlived)
+
+	leader := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "dockle",
+		Name:      "leader",
+		Help:      "Whether this replica scans images as the leader (1) or stands by (0)",
+	})
+	registry.MustRegister(leader)
+	ctx, cancel := context.WithCancel(context.Background())
+	var elected <-chan struct{}
+	if settings.LeaderElect {
+		elected, err = runLeaderElection(ctx, settings, dockleCollector, leader)
+		if err != nil {
+			cancel()
+			_ = listener.Close()
+			return nil, xerrors.Errorf("failed to run leader election: %w", err)
+		}
+	} else {
+		leader.Set(1)
+		if err := dockleCollector.Scan(ctx); err != nil {
+			cancel()
+			_ = listener.Close()
+			return nil, xerrors.Errorf("failed to scan of dockle collector: %w", err)
+		}
+		dockleCollector.StartLoop(ctx, settings.CollectorLoopInterval)
+	}
+
 	return &Monitor{
 		maxConnections: settings.MaxConnections,
 		listener:       listener,
 		server:         server,
+		collector:      dockleCollector,
+		dockleClient:   dockleClient,
+		notifier:       notifier,
+		settings:       settings,
+		eventRecorder:  eventRecorder,
+		cancel:         cancel,
+		elected:        elected,
 	}, nil
 }
 
+func newRouter(settings MonitorSettings, config *collector.RoutingConfig) (*collector.Router, error) {
+	sinks := make(map[string]collector.INotifier, len(config.Sinks))
+	for _, sink := range config.Sinks {
+		if _, ok := sinks[sink.Name]; ok {
+			return nil, xerrors.Errorf("duplicate sink: %s", sink.Name)
+		}
+		switch {
+		case sink.Slack != nil:
+			rateLimit := sink.Slack.RateLimit
+			if rateLimit <= 0 {
+				rateLimit = settings.SlackRateLimit
+			}
+			sinks[sink.Name] = collector.NewSlackNotifier(
+				client.NewSlackClient(sink.Slack.WebhookURL, rateLimit, sink.Slack.Retries),
+				settings.ExternalURL,
+			)
+		case sink.Webhook != nil:
+			var secret string
+			if sink.Webhook.SecretFile != "" {
+				content, err := ioutil.ReadFile(sink.Webhook.SecretFile)
+				if err != nil {
+					return nil, xerrors.Errorf("could not read secret of sink %s: %w", sink.Name, err)
+				}
+				secret = strings.TrimSpace(string(content))
+			}
+			mode := sink.Webhook.Mode
+			if mode == "" {
+				mode = client.WebhookModeStructured
+			}
+			source := sink.Webhook.Source
+			if source == "" {
+				source = settings.WebhookSource
+			}
+			webhookClient, err := client.NewWebhookClient(sink.Webhook.URL, secret, mode, sink.Webhook.Retries)
+			if err != nil {
+				return nil, xerrors.Errorf("could not set up sink %s: %w", sink.Name, err)
+			}
+			sinks[sink.Name] = collector.NewWebhookPublisher(webhookClient, nil, source)
+		default:
+			return nil, xerrors.Errorf("sink %s has no destination", sink.Name)
+		}
+	}
+	var store collector.INotificationStore
+	if settings.HistoryStore != nil {
+		store = settings.HistoryStore
+	}
+	return collector.NewRouter(config, sinks, store)
+}
+
+// Reload applies settings which can change without restart, and takes effect from the next scan.
+func (m *Monitor) Reload(
+	ignoreCodes []string,
+	ignoreImages []string,
+	registries []client.Registry,
+	routingConfig *collector.RoutingConfig,
+	exceptionConfigs []collector.ExceptionConfig,
+	severityConfigs []collector.SeverityConfig,
+	riskConfig *collector.RiskConfig,
+) error {
+	ignore, err := collector.NewIgnore(ignoreCodes, ignoreImages)
+	if err != nil {
+		return xerrors.Errorf("failed to create ignore: %w", err)
+	}
+	exceptions, err := collector.NewExceptions(exceptionConfigs)
+	if err != nil {
+		return xerrors.Errorf("failed to create exceptions: %w", err)
+	}
+	severities, err := collector.NewSeverities(severityConfigs)
+	if err != nil {
+		return xerrors.Errorf("failed to create severities: %w", err)
+	}
+	risk, err := collector.NewRisk(riskConfig)
+	if err != nil {
+		return xerrors.Errorf("failed to create risk: %w", err)
+	}
+	// Without the section, routing falls back to --notification-config, and is disabled without both.
+	if routingConfig == nil && m.settings.NotificationConfig != "" {
+		routingConfig, err = collector.LoadRoutingConfig(m.settings.NotificationConfig)
+		if err != nil {
+			return xerrors.Errorf("could not load notification config: %w", err)
+		}
+	}
+	var router *collector.Router
+	if routingConfig != nil {
+		if m.notifier == nil {
+			return xerrors.New("notification routing cannot replace --slack-webhook-url without restart")
+		}
+		router, err = newRouter(m.settings, routingConfig)
+		if err != nil {
+			return xerrors.Errorf("could not set up notification router: %w", err)
+		}
+	}
+
+	m.collector.SetIgnore(ignore)
+	m.collector.SetExceptions(exceptions)
+	m.collector.SetSeverities(severities)
+	m.collector.SetRisk(risk)
+	m.dockleClient.SetRegistries(registries)
+	if m.notifier != nil {
+		m.notifier.Replace(func(current collector.INotifier) collector.INotifier {
+			if router == nil {
+				return nil
+			}
+			if previous, ok := current.(*collector.Router); ok {
+				router.Inherit(previous)
+			}
+			return router
+		})
+	}
+	return nil
+}
+
+func (m *Monitor) Collector() *collector.DockleCollector {
+	return m.collector
+}
+
 func (m *Monitor) Start() error {
 	return m.server.Serve(netutil.LimitListener(m.listener, int(m.maxConnections)))
 }
 
+// Stop releases the lease if leading, waiting for the scan in flight until ctx is done, and shuts down the server.
 func (m *Monitor) Stop(ctx context.Context) error {
+	m.cancel()
+	if m.elected != nil {
+		select {
+		case <-m.elected:
+		case <-ctx.Done():
+		}
+	}
+	if m.eventRecorder != nil {
+		m.eventRecorder.Shutdown()
+	}
 	return m.server.Shutdown(ctx)
 }
@@ -0,0 +1,106 @@
+package processor_test
+
+import (
+	"context"
+	"fmt"
+	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/processor"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func fakeMonitorSettings() processor.MonitorSettings {
+	return processor.MonitorSettings{
+		Address:                     "127.0.0.1:0",
+		MaxConnections:              1,
+		DockleConcurrency:           1,
+		CollectorLoopInterval:       time.Hour,
+		LeaderElectionNamespace:     "fake",
+		LeaderElectionName:          "fake",
+		LeaderElectionIdentity:      "fake",
+		LeaderElectionLeaseDuration: 15 * time.Second,
+		LeaderElectionRenewDeadline: 10 * time.Second,
+		LeaderElectionRetryPeriod:   2 * time.Second,
+		KubernetesClient:            fake.NewSimpleClientset(),
+		Logger:                      &loggerMock{},
+	}
+}
+
+func TestNewMonitor(t *testing.T) {
+	tests := []struct {
+		name            string
+		settings        func(*processor.MonitorSettings)
+		wantErrorString string
+	}{
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func(settings *processor.MonitorSettings) {
+				settings.RemoteClusters = []client.Cluster{{Name: settings.ClusterName}}
+			},
+			"duplicate cluster: ",
+		},
+		{
+			func() string {
+				_, _, line, _ := runtime.Caller(1)
+				return fmt.Sprintf("L%d", line)
+			}(),
+			func(settings *processor.MonitorSettings) {
+				settings.Shard = true
+				settings.LeaderElect = true
+			},
+			"sharding cannot be combined with leader election",
+		},
+	}
+	for _, tt := range tests {
+		name := tt.name
+		update := tt.settings
+		wantErrorString := tt.wantErrorString
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			settings := fakeMonitorSettings()
+			update(&settings)
+			gotErrorString := ""
+			if _, err := processor.NewMonitor(settings); err != nil {
+				gotErrorString = err.Error()
+			}
+			if diff := cmp.Diff(wantErrorString, gotErrorString); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMonitorStopLeading(t *testing.T) {
+	settings := fakeMonitorSettings()
+	settings.LeaderElect = true
+	monitor, err := processor.NewMonitor(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for monitor.Collector().Snapshot() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("the leader did not scan")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := monitor.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// The replica which released the lease exports no results of its last term.
+	if monitor.Collector().Snapshot() != nil {
+		t.Errorf("snapshot is not reset")
+	}
+}
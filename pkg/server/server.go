@@ -2,17 +2,20 @@ package server
 
 import (
 	"context"
+	"io/ioutil"
 	"kube-dockle-exporter/pkg/client"
+	"kube-dockle-exporter/pkg/server/collector"
 	"kube-dockle-exporter/pkg/server/processor"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"golang.org/x/xerrors"
 
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 )
 
 func Run(a *Args) error {
@@ -20,7 +23,25 @@ func Run(a *Args) error {
 	logger := client.NewStandardLogger(a.Verbose)
 	i.SetLogger(logger)
 
-	kubeConfig, err := rest.InClusterConfig()
+	if err := a.Validate(); err != nil {
+		return xerrors.Errorf("invalid arguments: %w", err)
+	}
+
+	var config *Config
+	var loadedConfig []byte
+	if a.Config != "" {
+		var err error
+		loadedConfig, err = ioutil.ReadFile(a.Config)
+		if err != nil {
+			return xerrors.Errorf("failed to read config: %w", err)
+		}
+		config, err = ParseConfig(loadedConfig)
+		if err != nil {
+			return xerrors.Errorf("failed to load config: %w", err)
+		}
+	}
+
+	kubeConfig, err := client.NewRESTConfig(a.Kubeconfig, a.Context, float32(a.KubeAPIQPS), int(a.KubeAPIBurst))
 	if err != nil {
 		return xerrors.Errorf("failed to create kubernetes config: %w", err)
 	}
@@ -29,6 +50,166 @@ func Run(a *Args) error {
 		return xerrors.Errorf("failed to create kubernetes client: %w", err)
 	}
 	i.SetKubernetesClient(clientset)
+	dynamicClient, err := dynamic.NewForConfig(kubeConfig)
+	if err != nil {
+		return xerrors.Errorf("failed to create kubernetes dynamic client: %w", err)
+	}
+	i.SetDynamicClient(dynamicClient)
+
+	var remoteClusters []client.Cluster
+	for _, kubeContext := range a.ClusterContexts {
+		cluster, err := client.NewClusterFromContext(a.Kubeconfig, kubeContext, float32(a.KubeAPIQPS), int(a.KubeAPIBurst))
+		if err != nil {
+			return xerrors.Errorf("failed to create client of cluster: %w", err)
+		}
+		remoteClusters = append(remoteClusters, *cluster)
+	}
+	if a.ClusterSecretSelector != "" {
+		clusters, err := client.NewClustersFromSecrets(
+			clientset,
+			a.ClusterSecretNamespace,
+			a.ClusterSecretSelector,
+			float32(a.KubeAPIQPS),
+			int(a.KubeAPIBurst),
+		)
+		if err != nil {
+			return xerrors.Errorf("failed to create clients of clusters: %w", err)
+		}
+		remoteClusters = append(remoteClusters, clusters...)
+	}
+
+	var historyStore *client.HistoryStore
+	if a.HistoryPath != "" {
+		historyStore, err = client.NewHistoryStore(a.HistoryPath, time.Duration(a.HistoryRetention)*time.Second)
+		if err != nil {
+			return xerrors.Errorf("failed to open history store: %w", err)
+		}
+		defer func() {
+			if err := historyStore.Close(); err != nil {
+				i.logger.Errorf("Failed to close history store: %s\n", err.Error())
+			}
+		}()
+	}
+
+	var webhookSecret string
+	if a.WebhookSecretFile != "" {
+		secret, err := ioutil.ReadFile(a.WebhookSecretFile)
+		if err != nil {
+			return xerrors.Errorf("failed to read webhook secret: %w", err)
+		}
+		webhookSecret = strings.TrimSpace(string(secret))
+	}
+	var webhookOutbox *client.Outbox
+	if a.WebhookOutboxPath != "" {
+		webhookOutbox, err = client.NewOutbox(a.WebhookOutboxPath)
+		if err != nil {
+			return xerrors.Errorf("failed to open webhook outbox: %w", err)
+		}
+		defer func() {
+			if err := webhookOutbox.Close(); err != nil {
+				i.logger.Errorf("Failed to close webhook outbox: %s\n", err.Error())
+			}
+		}()
+	}
+
+	ignoreCodes := a.IgnoreCodes
+	ignoreImages := a.IgnoreImages
+	var registries []client.Registry
+	var routingConfig *collector.RoutingConfig
+	var exceptions []collector.ExceptionConfig
+	var severities []collector.SeverityConfig
+	var risk *collector.RiskConfig
+	if config != nil {
+		ignoreCodes = append(append([]string{}, a.IgnoreCodes...), config.Ignore.Codes...)
+		ignoreImages = append(append([]string{}, a.IgnoreImages...), config.Ignore.Images...)
+		registries, err = config.ResolveRegistries()
+		if err != nil {
+			return xerrors.Errorf("failed to load config: %w", err)
+		}
+		routingConfig = config.Notification
+		exceptions = config.Exceptions
+		severities = config.Severities
+		risk = config.Risk
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return xerrors.Errorf("failed to get hostname: %w", err)
+	}
+
+	var shardIndex int
+	if a.Shard {
+		shardIndex, err = collector.ParseOrdinal(hostname)
+		if err != nil {
+			return xerrors.Errorf("failed to get shard index: %w", err)
+		}
+	}
+
+	monitor, err := processor.NewMonitor(processor.MonitorSettings{
+		Address:                     a.MonitorAddress,
+		MaxConnections:              a.MonitorMaxConnections,
+		JaegerEndpoint:              a.MonitoringJaegerEndpoint,
+		EnableProfiling:             a.EnableProfiling,
+		EnableTracing:               a.EnableTracing,
+		TracingSampleRate:           a.TracingSampleRate,
+		ReUsePort:                   a.ReUsePort,
+		KeepAlived:                  a.KeepAlived,
+		TCPKeepAliveInterval:        time.Duration(a.TCPKeepAliveInterval) * time.Second,
+		DockleConcurrency:           a.DockleConcurrency,
+		IgnoreCodes:                 ignoreCodes,
+		IgnoreImages:                ignoreImages,
+		Registries:                  registries,
+		RoutingConfig:               routingConfig,
+		Exceptions:                  exceptions,
+		Severities:                  severities,
+		Risk:                        risk,
+		PolicyPaths:                 a.PolicyPaths,
+		DisableCorrelationRules:     a.DisableCorrelationRules,
+		NamespaceOwnerLabels:        a.NamespaceOwnerLabels,
+		NamespaceOwnerAnnotations:   a.NamespaceOwnerAnnotations,
+		CollectorLoopInterval:       time.Duration(a.CollectorLoopInterval) * time.Second,
+		ClusterName:                 a.ClusterName,
+		RemoteClusters:              remoteClusters,
+		LeaderElect:                 a.LeaderElect,
+		LeaderElectionNamespace:     a.LeaderElectionNamespace,
+		LeaderElectionName:          a.LeaderElectionName,
+		LeaderElectionIdentity:      hostname,
+		LeaderElectionLeaseDuration: time.Duration(a.LeaderElectionLeaseDuration) * time.Second,
+		LeaderElectionRenewDeadline: time.Duration(a.LeaderElectionRenewDeadline) * time.Second,
+		LeaderElectionRetryPeriod:   time.Duration(a.LeaderElectionRetryPeriod) * time.Second,
+		Shard:                       a.Shard,
+		ShardNamespace:              a.ShardNamespace,
+		ShardStatefulSetName:        a.ShardStatefulSetName,
+		ShardIndex:                  shardIndex,
+		EnablePolicyReport:          a.EnablePolicyReport,
+		PolicyReportScope:           a.PolicyReportScope,
+		EnableEvents:                a.EnableEvents,
+		EventLevel:                  a.EventLevel,
+		EventQPS:                    float32(a.EventQPS),
+		EventBurst:                  int(a.EventBurst),
+		HistoryStore:                historyStore,
+		NotificationLevel:           a.NotificationLevel,
+		NotificationConfig:          a.NotificationConfig,
+		SlackWebhookURL:             a.SlackWebhookURL,
+		SlackRateLimit:              a.SlackRateLimit,
+		SlackRetries:                int(a.SlackRetries),
+		ExternalURL:                 a.ExternalURL,
+		WebhookURL:                  a.WebhookURL,
+		WebhookMode:                 a.WebhookMode,
+		WebhookSecret:               webhookSecret,
+		WebhookSource:               a.WebhookSource,
+		WebhookRetries:              int(a.WebhookRetries),
+		WebhookOutbox:               webhookOutbox,
+		AlertmanagerURL:             a.AlertmanagerURL,
+		AlertmanagerLevel:           a.AlertmanagerLevel,
+		KubernetesClient:            i.KubernetesClient(),
+		DynamicClient:               i.DynamicClient(),
+		Logger:                      i.Logger(),
+	})
+	if err != nil {
+		return xerrors.Errorf("failed to create monitor: %w", err)
+	}
+	i.AddProcessor(monitor)
 
 	api, err := processor.NewAPI(processor.APISettings{
 		Address:              a.APIAddress,
@@ -36,6 +217,8 @@ func Run(a *Args) error {
 		ReUsePort:            a.ReUsePort,
 		KeepAlived:           a.KeepAlived,
 		TCPKeepAliveInterval: time.Duration(a.TCPKeepAliveInterval) * time.Second,
+		Collector:            monitor.Collector(),
+		HistoryStore:         historyStore,
 		Logger:               i.Logger(),
 	})
 	if err != nil {
@@ -43,28 +226,35 @@ func Run(a *Args) error {
 	}
 	i.AddProcessor(api)
 
-	monitor, err := processor.NewMonitor(processor.MonitorSettings{
-		Address:               a.MonitorAddress,
-		MaxConnections:        a.MonitorMaxConnections,
-		JaegerEndpoint:        a.MonitoringJaegerEndpoint,
-		EnableProfiling:       a.EnableProfiling,
-		EnableTracing:         a.EnableTracing,
-		TracingSampleRate:     a.TracingSampleRate,
-		ReUsePort:             a.ReUsePort,
-		KeepAlived:            a.KeepAlived,
-		TCPKeepAliveInterval:  time.Duration(a.TCPKeepAliveInterval) * time.Second,
-		DockleConcurrency:     a.DockleConcurrency,
-		CollectorLoopInterval: time.Duration(a.CollectorLoopInterval) * time.Second,
-		KubernetesClient:      i.KubernetesClient(),
-		Logger:                i.Logger(),
-	})
-	if err != nil {
-		return xerrors.Errorf("failed to create monitor: %w", err)
-	}
-	i.AddProcessor(monitor)
-
 	i.Start()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if a.Config != "" {
+		go watchConfig(
+			ctx,
+			a.Config,
+			loadedConfig,
+			time.Duration(a.ConfigReloadInterval)*time.Second,
+			i.Logger(),
+			func(config *Config) error {
+				registries, err := config.ResolveRegistries()
+				if err != nil {
+					return err
+				}
+				return monitor.Reload(
+					append(append([]string{}, a.IgnoreCodes...), config.Ignore.Codes...),
+					append(append([]string{}, a.IgnoreImages...), config.Ignore.Images...),
+					registries,
+					config.Notification,
+					config.Exceptions,
+					config.Severities,
+					config.Risk,
+				)
+			},
+		)
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM)
 	<-quit